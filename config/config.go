@@ -0,0 +1,255 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// devJWTSecret signs tokens when no secret is configured. It is public, so it must never be used
+// outside development.
+const devJWTSecret = "uber-eats-dev-secret"
+
+// publicJWTSecrets are the secrets published in this repository, the development secret and the
+// placeholder in docker-compose.yaml. Anyone can sign tokens with them.
+var publicJWTSecrets = map[string]bool{
+	devJWTSecret:              true,
+	"change-me-in-production": true,
+}
+
+// Config is the configuration of the server. It starts from Default, is overlaid with an optional
+// YAML file and then with environment variables, so one binary can run in every environment.
+type Config struct {
+	Server ServerConfig `yaml:"server"`
+	Mongo  MongoConfig  `yaml:"mongo"`
+	Auth   AuthConfig   `yaml:"auth"`
+}
+
+type ServerConfig struct {
+	// Addr is the address the HTTP server listens on, such as ":8080"
+	Addr string `yaml:"addr"`
+	// ShutdownTimeout bounds how long in-flight requests get to finish on shutdown. It should be
+	// shorter than the grace period of whatever stops the server.
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+	// RequestTimeout bounds how long a request may spend in the handler, database calls included
+	RequestTimeout time.Duration `yaml:"requestTimeout"`
+	// RouteTimeouts overrides RequestTimeout for single routes, keyed by method and route pattern
+	// such as "POST /api/cart/checkout"
+	RouteTimeouts map[string]time.Duration `yaml:"routeTimeouts"`
+}
+
+type MongoConfig struct {
+	URI             string        `yaml:"uri"`
+	Database        string        `yaml:"database"`
+	MaxPoolSize     uint64        `yaml:"maxPoolSize"`
+	MinPoolSize     uint64        `yaml:"minPoolSize"`
+	MaxConnIdleTime time.Duration `yaml:"maxConnIdleTime"`
+	ConnectTimeout  time.Duration `yaml:"connectTimeout"`
+}
+
+type AuthConfig struct {
+	// JWTSecret signs access tokens. Without one a development secret is used.
+	JWTSecret string `yaml:"jwtSecret"`
+	// CursorSecret signs pagination cursors. Without one the JWT secret is used.
+	CursorSecret string `yaml:"cursorSecret"`
+}
+
+// UsesPublicSecret reports whether tokens are signed with a secret published in this repository
+func (c AuthConfig) UsesPublicSecret() bool {
+	return publicJWTSecrets[c.JWTSecret]
+}
+
+// Default returns the configuration used when nothing overrides it
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{Addr: ":8080", ShutdownTimeout: 10 * time.Second, RequestTimeout: 10 * time.Second},
+		Mongo: MongoConfig{
+			URI:             "mongodb://localhost:27017",
+			Database:        "testing",
+			MaxPoolSize:     100,
+			MinPoolSize:     5,
+			MaxConnIdleTime: 5 * time.Second,
+			ConnectTimeout:  10 * time.Second,
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the YAML file at path if path is not empty, and
+// the environment, in that order, and validates it
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.loadEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	if cfg.Auth.JWTSecret == "" {
+		cfg.Auth.JWTSecret = devJWTSecret
+		log.Println("JWT_SECRET is not set, using the development secret")
+	}
+	if cfg.Auth.CursorSecret == "" {
+		cfg.Auth.CursorSecret = cfg.Auth.JWTSecret
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadFile overlays the settings of a YAML file. Unknown keys are rejected so typos don't go
+// unnoticed.
+func (c *Config) loadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config: %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadEnv overlays the settings found in the environment
+func (c *Config) loadEnv(lookup func(string) (string, bool)) error {
+	var errs []error
+	setString := func(name string, target *string) {
+		if value, ok := lookup(name); ok {
+			*target = value
+		}
+	}
+	setUint := func(name string, target *uint64) {
+		if value, ok := lookup(name); ok {
+			n, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be a non-negative integer, got %q", name, value))
+				return
+			}
+			*target = n
+		}
+	}
+	setDuration := func(name string, target *time.Duration) {
+		if value, ok := lookup(name); ok {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be a duration such as 10s, got %q", name, value))
+				return
+			}
+			*target = d
+		}
+	}
+
+	setString("SERVER_ADDR", &c.Server.Addr)
+	setDuration("SERVER_SHUTDOWN_TIMEOUT", &c.Server.ShutdownTimeout)
+	setDuration("SERVER_REQUEST_TIMEOUT", &c.Server.RequestTimeout)
+	if value, ok := lookup("SERVER_ROUTE_TIMEOUTS"); ok {
+		routeTimeouts, err := parseRouteTimeouts(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("SERVER_ROUTE_TIMEOUTS: %w", err))
+		} else {
+			c.Server.RouteTimeouts = routeTimeouts
+		}
+	}
+	setString("MONGO_URI", &c.Mongo.URI)
+	setString("MONGO_DATABASE", &c.Mongo.Database)
+	setUint("MONGO_MAX_POOL_SIZE", &c.Mongo.MaxPoolSize)
+	setUint("MONGO_MIN_POOL_SIZE", &c.Mongo.MinPoolSize)
+	setDuration("MONGO_MAX_CONN_IDLE_TIME", &c.Mongo.MaxConnIdleTime)
+	setDuration("MONGO_CONNECT_TIMEOUT", &c.Mongo.ConnectTimeout)
+	setString("JWT_SECRET", &c.Auth.JWTSecret)
+	setString("CURSOR_SECRET", &c.Auth.CursorSecret)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// parseRouteTimeouts reads comma-separated route timeouts such as
+// "POST /api/cart/checkout=30s,GET /api/search=3s"
+func parseRouteTimeouts(value string) (map[string]time.Duration, error) {
+	routeTimeouts := make(map[string]time.Duration)
+	for _, entry := range strings.Split(value, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		route, timeout, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("%q must be a route and a duration, such as GET /api/search=3s", entry)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(timeout))
+		if err != nil {
+			return nil, fmt.Errorf("%q must end in a duration such as 10s", entry)
+		}
+		routeTimeouts[strings.TrimSpace(route)] = d
+	}
+	return routeTimeouts, nil
+}
+
+// Validate reports every invalid setting at once
+func (c *Config) Validate() error {
+	var errs []error
+
+	if _, _, err := net.SplitHostPort(c.Server.Addr); err != nil {
+		errs = append(errs, fmt.Errorf("server.addr must be host:port, got %q", c.Server.Addr))
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("server.shutdownTimeout must be positive"))
+	}
+	if c.Server.RequestTimeout <= 0 {
+		errs = append(errs, errors.New("server.requestTimeout must be positive"))
+	}
+	for _, route := range slices.Sorted(maps.Keys(c.Server.RouteTimeouts)) {
+		timeout := c.Server.RouteTimeouts[route]
+		method, path, found := strings.Cut(route, " ")
+		if !found || method == "" || !strings.HasPrefix(path, "/") {
+			errs = append(errs, fmt.Errorf("server.routeTimeouts key must be a method and route, such as \"GET /api/search\", got %q", route))
+		}
+		if timeout <= 0 {
+			errs = append(errs, fmt.Errorf("server.routeTimeouts[%q] must be positive", route))
+		}
+	}
+
+	if !strings.HasPrefix(c.Mongo.URI, "mongodb://") && !strings.HasPrefix(c.Mongo.URI, "mongodb+srv://") {
+		errs = append(errs, errors.New("mongo.uri must start with mongodb:// or mongodb+srv://"))
+	}
+	if c.Mongo.Database == "" || strings.ContainsAny(c.Mongo.Database, `/\. "$`) {
+		errs = append(errs, fmt.Errorf("mongo.database must be a valid database name, got %q", c.Mongo.Database))
+	}
+	if c.Mongo.MaxPoolSize == 0 {
+		errs = append(errs, errors.New("mongo.maxPoolSize must be at least 1"))
+	}
+	if c.Mongo.MinPoolSize > c.Mongo.MaxPoolSize {
+		errs = append(errs, errors.New("mongo.minPoolSize cannot be more than mongo.maxPoolSize"))
+	}
+	if c.Mongo.MaxConnIdleTime <= 0 {
+		errs = append(errs, errors.New("mongo.maxConnIdleTime must be positive"))
+	}
+	if c.Mongo.ConnectTimeout <= 0 {
+		errs = append(errs, errors.New("mongo.connectTimeout must be positive"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+	return nil
+}
@@ -2,22 +2,28 @@ package config
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
-	"time"
+	"strings"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func ConnectDB() (*mongo.Client, context.Context, context.CancelFunc) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// ConnectDB connects to MongoDB and creates the indexes. The connect timeout only applies here;
+// disconnect with a context of its own.
+func ConnectDB(cfg MongoConfig) *mongo.Client {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ConnectTimeout)
+	defer cancel()
 
 	clientOptions := options.Client().
-		ApplyURI("mongodb://mongodb:27017").
-		SetMaxPoolSize(100).
-		SetMinPoolSize(5).
-		SetMaxConnIdleTime(5 * time.Second)
+		ApplyURI(cfg.URI).
+		SetMaxPoolSize(cfg.MaxPoolSize).
+		SetMinPoolSize(cfg.MinPoolSize).
+		SetMaxConnIdleTime(cfg.MaxConnIdleTime)
 
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
@@ -25,46 +31,57 @@ func ConnectDB() (*mongo.Client, context.Context, context.CancelFunc) {
 	}
 
 	// Create indexes
-	createIndexes(client)
+	createIndexes(client.Database(cfg.Database))
 
-	return client, ctx, cancel
+	return client
 }
 
-func GetCollection(client *mongo.Client, collectionName string) *mongo.Collection {
-	collection := client.Database("testing").Collection(collectionName)
-	return collection
-}
-
-func createIndexes(client *mongo.Client) {
-	reviewCollection := GetCollection(client, "reviews")
+func createIndexes(db *mongo.Database) {
+	reviewCollection := db.Collection("reviews")
 	reviewIndexes := []mongo.IndexModel{
 		{
 			Keys: bson.D{{Key: "restaurantId", Value: 1}},
 		},
 		{
-			Keys: bson.D{{Key: "orderId", Value: 1}},
+			// A restaurant's reviews, newest first
+			Keys: bson.D{{Key: "restaurantId", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "_id", Value: 1}},
 		},
 	}
 	_, err := reviewCollection.Indexes().CreateMany(context.Background(), reviewIndexes)
 	if err != nil {
 		log.Fatal(err)
 	}
+	createUniqueReviewOrderIndex(reviewCollection)
 
-	restaurantCollection := GetCollection(client, "restaurants")
+	restaurantCollection := db.Collection("restaurants")
 	restaurantIndexes := []mongo.IndexModel{
 		{
 			Keys: bson.D{{Key: "name", Value: 1}},
 		},
+		{
+			Keys: bson.D{{Key: "rating", Value: -1}, {Key: "_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: 1}},
+		},
+		{
+			// Full-text search; names weigh more than descriptions
+			Keys:    bson.D{{Key: "name", Value: "text"}, {Key: "description", Value: "text"}},
+			Options: options.Index().SetName("restaurant_text").SetWeights(bson.D{{Key: "name", Value: 10}, {Key: "description", Value: 2}}),
+		},
 		{
 			Keys: bson.D{{Key: "location", Value: "2dsphere"}},
 		},
+		{
+			Keys: bson.D{{Key: "timezone", Value: 1}, {Key: "openIntervals.start", Value: 1}, {Key: "openIntervals.end", Value: 1}},
+		},
 	}
 	_, err = restaurantCollection.Indexes().CreateMany(context.Background(), restaurantIndexes)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	itemCollection := GetCollection(client, "items")
+	itemCollection := db.Collection("items")
 	itemIndexes := []mongo.IndexModel{
 		{
 			Keys: bson.D{{Key: "restaurantId", Value: 1}},
@@ -72,9 +89,200 @@ func createIndexes(client *mongo.Client) {
 		{
 			Keys: bson.D{{Key: "name", Value: 1}},
 		},
+		{
+			Keys: bson.D{{Key: "price", Value: 1}, {Key: "_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: 1}},
+		},
+		{
+			// Full-text search; names weigh more than descriptions
+			Keys:    bson.D{{Key: "name", Value: "text"}, {Key: "description", Value: "text"}},
+			Options: options.Index().SetName("item_text").SetWeights(bson.D{{Key: "name", Value: 10}, {Key: "description", Value: 2}}),
+		},
+		{
+			Keys: bson.D{{Key: "restaurantId", Value: 1}, {Key: "categoryId", Value: 1}, {Key: "position", Value: 1}},
+		},
+		{
+			// Only items with a daily stock reset are indexed, for the reset job
+			Keys:    bson.D{{Key: "inventory.nextResetAt", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
 	}
 	_, err = itemCollection.Indexes().CreateMany(context.Background(), itemIndexes)
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	menuCategoryCollection := db.Collection("menu_categories")
+	menuCategoryIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "restaurantId", Value: 1}, {Key: "position", Value: 1}},
+		},
+	}
+	_, err = menuCategoryCollection.Indexes().CreateMany(context.Background(), menuCategoryIndexes)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	menuCollection := db.Collection("menus")
+	menuIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "restaurantId", Value: 1}, {Key: "name", Value: 1}},
+		},
+	}
+	_, err = menuCollection.Indexes().CreateMany(context.Background(), menuIndexes)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	deliveryZoneCollection := db.Collection("delivery_zones")
+	deliveryZoneIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "restaurantId", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "area", Value: "2dsphere"}},
+		},
+	}
+	_, err = deliveryZoneCollection.Indexes().CreateMany(context.Background(), deliveryZoneIndexes)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	orderCollection := db.Collection("orders")
+	orderIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "restaurantId", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}},
+		},
+	}
+	_, err = orderCollection.Indexes().CreateMany(context.Background(), orderIndexes)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	userCollection := db.Collection("users")
+	userIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	_, err = userCollection.Indexes().CreateMany(context.Background(), userIndexes)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	auditCollection := db.Collection("audit_logs")
+	auditIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+	}
+	_, err = auditCollection.Indexes().CreateMany(context.Background(), auditIndexes)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cartCollection := db.Collection("carts")
+	cartIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "customerId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	_, err = cartCollection.Indexes().CreateMany(context.Background(), cartIndexes)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// uniqueReviewOrderIndex allows one review per order
+var uniqueReviewOrderIndex = mongo.IndexModel{
+	Keys:    bson.D{{Key: "orderId", Value: 1}},
+	Options: options.Index().SetName("orderId_unique").SetUnique(true),
+}
+
+// createUniqueReviewOrderIndex creates the unique orderId index on reviews. It replaces a plain
+// index on the same key, and MongoDB won't build a second index on that key, so the plain one has
+// to be dropped first. Duplicate reviews are looked for before that, and the plain index is put
+// back if the build still fails, so orderId is never left unindexed.
+func createUniqueReviewOrderIndex(collection *mongo.Collection) {
+	ctx := context.Background()
+	_, err := collection.Indexes().CreateOne(ctx, uniqueReviewOrderIndex)
+	if err == nil {
+		return
+	}
+	if !isIndexConflict(err) {
+		log.Fatalf("Error creating the unique orderId index on reviews: %v", err)
+	}
+
+	duplicates, err := duplicateReviewOrders(ctx, collection)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(duplicates) > 0 {
+		log.Fatalf("Can't make review orderIds unique: some orders have several reviews, such as %s. "+
+			"Delete all but one review of each order, run with -recompute-ratings and start again.", strings.Join(duplicates, ", "))
+	}
+
+	if _, err := collection.Indexes().DropOne(ctx, "orderId_1"); err != nil && !isIndexNotFound(err) {
+		log.Fatal(err)
+	}
+	if _, err := collection.Indexes().CreateOne(ctx, uniqueReviewOrderIndex); err != nil {
+		if _, restoreErr := collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "orderId", Value: 1}}}); restoreErr != nil {
+			log.Printf("Error restoring the orderId index on reviews: %v", restoreErr)
+		}
+		log.Fatalf("Error creating the unique orderId index on reviews: %v", err)
+	}
+}
+
+// maxDuplicatesReported caps how many duplicated orders are named when the unique index can't be built
+const maxDuplicatesReported = 5
+
+// duplicateReviewOrders returns the IDs of some orders with more than one review
+func duplicateReviewOrders(ctx context.Context, collection *mongo.Collection) ([]string, error) {
+	cursor, err := collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{"_id": "$orderId", "count": bson.M{"$sum": 1}}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+		{{Key: "$limit", Value: maxDuplicatesReported}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var groups []struct {
+		OrderID interface{} `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	duplicates := make([]string, len(groups))
+	for i, group := range groups {
+		if id, ok := group.OrderID.(primitive.ObjectID); ok {
+			duplicates[i] = id.Hex()
+		} else {
+			duplicates[i] = fmt.Sprint(group.OrderID)
+		}
+	}
+	return duplicates, nil
+}
+
+// isIndexConflict reports whether creating an index failed because one with the same key and other
+// options or another name exists
+func isIndexConflict(err error) bool {
+	var commandErr mongo.CommandError
+	// 85 is IndexOptionsConflict and 86 IndexKeySpecsConflict
+	return errors.As(err, &commandErr) && (commandErr.Code == 85 || commandErr.Code == 86)
+}
+
+// isIndexNotFound reports whether dropping an index failed because it, or its collection, doesn't
+// exist
+func isIndexNotFound(err error) bool {
+	var commandErr mongo.CommandError
+	// 26 is NamespaceNotFound and 27 IndexNotFound
+	return errors.As(err, &commandErr) && (commandErr.Code == 26 || commandErr.Code == 27)
 }
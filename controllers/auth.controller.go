@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/aldiandyaIrsyad/uber-eats/middleware"
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/aldiandyaIrsyad/uber-eats/services"
+	"github.com/gin-gonic/gin"
+)
+
+type AuthController struct {
+	authService *services.AuthService
+}
+
+func NewAuthController(authService *services.AuthService) *AuthController {
+	return &AuthController{
+		authService: authService,
+	}
+}
+
+func (c *AuthController) Signup(ctx *gin.Context) {
+	var body struct {
+		Name     string `json:"name" binding:"required,min=2,max=100"`
+		Email    string `json:"email" binding:"required,email"`
+		Password string `json:"password" binding:"required"`
+		Role     string `json:"role"`
+	}
+	if err := bindJSON(ctx, &body); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	user := models.User{Name: body.Name, Email: body.Email, Role: body.Role}
+	token, err := c.authService.Signup(ctx.Request.Context(), &user, body.Password)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"token": token, "user": user})
+}
+
+func (c *AuthController) Login(ctx *gin.Context) {
+	var body struct {
+		Email    string `json:"email" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+	if err := bindJSON(ctx, &body); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	token, user, err := c.authService.Login(ctx.Request.Context(), body.Email, body.Password)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"token": token, "user": user})
+}
+
+func (c *AuthController) Me(ctx *gin.Context) {
+	caller, _ := middleware.CurrentUser(ctx)
+
+	user, err := c.authService.GetUserByID(ctx.Request.Context(), caller.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
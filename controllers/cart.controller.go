@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/aldiandyaIrsyad/uber-eats/middleware"
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/aldiandyaIrsyad/uber-eats/services"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CartController struct {
+	cartService *services.CartService
+}
+
+func NewCartController(cartService *services.CartService) *CartController {
+	return &CartController{
+		cartService: cartService,
+	}
+}
+
+func (c *CartController) GetCart(ctx *gin.Context) {
+	user, _ := middleware.CurrentUser(ctx)
+
+	cart, err := c.cartService.GetCart(ctx.Request.Context(), user.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, cart)
+}
+
+func (c *CartController) AddItem(ctx *gin.Context) {
+	user, _ := middleware.CurrentUser(ctx)
+
+	var body struct {
+		ItemID    primitive.ObjectID        `json:"itemId" binding:"required"`
+		Quantity  int                       `json:"quantity"`
+		Modifiers []models.SelectedModifier `json:"modifiers"`
+	}
+	if err := bindJSON(ctx, &body); err != nil {
+		ctx.Error(err)
+		return
+	}
+	if body.Quantity == 0 {
+		body.Quantity = 1
+	}
+
+	cart, err := c.cartService.AddItem(ctx.Request.Context(), user.ID, body.ItemID, body.Quantity, body.Modifiers)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, cart)
+}
+
+func (c *CartController) UpdateItemQuantity(ctx *gin.Context) {
+	user, _ := middleware.CurrentUser(ctx)
+
+	lineID, err := parseID(ctx, "lineID")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var body struct {
+		Quantity *int `json:"quantity" binding:"required"`
+	}
+	if err := bindJSON(ctx, &body); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	cart, err := c.cartService.UpdateItemQuantity(ctx.Request.Context(), user.ID, lineID, *body.Quantity)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, cart)
+}
+
+func (c *CartController) RemoveItem(ctx *gin.Context) {
+	user, _ := middleware.CurrentUser(ctx)
+
+	lineID, err := parseID(ctx, "lineID")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	cart, err := c.cartService.RemoveItem(ctx.Request.Context(), user.ID, lineID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, cart)
+}
+
+func (c *CartController) ClearCart(ctx *gin.Context) {
+	user, _ := middleware.CurrentUser(ctx)
+
+	if err := c.cartService.ClearCart(ctx.Request.Context(), user.ID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Cart cleared successfully"})
+}
+
+func (c *CartController) Checkout(ctx *gin.Context) {
+	user, _ := middleware.CurrentUser(ctx)
+
+	order, err := c.cartService.Checkout(ctx.Request.Context(), user.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, order)
+}
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/aldiandyaIrsyad/uber-eats/services"
+	"github.com/gin-gonic/gin"
+)
+
+type DeliveryZoneController struct {
+	zoneService *services.DeliveryZoneService
+}
+
+func NewDeliveryZoneController(zoneService *services.DeliveryZoneService) *DeliveryZoneController {
+	return &DeliveryZoneController{
+		zoneService: zoneService,
+	}
+}
+
+func (c *DeliveryZoneController) CreateZone(ctx *gin.Context) {
+	restaurantID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var zone models.DeliveryZone
+	if err := bindJSON(ctx, &zone); err != nil {
+		ctx.Error(err)
+		return
+	}
+	zone.RestaurantID = restaurantID
+
+	if err := c.zoneService.CreateZone(ctx.Request.Context(), &zone); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, zone)
+}
+
+func (c *DeliveryZoneController) GetZones(ctx *gin.Context) {
+	restaurantID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	zones, err := c.zoneService.GetZonesByRestaurantID(ctx.Request.Context(), restaurantID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	respondPage(ctx, models.WholePage(zones))
+}
+
+func (c *DeliveryZoneController) UpdateZone(ctx *gin.Context) {
+	restaurantID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	zoneID, err := parseID(ctx, "zoneID")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var zone models.DeliveryZone
+	if err := bindJSON(ctx, &zone); err != nil {
+		ctx.Error(err)
+		return
+	}
+	zone.ID = zoneID
+	zone.RestaurantID = restaurantID
+
+	if err := c.zoneService.UpdateZone(ctx.Request.Context(), &zone); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Delivery zone updated successfully"})
+}
+
+func (c *DeliveryZoneController) DeleteZone(ctx *gin.Context) {
+	restaurantID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	zoneID, err := parseID(ctx, "zoneID")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err := c.zoneService.DeleteZone(ctx.Request.Context(), restaurantID, zoneID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Delivery zone deleted successfully"})
+}
+
+func (c *DeliveryZoneController) CheckDelivery(ctx *gin.Context) {
+	restaurantID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	lat, lng, err := parseLatLng(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	quote, err := c.zoneService.CheckDelivery(ctx.Request.Context(), restaurantID, lat, lng)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quote)
+}
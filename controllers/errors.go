@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/aldiandyaIrsyad/uber-eats/services"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func init() {
+	// Name fields in validation errors the way clients send them
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+	}
+}
+
+// parseID reads the ObjectID in the path parameter param
+func parseID(ctx *gin.Context, param string) (primitive.ObjectID, error) {
+	id, err := primitive.ObjectIDFromHex(ctx.Param(param))
+	if err != nil {
+		return primitive.NilObjectID, services.InvalidField(param, "invalid_id", param+" must be a 24-character hex ID")
+	}
+	return id, nil
+}
+
+// invalidQuery reports a query parameter that can't be used
+func invalidQuery(param string, err error) error {
+	return services.InvalidField(param, "invalid_query", err.Error())
+}
+
+// bindJSON decodes the JSON body into obj and validates it. Every failed binding rule is reported
+// against its field.
+func bindJSON(ctx *gin.Context, obj interface{}) error {
+	err := ctx.ShouldBindJSON(obj)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &validationErrs):
+		// The namespace of a field starts with the name of the bound type, if it has one
+		root := reflect.Indirect(reflect.ValueOf(obj)).Type().Name()
+		fields := make([]services.FieldError, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			field := fieldErr.Namespace()
+			if root != "" {
+				field = strings.TrimPrefix(field, root+".")
+			}
+			fields = append(fields, services.FieldError{
+				Field:   field,
+				Code:    fieldErr.Tag(),
+				Message: ruleMessage(field, fieldErr),
+			})
+		}
+		return services.Validation("invalid_body", "the request body is invalid", fields...)
+	case errors.Is(err, io.EOF):
+		return services.Validation("invalid_body", "the request body is empty")
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return services.Validation("malformed_json", "the request body is not valid JSON")
+	case errors.As(err, &typeErr):
+		return services.InvalidField(typeErr.Field, "invalid_type", fmt.Sprintf("%s has the wrong type: got %s, want %s", typeErr.Field, typeErr.Value, typeErr.Type.Kind()))
+	default:
+		return services.Validation("invalid_body", err.Error())
+	}
+}
+
+// ruleMessage explains a failed binding rule
+func ruleMessage(field string, fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return field + " is required"
+	case "email":
+		return field + " must be an email address"
+	case "min":
+		return fmt.Sprintf("%s must be at least %s", field, fieldErr.Param())
+	case "max":
+		return fmt.Sprintf("%s must be at most %s", field, fieldErr.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s", field, fieldErr.Param())
+	default:
+		return fmt.Sprintf("%s fails the %s rule", field, fieldErr.Tag())
+	}
+}
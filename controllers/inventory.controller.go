@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/aldiandyaIrsyad/uber-eats/services"
+	"github.com/gin-gonic/gin"
+)
+
+type InventoryController struct {
+	inventoryService *services.InventoryService
+}
+
+func NewInventoryController(inventoryService *services.InventoryService) *InventoryController {
+	return &InventoryController{
+		inventoryService: inventoryService,
+	}
+}
+
+func (c *InventoryController) AdjustStock(ctx *gin.Context) {
+	restaurantID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var body struct {
+		Adjustments []models.StockAdjustment `json:"adjustments" binding:"required,dive"`
+	}
+	if err := bindJSON(ctx, &body); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err := c.inventoryService.AdjustStock(ctx.Request.Context(), restaurantID, body.Adjustments); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Stock updated successfully"})
+}
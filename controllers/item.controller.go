@@ -1,9 +1,7 @@
 package controllers
 
 import (
-	"context"
 	"net/http"
-	"strconv"
 
 	"github.com/aldiandyaIrsyad/uber-eats/models"
 	"github.com/aldiandyaIrsyad/uber-eats/services"
@@ -23,13 +21,13 @@ func NewItemController(itemService *services.ItemService) *ItemController {
 
 func (c *ItemController) CreateItem(ctx *gin.Context) {
 	var item models.Item
-	if err := ctx.ShouldBindJSON(&item); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(ctx, &item); err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	if err := c.itemService.CreateItem(context.Background(), &item); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := c.itemService.CreateItem(ctx.Request.Context(), &item); err != nil {
+		ctx.Error(err)
 		return
 	}
 
@@ -37,16 +35,15 @@ func (c *ItemController) CreateItem(ctx *gin.Context) {
 }
 
 func (c *ItemController) GetItemByID(ctx *gin.Context) {
-	id := ctx.Param("id")
-	itemID, err := primitive.ObjectIDFromHex(id)
+	itemID, err := parseID(ctx, "id")
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(err)
 		return
 	}
 
-	item, err := c.itemService.GetItemByID(context.Background(), itemID)
+	item, err := c.itemService.GetItemByID(ctx.Request.Context(), itemID)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		ctx.Error(err)
 		return
 	}
 
@@ -54,21 +51,20 @@ func (c *ItemController) GetItemByID(ctx *gin.Context) {
 }
 
 func (c *ItemController) UpdateItem(ctx *gin.Context) {
-	id := ctx.Param("id")
-	itemID, err := primitive.ObjectIDFromHex(id)
+	itemID, err := parseID(ctx, "id")
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(err)
 		return
 	}
 
 	var update map[string]interface{}
-	if err := ctx.ShouldBindJSON(&update); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(ctx, &update); err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	if err := c.itemService.UpdateItem(context.Background(), itemID, update); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := c.itemService.UpdateItem(ctx.Request.Context(), itemID, update); err != nil {
+		ctx.Error(err)
 		return
 	}
 
@@ -76,15 +72,14 @@ func (c *ItemController) UpdateItem(ctx *gin.Context) {
 }
 
 func (c *ItemController) DeleteItem(ctx *gin.Context) {
-	id := ctx.Param("id")
-	itemID, err := primitive.ObjectIDFromHex(id)
+	itemID, err := parseID(ctx, "id")
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(err)
 		return
 	}
 
-	if err := c.itemService.DeleteItem(context.Background(), itemID); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := c.itemService.DeleteItem(ctx.Request.Context(), itemID); err != nil {
+		ctx.Error(err)
 		return
 	}
 
@@ -93,54 +88,62 @@ func (c *ItemController) DeleteItem(ctx *gin.Context) {
 
 func (c *ItemController) GetItems(ctx *gin.Context) {
 	// Parse query parameters
-	page := ctx.DefaultQuery("page", "1")
-	pageSize := ctx.DefaultQuery("pageSize", "10")
-	sortField := ctx.DefaultQuery("sortField", "")
-	sortOrder := ctx.DefaultQuery("sortOrder", "asc")
+	sortExpression := ctx.Query("sort")
 	restaurantID := ctx.Query("restaurantID")
 
-	// Convert string parameters to appropriate types
-	pageInt, _ := strconv.ParseInt(page, 10, 64)
-	pageSizeInt, _ := strconv.ParseInt(pageSize, 10, 64)
+	queryOpts := models.QueryOptions{}
 
-	// Build query options
-	queryOpts := models.QueryOptions{
-		Pagination: &models.PaginationOptions{
-			Page:     pageInt,
-			PageSize: pageSizeInt,
-		},
+	// Add sorting; sortField and sortOrder are still read for a single key
+	if sortExpression == "" && ctx.Query("sortField") != "" {
+		sortExpression = ctx.Query("sortField") + ":" + ctx.DefaultQuery("sortOrder", "asc")
 	}
+	sort, err := models.ParseSort(sortExpression, models.ItemSorts)
+	if err != nil {
+		ctx.Error(invalidQuery("sort", err))
+		return
+	}
+	queryOpts.Sort = sort
 
-	// Add sorting if specified
-	if sortField != "" {
-		order := 1
-		if sortOrder == "desc" {
-			order = -1
-		}
-		queryOpts.Sort = &models.SortOptions{
-			Field: sortField,
-			Order: order,
-		}
+	// Add pagination; a cursor has to match the sort
+	pagination, err := parsePagination(ctx, sort)
+	if err != nil {
+		ctx.Error(err)
+		return
 	}
+	queryOpts.Pagination = pagination
 
 	// Add filters
+	filter, err := models.ParseFilter(ctx.Query("filter"), models.ItemFilters)
+	if err != nil {
+		ctx.Error(invalidQuery("filter", err))
+		return
+	}
 	if restaurantID != "" {
 		restaurantObjID, err := primitive.ObjectIDFromHex(restaurantID)
 		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid restaurant ID"})
+			ctx.Error(services.InvalidField("restaurantID", "invalid_id", "restaurantID must be a 24-character hex ID"))
 			return
 		}
-		queryOpts.Filter = map[string]interface{}{
-			"restaurantId": restaurantObjID,
+		if _, ok := filter["restaurantId"]; ok {
+			ctx.Error(services.InvalidField("restaurantID", "invalid_query", "use either restaurantID or a restaurantId filter"))
+			return
 		}
+		filter["restaurantId"] = restaurantObjID
+	}
+	queryOpts.Filter = filter
+
+	at, err := parseAt(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
 	}
 
 	// Get items
-	result, err := c.itemService.GetItems(context.Background(), queryOpts)
+	result, err := c.itemService.GetItems(ctx.Request.Context(), queryOpts, at)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, result)
+	respondPage(ctx, result)
 }
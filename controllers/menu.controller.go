@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/aldiandyaIrsyad/uber-eats/services"
+	"github.com/gin-gonic/gin"
+)
+
+type MenuController struct {
+	menuService *services.MenuService
+}
+
+func NewMenuController(menuService *services.MenuService) *MenuController {
+	return &MenuController{
+		menuService: menuService,
+	}
+}
+
+func (c *MenuController) GetRestaurantMenu(ctx *gin.Context) {
+	restaurantID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	menu, err := c.menuService.GetRestaurantMenu(ctx.Request.Context(), restaurantID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, menu)
+}
+
+func (c *MenuController) CreateCategory(ctx *gin.Context) {
+	restaurantID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var category models.MenuCategory
+	if err := bindJSON(ctx, &category); err != nil {
+		ctx.Error(err)
+		return
+	}
+	category.RestaurantID = restaurantID
+
+	if err := c.menuService.CreateCategory(ctx.Request.Context(), &category); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, category)
+}
+
+func (c *MenuController) GetCategories(ctx *gin.Context) {
+	restaurantID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	categories, err := c.menuService.GetCategoriesByRestaurantID(ctx.Request.Context(), restaurantID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	respondPage(ctx, models.WholePage(categories))
+}
+
+func (c *MenuController) UpdateCategory(ctx *gin.Context) {
+	restaurantID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	categoryID, err := parseID(ctx, "categoryID")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var category models.MenuCategory
+	if err := bindJSON(ctx, &category); err != nil {
+		ctx.Error(err)
+		return
+	}
+	category.ID = categoryID
+	category.RestaurantID = restaurantID
+
+	if err := c.menuService.UpdateCategory(ctx.Request.Context(), &category); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Category updated successfully"})
+}
+
+func (c *MenuController) DeleteCategory(ctx *gin.Context) {
+	restaurantID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	categoryID, err := parseID(ctx, "categoryID")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err := c.menuService.DeleteCategory(ctx.Request.Context(), restaurantID, categoryID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
+}
+
+func (c *MenuController) CreateMenu(ctx *gin.Context) {
+	restaurantID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var menu models.Menu
+	if err := bindJSON(ctx, &menu); err != nil {
+		ctx.Error(err)
+		return
+	}
+	menu.RestaurantID = restaurantID
+
+	if err := c.menuService.CreateMenu(ctx.Request.Context(), &menu); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, menu)
+}
+
+func (c *MenuController) GetMenus(ctx *gin.Context) {
+	restaurantID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	menus, err := c.menuService.GetMenusByRestaurantID(ctx.Request.Context(), restaurantID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	respondPage(ctx, models.WholePage(menus))
+}
+
+func (c *MenuController) UpdateMenu(ctx *gin.Context) {
+	restaurantID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	menuID, err := parseID(ctx, "menuID")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var menu models.Menu
+	if err := bindJSON(ctx, &menu); err != nil {
+		ctx.Error(err)
+		return
+	}
+	menu.ID = menuID
+	menu.RestaurantID = restaurantID
+
+	if err := c.menuService.UpdateMenu(ctx.Request.Context(), &menu); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Menu updated successfully"})
+}
+
+func (c *MenuController) DeleteMenu(ctx *gin.Context) {
+	restaurantID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	menuID, err := parseID(ctx, "menuID")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err := c.menuService.DeleteMenu(ctx.Request.Context(), restaurantID, menuID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Menu deleted successfully"})
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/aldiandyaIrsyad/uber-eats/middleware"
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/aldiandyaIrsyad/uber-eats/services"
+	"github.com/gin-gonic/gin"
+)
+
+type OrderController struct {
+	orderService *services.OrderService
+}
+
+func NewOrderController(orderService *services.OrderService) *OrderController {
+	return &OrderController{
+		orderService: orderService,
+	}
+}
+
+func (c *OrderController) CreateOrder(ctx *gin.Context) {
+	var order models.Order
+	if err := bindJSON(ctx, &order); err != nil {
+		ctx.Error(err)
+		return
+	}
+	user, _ := middleware.CurrentUser(ctx)
+	order.CustomerID = user.ID
+
+	if err := c.orderService.CreateOrder(ctx.Request.Context(), &order); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, order)
+}
+
+func (c *OrderController) GetOrderByID(ctx *gin.Context) {
+	orderID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	order, err := c.orderService.GetOrderByID(ctx.Request.Context(), orderID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, order)
+}
+
+func (c *OrderController) GetOrdersByRestaurantID(ctx *gin.Context) {
+	restaurantID, err := parseID(ctx, "restaurantID")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	// Newest first
+	opts, err := parseSortAndPage(ctx, models.OrderSorts, "createdAt:desc")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	orders, err := c.orderService.GetOrdersByRestaurantID(ctx.Request.Context(), restaurantID, opts)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	respondPage(ctx, orders)
+}
+
+func (c *OrderController) UpdateOrderStatus(ctx *gin.Context) {
+	orderID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var body struct {
+		Status string `json:"status" binding:"required"`
+	}
+	if err := bindJSON(ctx, &body); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	user, _ := middleware.CurrentUser(ctx)
+	order, err := c.orderService.UpdateOrderStatus(ctx.Request.Context(), orderID, body.Status, user)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, order)
+}
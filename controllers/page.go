@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/gin-gonic/gin"
+)
+
+// respondPage writes a page of a listing along with an RFC 8288 Link header to its first, previous,
+// next and, when the total is known for numbered pages, last pages
+func respondPage[T any](ctx *gin.Context, page *models.Page[T]) {
+	if links := pageLinks(ctx.Request.URL, &page.Pagination); links != "" {
+		ctx.Header("Link", links)
+	}
+	ctx.JSON(http.StatusOK, page)
+}
+
+// pageLinks builds the Link header of a page from the request URL, so the links keep its filter
+// and sort. Previous and next pages are linked by cursor.
+func pageLinks(requestURL *url.URL, pagination *models.Pagination) string {
+	link := func(rel string, set map[string]string) string {
+		query := requestURL.Query()
+		query.Del("page")
+		query.Del("cursor")
+		for key, value := range set {
+			query.Set(key, value)
+		}
+		target := url.URL{Path: requestURL.Path, RawQuery: query.Encode()}
+		return "<" + target.String() + `>; rel="` + rel + `"`
+	}
+
+	links := []string{link("first", nil)}
+	if pagination.PrevCursor != "" {
+		links = append(links, link("prev", map[string]string{"cursor": pagination.PrevCursor}))
+	}
+	if pagination.NextCursor != "" {
+		links = append(links, link("next", map[string]string{"cursor": pagination.NextCursor}))
+	}
+	if pagination.Page > 0 && pagination.TotalPages != nil && *pagination.TotalPages > 0 {
+		links = append(links, link("last", map[string]string{"page": strconv.FormatInt(*pagination.TotalPages, 10)}))
+	}
+	return strings.Join(links, ", ")
+}
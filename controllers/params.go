@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/aldiandyaIrsyad/uber-eats/services"
+	"github.com/gin-gonic/gin"
+)
+
+// parseLatLng reads the lat and lng query parameters
+func parseLatLng(ctx *gin.Context) (float64, float64, error) {
+	lat, err := strconv.ParseFloat(ctx.Query("lat"), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return 0, 0, services.InvalidField("lat", "invalid_query", "lat must be a latitude between -90 and 90")
+	}
+	lng, err := strconv.ParseFloat(ctx.Query("lng"), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return 0, 0, services.InvalidField("lng", "invalid_query", "lng must be a longitude between -180 and 180")
+	}
+	return lat, lng, nil
+}
+
+// parseRadius reads the optional radius query parameter in meters
+func parseRadius(ctx *gin.Context) (float64, error) {
+	radius, err := strconv.ParseFloat(ctx.DefaultQuery("radius", strconv.Itoa(defaultNearbyRadius)), 64)
+	if err != nil || radius <= 0 || radius > maxNearbyRadius {
+		return 0, services.InvalidField("radius", "invalid_query", "radius must be between 0 and 50000 meters")
+	}
+	return radius, nil
+}
+
+// parseOpenNow reads the optional openNow query parameter
+func parseOpenNow(ctx *gin.Context) (bool, error) {
+	openNow, err := strconv.ParseBool(ctx.DefaultQuery("openNow", "false"))
+	if err != nil {
+		return false, services.InvalidField("openNow", "invalid_query", "openNow must be true or false")
+	}
+	return openNow, nil
+}
+
+// parseAt reads the optional at query parameter, an RFC 3339 time. It returns nil when at is absent.
+func parseAt(ctx *gin.Context) (*time.Time, error) {
+	value := ctx.Query("at")
+	if value == "" {
+		return nil, nil
+	}
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, services.InvalidField("at", "invalid_query", "at must be an RFC 3339 time, such as 2024-05-01T12:30:00Z")
+	}
+	return &at, nil
+}
+
+// parsePagination reads page, pageSize, cursor and total. A cursor is used instead of page and
+// has to come from a listing with the same sort. The total costs another query, so by default it
+// is only counted for numbered pages; total=true or total=false overrides that.
+func parsePagination(ctx *gin.Context, sort *models.SortOptions) (*models.PaginationOptions, error) {
+	page, _ := strconv.ParseInt(ctx.DefaultQuery("page", "1"), 10, 64)
+	pageSize, _ := strconv.ParseInt(ctx.DefaultQuery("pageSize", "10"), 10, 64)
+	pagination := models.NewPagination(page, pageSize)
+	pagination.Validate()
+
+	options := &models.PaginationOptions{Page: pagination.Page, PageSize: pagination.PageSize}
+	if token := ctx.Query("cursor"); token != "" {
+		cursor, err := models.DecodeCursor(token)
+		if err != nil {
+			return nil, invalidQuery("cursor", err)
+		}
+		if err := cursor.Check(sort); err != nil {
+			return nil, invalidQuery("cursor", err)
+		}
+		options.Cursor = cursor
+	}
+
+	options.WithTotal = options.Cursor == nil
+	if value := ctx.Query("total"); value != "" {
+		withTotal, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, services.InvalidField("total", "invalid_query", "total must be true or false")
+		}
+		options.WithTotal = withTotal
+	}
+	return options, nil
+}
+
+// parseSortAndPage reads the sort and page parameters of a listing without filters. defaultSort
+// applies when no sort is given.
+func parseSortAndPage(ctx *gin.Context, schema models.SortSchema, defaultSort string) (models.QueryOptions, error) {
+	sort, err := models.ParseSort(ctx.DefaultQuery("sort", defaultSort), schema)
+	if err != nil {
+		return models.QueryOptions{}, invalidQuery("sort", err)
+	}
+	pagination, err := parsePagination(ctx, sort)
+	if err != nil {
+		return models.QueryOptions{}, err
+	}
+	return models.QueryOptions{Sort: sort, Pagination: pagination}, nil
+}
@@ -1,14 +1,17 @@
 package controllers
 
 import (
-	"context"
 	"net/http"
-	"strconv"
 
+	"github.com/aldiandyaIrsyad/uber-eats/middleware"
 	"github.com/aldiandyaIrsyad/uber-eats/models"
 	"github.com/aldiandyaIrsyad/uber-eats/services"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultNearbyRadius = 5000
+	maxNearbyRadius     = 50000
 )
 
 type RestaurantController struct {
@@ -23,13 +26,15 @@ func NewRestaurantController(restaurantService *services.RestaurantService) *Res
 
 func (c *RestaurantController) CreateRestaurant(ctx *gin.Context) {
 	var restaurant models.Restaurant
-	if err := ctx.ShouldBindJSON(&restaurant); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(ctx, &restaurant); err != nil {
+		ctx.Error(err)
 		return
 	}
+	user, _ := middleware.CurrentUser(ctx)
+	restaurant.OwnerID = user.ID
 
-	if err := c.restaurantService.CreateRestaurant(context.Background(), &restaurant); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := c.restaurantService.CreateRestaurant(ctx.Request.Context(), &restaurant); err != nil {
+		ctx.Error(err)
 		return
 	}
 
@@ -37,16 +42,21 @@ func (c *RestaurantController) CreateRestaurant(ctx *gin.Context) {
 }
 
 func (c *RestaurantController) GetRestaurantByID(ctx *gin.Context) {
-	id := ctx.Param("id")
-	restaurantID, err := primitive.ObjectIDFromHex(id)
+	restaurantID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	at, err := parseAt(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(err)
 		return
 	}
 
-	restaurant, err := c.restaurantService.GetRestaurantByID(context.Background(), restaurantID)
+	restaurant, err := c.restaurantService.GetRestaurantByID(ctx.Request.Context(), restaurantID, at)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "Restaurant not found"})
+		ctx.Error(err)
 		return
 	}
 
@@ -54,21 +64,20 @@ func (c *RestaurantController) GetRestaurantByID(ctx *gin.Context) {
 }
 
 func (c *RestaurantController) UpdateRestaurant(ctx *gin.Context) {
-	id := ctx.Param("id")
-	restaurantID, err := primitive.ObjectIDFromHex(id)
+	restaurantID, err := parseID(ctx, "id")
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(err)
 		return
 	}
 
 	var update map[string]interface{}
-	if err := ctx.ShouldBindJSON(&update); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(ctx, &update); err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	if err := c.restaurantService.UpdateRestaurant(context.Background(), restaurantID, update); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := c.restaurantService.UpdateRestaurant(ctx.Request.Context(), restaurantID, update); err != nil {
+		ctx.Error(err)
 		return
 	}
 
@@ -76,15 +85,14 @@ func (c *RestaurantController) UpdateRestaurant(ctx *gin.Context) {
 }
 
 func (c *RestaurantController) DeleteRestaurant(ctx *gin.Context) {
-	id := ctx.Param("id")
-	restaurantID, err := primitive.ObjectIDFromHex(id)
+	restaurantID, err := parseID(ctx, "id")
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(err)
 		return
 	}
 
-	if err := c.restaurantService.DeleteRestaurant(context.Background(), restaurantID); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := c.restaurantService.DeleteRestaurant(ctx.Request.Context(), restaurantID); err != nil {
+		ctx.Error(err)
 		return
 	}
 
@@ -92,16 +100,15 @@ func (c *RestaurantController) DeleteRestaurant(ctx *gin.Context) {
 }
 
 func (c *RestaurantController) GetAverageRating(ctx *gin.Context) {
-	id := ctx.Param("id")
-	restaurantID, err := primitive.ObjectIDFromHex(id)
+	restaurantID, err := parseID(ctx, "id")
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(err)
 		return
 	}
 
-	averageRating, err := c.restaurantService.GetAverageRating(context.Background(), restaurantID)
+	averageRating, err := c.restaurantService.GetAverageRating(ctx.Request.Context(), restaurantID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -109,24 +116,169 @@ func (c *RestaurantController) GetAverageRating(ctx *gin.Context) {
 }
 
 func (c *RestaurantController) GetRestaurants(ctx *gin.Context) {
-	var filter map[string]interface{}
-	if err := ctx.ShouldBindJSON(&filter); err != nil {
-		filter = nil // If JSON is empty or invalid, use no filter
+	openNow, err := parseOpenNow(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	opts, err := restaurantListParams(ctx, "")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	restaurants, err := c.restaurantService.GetRestaurants(ctx.Request.Context(), opts, openNow)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	respondPage(ctx, restaurants)
+}
+
+func (c *RestaurantController) GetNearbyRestaurants(ctx *gin.Context) {
+	lat, lng, err := parseLatLng(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	radius, err := parseRadius(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	openNow, err := parseOpenNow(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	// Nearest first unless sorted otherwise
+	opts, err := restaurantListParams(ctx, "distance")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	query := models.NearbyQuery{Latitude: lat, Longitude: lng, RadiusMeters: radius}
+
+	restaurants, err := c.restaurantService.GetNearbyRestaurants(ctx.Request.Context(), query, opts, openNow)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	respondPage(ctx, restaurants)
+}
+
+func (c *RestaurantController) RecomputeRatings(ctx *gin.Context) {
+	count, err := c.restaurantService.RecomputeRatings(ctx.Request.Context())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Ratings recomputed successfully", "reviewedRestaurants": count})
+}
+
+func (c *RestaurantController) GetHoursExceptions(ctx *gin.Context) {
+	restaurantID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	exceptions, err := c.restaurantService.GetHoursExceptions(ctx.Request.Context(), restaurantID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	respondPage(ctx, models.WholePage(exceptions))
+}
+
+func (c *RestaurantController) AddHoursException(ctx *gin.Context) {
+	restaurantID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var exception models.HoursException
+	if err := bindJSON(ctx, &exception); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err := c.restaurantService.AddHoursException(ctx.Request.Context(), restaurantID, &exception); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, exception)
+}
+
+func (c *RestaurantController) UpdateHoursException(ctx *gin.Context) {
+	restaurantID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	exceptionID, err := parseID(ctx, "exceptionID")
+	if err != nil {
+		ctx.Error(err)
+		return
 	}
 
-	page, _ := strconv.ParseInt(ctx.DefaultQuery("page", "1"), 10, 64)
-	pageSize, _ := strconv.ParseInt(ctx.DefaultQuery("pageSize", "10"), 10, 64)
+	var exception models.HoursException
+	if err := bindJSON(ctx, &exception); err != nil {
+		ctx.Error(err)
+		return
+	}
+	exception.ID = exceptionID
 
-	pagination := &models.Pagination{
-		Page:     page,
-		PageSize: pageSize,
+	if err := c.restaurantService.UpdateHoursException(ctx.Request.Context(), restaurantID, &exception); err != nil {
+		ctx.Error(err)
+		return
 	}
 
-	restaurants, err := c.restaurantService.GetRestaurants(context.Background(), filter, pagination)
+	ctx.JSON(http.StatusOK, exception)
+}
+
+func (c *RestaurantController) DeleteHoursException(ctx *gin.Context) {
+	restaurantID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	exceptionID, err := parseID(ctx, "exceptionID")
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, restaurants)
+	if err := c.restaurantService.DeleteHoursException(ctx.Request.Context(), restaurantID, exceptionID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Hours exception deleted successfully"})
+}
+
+// restaurantListParams reads the filter, sort and page parameters shared by the restaurant listings.
+// defaultSort applies when no sort is given.
+func restaurantListParams(ctx *gin.Context, defaultSort string) (models.QueryOptions, error) {
+	filter, err := models.ParseFilter(ctx.Query("filter"), models.RestaurantFilters)
+	if err != nil {
+		return models.QueryOptions{}, invalidQuery("filter", err)
+	}
+	sort, err := models.ParseSort(ctx.DefaultQuery("sort", defaultSort), models.RestaurantSorts)
+	if err != nil {
+		return models.QueryOptions{}, invalidQuery("sort", err)
+	}
+	pagination, err := parsePagination(ctx, sort)
+	if err != nil {
+		return models.QueryOptions{}, err
+	}
+
+	return models.QueryOptions{Filter: filter, Sort: sort, Pagination: pagination}, nil
 }
@@ -1,13 +1,12 @@
 package controllers
 
 import (
-	"context"
 	"net/http"
 
+	"github.com/aldiandyaIrsyad/uber-eats/middleware"
 	"github.com/aldiandyaIrsyad/uber-eats/models"
 	"github.com/aldiandyaIrsyad/uber-eats/services"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type ReviewController struct {
@@ -22,47 +21,94 @@ func NewReviewController(reviewService *services.ReviewService) *ReviewControlle
 
 func (c *ReviewController) CreateReview(ctx *gin.Context) {
 	var review models.Review
-	if err := ctx.ShouldBindJSON(&review); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(ctx, &review); err != nil {
+		ctx.Error(err)
 		return
 	}
+	user, _ := middleware.CurrentUser(ctx)
+	review.UserID = user.ID
 
-	if err := c.reviewService.CreateReview(context.Background(), &review); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := c.reviewService.CreateReview(ctx.Request.Context(), &review); err != nil {
+		ctx.Error(err)
 		return
 	}
 
 	ctx.JSON(http.StatusCreated, review)
 }
 
+func (c *ReviewController) UpdateReview(ctx *gin.Context) {
+	reviewID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var body struct {
+		Rating  int    `json:"rating" binding:"required,min=1,max=5"`
+		Comment string `json:"comment" binding:"required,min=10,max=1000"`
+	}
+	if err := bindJSON(ctx, &body); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	review, err := c.reviewService.UpdateReview(ctx.Request.Context(), reviewID, body.Rating, body.Comment)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, review)
+}
+
+func (c *ReviewController) DeleteReview(ctx *gin.Context) {
+	reviewID, err := parseID(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err := c.reviewService.DeleteReview(ctx.Request.Context(), reviewID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Review deleted successfully"})
+}
+
 func (c *ReviewController) GetReviewsByRestaurantID(ctx *gin.Context) {
-	id := ctx.Param("restaurantID")
-	restaurantID, err := primitive.ObjectIDFromHex(id)
+	restaurantID, err := parseID(ctx, "restaurantID")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	// Newest first unless sorted otherwise
+	opts, err := parseSortAndPage(ctx, models.ReviewSorts, "createdAt:desc")
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(err)
 		return
 	}
 
-	reviews, err := c.reviewService.GetReviewsByRestaurantID(context.Background(), restaurantID)
+	reviews, err := c.reviewService.GetReviewsByRestaurantID(ctx.Request.Context(), restaurantID, opts)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, reviews)
+	respondPage(ctx, reviews)
 }
 
 func (c *ReviewController) GetAverageRatingByRestaurantID(ctx *gin.Context) {
-	id := ctx.Param("restaurantID")
-	restaurantID, err := primitive.ObjectIDFromHex(id)
+	restaurantID, err := parseID(ctx, "restaurantID")
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(err)
 		return
 	}
 
-	averageRating, err := c.reviewService.GetAverageRatingByRestaurantID(context.Background(), restaurantID)
+	averageRating, err := c.reviewService.GetAverageRatingByRestaurantID(ctx.Request.Context(), restaurantID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
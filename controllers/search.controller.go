@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/aldiandyaIrsyad/uber-eats/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+	maxSearchLength    = 100
+
+	defaultSuggestLimit = 8
+	maxSuggestLimit     = 20
+)
+
+var errInvalidSearchText = services.InvalidField("q", "invalid_query", "q must be between 1 and 100 characters")
+
+type SearchController struct {
+	searchService  *services.SearchService
+	suggestService *services.SuggestService
+}
+
+func NewSearchController(searchService *services.SearchService, suggestService *services.SuggestService) *SearchController {
+	return &SearchController{
+		searchService:  searchService,
+		suggestService: suggestService,
+	}
+}
+
+func (c *SearchController) Search(ctx *gin.Context) {
+	text := strings.TrimSpace(ctx.Query("q"))
+	if text == "" || len(text) > maxSearchLength {
+		ctx.Error(errInvalidSearchText)
+		return
+	}
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(defaultSearchLimit)))
+	if err != nil || limit < 1 || limit > maxSearchLimit {
+		ctx.Error(services.InvalidField("limit", "invalid_query", "limit must be between 1 and 50"))
+		return
+	}
+	query := models.SearchQuery{Text: text, Limit: limit}
+
+	// The search is only limited to an area when a point is given
+	if ctx.Query("lat") != "" || ctx.Query("lng") != "" {
+		lat, lng, err := parseLatLng(ctx)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		radius, err := parseRadius(ctx)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		query.Near = &models.NearbyQuery{Latitude: lat, Longitude: lng, RadiusMeters: radius}
+	}
+
+	results, err := c.searchService.Search(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"query": text, "results": results})
+}
+
+func (c *SearchController) Suggest(ctx *gin.Context) {
+	text := strings.TrimSpace(ctx.Query("q"))
+	if text == "" || len(text) > maxSearchLength {
+		ctx.Error(errInvalidSearchText)
+		return
+	}
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(defaultSuggestLimit)))
+	if err != nil || limit < 1 || limit > maxSuggestLimit {
+		ctx.Error(services.InvalidField("limit", "invalid_query", "limit must be between 1 and 20"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"query": text, "suggestions": c.suggestService.Suggest(text, limit)})
+}
@@ -1,26 +1,85 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/aldiandyaIrsyad/uber-eats/config"
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/aldiandyaIrsyad/uber-eats/repos"
 	"github.com/aldiandyaIrsyad/uber-eats/routes"
 	"github.com/aldiandyaIrsyad/uber-eats/seeders"
+	"github.com/aldiandyaIrsyad/uber-eats/services"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
-	// Connect to MongoDB
-	client, ctx, cancel := config.ConnectDB()
-	defer cancel()
-	defer func() {
-		if err := client.Disconnect(ctx); err != nil {
-			log.Fatal(err)
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run starts the server and blocks until it has shut down. Errors are returned rather than fatal,
+// so the deferred shutdown steps still run.
+func run() error {
+	recomputeRatings := flag.Bool("recompute-ratings", false, "rebuild restaurant rating aggregates from the reviews collection and exit")
+	memory := flag.Bool("memory", false, "run against an in-memory store instead of MongoDB; data is lost on exit")
+	dev := flag.Bool("dev", false, "allow the development JWT secret; for local use only")
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "YAML config file; environment variables override its settings")
+	flag.Parse()
+
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		return err
+	}
+	if cfg.Auth.UsesPublicSecret() && !*dev {
+		return errors.New("JWT_SECRET is not set or is a published development value; set a secret of your own, or pass -dev to run locally")
+	}
+
+	// Cursors are signed, so clients can't forge the sort values they carry
+	models.SetCursorSecret(cfg.Auth.CursorSecret)
+
+	var repositories repos.Repositories
+	var database seeders.Database
+	if *memory {
+		store := repos.NewMemoryStore()
+		repositories = repos.NewMemoryRepositories(store)
+		database = seeders.MemoryDatabase(store)
+		log.Println("Using the in-memory store; data is lost on exit")
+	} else {
+		// Connect to MongoDB. It is disconnected last, once requests and jobs are done with it.
+		client := config.ConnectDB(cfg.Mongo)
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+			defer cancel()
+			if err := client.Disconnect(ctx); err != nil {
+				log.Printf("Error disconnecting from MongoDB: %v", err)
+			}
+		}()
+		db := client.Database(cfg.Mongo.Database)
+		repositories = repos.NewMongoRepositories(db)
+		database = seeders.MongoDatabase(db)
+	}
+
+	if *recomputeRatings {
+		suggestService := services.NewSuggestService(repositories.Restaurants, repositories.Items)
+		restaurantService := services.NewRestaurantService(repositories.Restaurants, repositories.Reviews, repositories.Menus, suggestService)
+		count, err := restaurantService.RecomputeRatings(context.Background())
+		if err != nil {
+			return err
 		}
-	}()
+		log.Printf("Recomputed ratings for %d reviewed restaurants", count)
+		return nil
+	}
 
 	// Seed database
-	if err := seeders.SeedDatabase(client); err != nil {
+	if err := seeders.SeedDatabase(database); err != nil {
 		log.Printf("Error seeding database: %v", err)
 	}
 
@@ -28,11 +87,50 @@ func main() {
 	r := gin.Default()
 
 	// Setup routes with dependency injection
-	routeHandler := routes.NewRouteHandler(client)
+	routeHandler := routes.NewRouteHandler(cfg, repositories)
 	routeHandler.SetupRoutes(r)
+	if count, err := routeHandler.BackfillOpenIntervals(context.Background()); err != nil {
+		log.Printf("Error backfilling open intervals: %v", err)
+	} else if count > 0 {
+		log.Printf("Computed open intervals for %d restaurants", count)
+	}
+	if err := routeHandler.LoadSearchIndex(context.Background()); err != nil {
+		return err
+	}
+
+	// Start background jobs, such as daily stock resets
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	waitJobs := routeHandler.StartBackgroundJobs(jobsCtx)
 
 	// Start server
-	if err := r.Run(":8080"); err != nil {
-		log.Fatal(err)
+	server := &http.Server{Addr: cfg.Server.Addr, Handler: r}
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	// Run until SIGINT or SIGTERM. Once the first one is received a second one kills the process.
+	signals, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+	var runErr error
+	select {
+	case runErr = <-serverErr:
+		// Reported by main once everything has been shut down
+	case <-signals.Done():
+		stopSignals()
+	}
+
+	// Stop accepting connections and let in-flight requests finish, up to the shutdown timeout
+	log.Printf("Shutting down, draining requests for up to %s", cfg.Server.ShutdownTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Error draining requests: %v", err)
 	}
+
+	stopJobs()
+	waitJobs()
+	log.Println("Server stopped")
+	return runErr
 }
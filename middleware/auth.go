@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/aldiandyaIrsyad/uber-eats/services"
+	"github.com/gin-gonic/gin"
+)
+
+const currentUserKey = "currentUser"
+
+var errBearerScheme = services.Unauthorized("invalid_authorization", "Authorization header must use the Bearer scheme")
+
+// Authenticate attaches the caller to the request context when a bearer token is sent.
+// Requests without a token continue anonymously; requests with a bad token are rejected.
+func Authenticate(authService *services.AuthService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		header := ctx.GetHeader("Authorization")
+		if header == "" {
+			ctx.Next()
+			return
+		}
+
+		tokenString, found := strings.CutPrefix(header, "Bearer ")
+		if !found {
+			ctx.Error(errBearerScheme)
+			ctx.Abort()
+			return
+		}
+
+		user, err := authService.ParseToken(tokenString)
+		if err != nil {
+			ctx.Error(err)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Set(currentUserKey, user)
+		ctx.Next()
+	}
+}
+
+// CurrentUser returns the authenticated caller, if any
+func CurrentUser(ctx *gin.Context) (*models.AuthUser, bool) {
+	value, exists := ctx.Get(currentUserKey)
+	if !exists {
+		return nil, false
+	}
+	user, ok := value.(*models.AuthUser)
+	return user, ok
+}
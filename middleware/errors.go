@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/aldiandyaIrsyad/uber-eats/services"
+	"github.com/gin-gonic/gin"
+)
+
+const problemContentType = "application/problem+json"
+
+// statusClientClosedRequest is the status nginx logs for requests the client gave up on. Nobody
+// reads the response, but it tells them apart from server errors in the access log.
+const statusClientClosedRequest = 499
+
+// Problem is an RFC 7807 problem details response. Code identifies the problem to clients and
+// Errors lists the invalid fields of a validation problem.
+type Problem struct {
+	Type     string                `json:"type"`
+	Title    string                `json:"title"`
+	Status   int                   `json:"status"`
+	Detail   string                `json:"detail,omitempty"`
+	Instance string                `json:"instance,omitempty"`
+	Code     string                `json:"code"`
+	Errors   []services.FieldError `json:"errors,omitempty"`
+}
+
+// kindStatus is the HTTP status of each kind of service error
+var kindStatus = map[services.Kind]int{
+	services.KindNotFound:     http.StatusNotFound,
+	services.KindConflict:     http.StatusConflict,
+	services.KindValidation:   http.StatusBadRequest,
+	services.KindUnauthorized: http.StatusUnauthorized,
+	services.KindForbidden:    http.StatusForbidden,
+	services.KindUnavailable:  http.StatusServiceUnavailable,
+}
+
+// Errors renders the last error a handler attached with ctx.Error as problem+json, unless a
+// response was already written. Errors that aren't service errors are hidden behind a generic
+// 500; gin's logger still records them in full.
+func Errors() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		last := ctx.Errors.Last()
+		if last == nil || ctx.Writer.Written() {
+			return
+		}
+		writeProblem(ctx, NewProblem(last.Err))
+	}
+}
+
+// NoRoute answers requests for a path no route matches
+func NoRoute() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Error(services.ErrNotFound)
+	}
+}
+
+// NoMethod answers requests for a path that has routes, but none for the request's method
+func NoMethod() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		writeProblem(ctx, newProblem(http.StatusMethodNotAllowed, "method_not_allowed", ctx.Request.Method+" is not allowed on this resource"))
+	}
+}
+
+func writeProblem(ctx *gin.Context, problem *Problem) {
+	problem.Instance = ctx.Request.URL.Path
+	ctx.Header("Content-Type", problemContentType)
+	ctx.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// NewProblem describes err to an API client
+func NewProblem(err error) *Problem {
+	serviceErr := services.AsError(err)
+	if serviceErr == nil {
+		return newProblem(http.StatusInternalServerError, "internal_error", "an unexpected error occurred")
+	}
+
+	status := kindStatus[serviceErr.Kind]
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		status = http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		status = statusClientClosedRequest
+	}
+
+	// Wrapping adds context to the message of a service error, such as which option is invalid.
+	// Errors classified from the database keep their own message, since the cause is only for
+	// the logs.
+	detail := serviceErr.Message
+	var wrapped *services.Error
+	if errors.As(err, &wrapped) && wrapped.Kind != services.KindUnavailable {
+		detail = err.Error()
+	}
+
+	problem := newProblem(status, serviceErr.Code, detail)
+	problem.Errors = serviceErr.Fields
+	if len(problem.Errors) == 0 && serviceErr.Field != "" {
+		problem.Errors = []services.FieldError{{Field: serviceErr.Field, Code: serviceErr.Code, Message: detail}}
+	}
+	return problem
+}
+
+func newProblem(status int, code, detail string) *Problem {
+	title := http.StatusText(status)
+	if status == statusClientClosedRequest {
+		title = "Client Closed Request"
+	}
+	return &Problem{
+		Type:   "about:blank",
+		Title:  title,
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
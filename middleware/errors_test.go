@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aldiandyaIrsyad/uber-eats/services"
+	"github.com/gin-gonic/gin"
+)
+
+func TestNewProblem(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"service error", services.ErrNotFound, http.StatusNotFound, "not_found"},
+		{"wrapped service error", fmt.Errorf("%w: size", services.Validation("invalid_body", "invalid body")), http.StatusBadRequest, "invalid_body"},
+		{"timeout", fmt.Errorf("query: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, "timeout"},
+		{"cancelled", fmt.Errorf("query: %w", context.Canceled), statusClientClosedRequest, "request_cancelled"},
+		{"unexpected", errors.New("boom"), http.StatusInternalServerError, "internal_error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem := NewProblem(tt.err)
+			if problem.Status != tt.status || problem.Code != tt.code || problem.Title == "" {
+				t.Errorf("problem = %+v, want status %d and code %s", problem, tt.status, tt.code)
+			}
+		})
+	}
+}
+
+func TestErrorsAnswersUnknownRoutesWithProblems(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Errors())
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(NoRoute())
+	router.NoMethod(NoMethod())
+	router.GET("/api/restaurants", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	tests := []struct {
+		method, path string
+		status       int
+	}{
+		{http.MethodGet, "/api/restaurants", http.StatusOK},
+		{http.MethodGet, "/api/nothing", http.StatusNotFound},
+		{http.MethodDelete, "/api/restaurants", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.path, nil))
+			if recorder.Code != tt.status {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.status)
+			}
+			if tt.status != http.StatusOK && recorder.Header().Get("Content-Type") != problemContentType {
+				t.Errorf("Content-Type = %q, want %s", recorder.Header().Get("Content-Type"), problemContentType)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout gives every request a deadline on its context, so the database calls made for it are
+// cancelled once it passes. routeTimeouts overrides the default for single routes, keyed by method
+// and route pattern such as "POST /api/cart/checkout".
+func Timeout(defaultTimeout time.Duration, routeTimeouts map[string]time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		timeout, found := routeTimeouts[ctx.Request.Method+" "+ctx.FullPath()]
+		if !found {
+			timeout = defaultTimeout
+		}
+
+		requestCtx, cancel := context.WithTimeout(ctx.Request.Context(), timeout)
+		defer cancel()
+		ctx.Request = ctx.Request.WithContext(requestCtx)
+
+		ctx.Next()
+
+		// A handler that gave up without reporting why still owes the caller a response
+		if !ctx.Writer.Written() && len(ctx.Errors) == 0 && errors.Is(requestCtx.Err(), context.DeadlineExceeded) {
+			ctx.Error(requestCtx.Err())
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditEvent records a request that an authorization policy refused
+type AuditEvent struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID `bson:"userId" json:"userId"`
+	Role      string             `bson:"role" json:"role"`
+	Policy    string             `bson:"policy" json:"policy"`
+	Reason    string             `bson:"reason" json:"reason"`
+	Method    string             `bson:"method" json:"method"`
+	Path      string             `bson:"path" json:"path"`
+	ClientIP  string             `bson:"clientIp" json:"clientIp"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CartItem is a line in the cart. The same item can be on several lines with different modifiers,
+// so lines are addressed by LineID.
+type CartItem struct {
+	LineID    primitive.ObjectID `bson:"lineId" json:"lineId"`
+	ItemID    primitive.ObjectID `bson:"itemId" json:"itemId" validate:"required"`
+	Name      string             `bson:"name" json:"name"`
+	Price     float64            `bson:"price" json:"price"`
+	Modifiers []SelectedModifier `bson:"modifiers,omitempty" json:"modifiers,omitempty"`
+	UnitPrice float64            `bson:"unitPrice" json:"unitPrice"`
+	Quantity  int                `bson:"quantity" json:"quantity" validate:"required,min=1"`
+	LineTotal float64            `bson:"lineTotal" json:"lineTotal"`
+}
+
+// PriceBreakdown is what the customer pays, split the way it's shown at checkout
+type PriceBreakdown struct {
+	Subtotal    float64 `bson:"subtotal" json:"subtotal"`
+	Tax         float64 `bson:"tax" json:"tax"`
+	ServiceFee  float64 `bson:"serviceFee" json:"serviceFee"`
+	DeliveryFee float64 `bson:"deliveryFee" json:"deliveryFee"`
+	Total       float64 `bson:"total" json:"total"`
+}
+
+// Cart holds a customer's items before checkout. A cart only ever contains items from one restaurant.
+type Cart struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	CustomerID   primitive.ObjectID `bson:"customerId" json:"customerId"`
+	RestaurantID primitive.ObjectID `bson:"restaurantId,omitempty" json:"restaurantId,omitempty"`
+	Items        []CartItem         `bson:"items" json:"items"`
+	CreatedAt    time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time          `bson:"updatedAt" json:"updatedAt"`
+
+	Pricing PriceBreakdown `bson:"-" json:"pricing"`
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrInvalidCursor is returned for cursors that can't be decoded or were taken with another sort
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursorKey signs cursors. Until SetCursorSecret is called it is random, so cursors only work
+// until a restart.
+var cursorKey = func() []byte {
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return key
+}()
+
+// SetCursorSecret derives the key cursors are signed with from secret. It is meant to be called
+// once at startup; instances sharing a secret accept each other's cursors.
+func SetCursorSecret(secret string) {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("cursor"))
+	cursorKey = mac.Sum(nil)
+}
+
+// Cursor marks a position in a sorted listing: the sort key values, _id last, of the row it was
+// taken from. A next cursor continues after that row and a previous cursor ends before it. Cursors
+// are encoded as base64 BSON so the values keep their types, and signed so clients can't make up
+// their own values.
+type Cursor struct {
+	Values []interface{} `bson:"v"`
+	Before bool          `bson:"b,omitempty"`
+	Sort   string        `bson:"s"`
+}
+
+// Encode returns the cursor as an opaque token
+func (c *Cursor) Encode() string {
+	raw, err := bson.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(append(raw, signCursor(raw)...))
+}
+
+// DecodeCursor parses a token returned by Encode, refusing it unless it was signed with the current
+// key
+func DecodeCursor(token string) (*Cursor, error) {
+	signed, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(signed) < sha256.Size {
+		return nil, ErrInvalidCursor
+	}
+	raw, signature := signed[:len(signed)-sha256.Size], signed[len(signed)-sha256.Size:]
+	if !hmac.Equal(signature, signCursor(raw)) {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := bson.Unmarshal(raw, &cursor); err != nil || cursor.Sort == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+func signCursor(raw []byte) []byte {
+	mac := hmac.New(sha256.New, cursorKey)
+	mac.Write(raw)
+	return mac.Sum(nil)
+}
+
+// Check reports whether the cursor can be used with this sort: it was taken from a listing with
+// the same sort and holds a value of the right type for every key. A key's value is nil when the
+// row didn't have the field.
+func (c *Cursor) Check(sort *SortOptions) error {
+	if c.Sort != sort.Signature() || len(c.Values) != len(sort.Keys) {
+		return fmt.Errorf("%w: it was taken with a different sort", ErrInvalidCursor)
+	}
+	for i, key := range sort.Keys {
+		if c.Values[i] != nil && !sortValueHasType(c.Values[i], key.Type) {
+			return fmt.Errorf("%w: bad value for %s", ErrInvalidCursor, key.Field)
+		}
+	}
+	return nil
+}
+
+// sortValueHasType reports whether a decoded cursor value is a scalar of the given filter value type
+func sortValueHasType(value interface{}, valueType string) bool {
+	switch value.(type) {
+	case string:
+		return valueType == FilterString
+	case int32, int64, float64:
+		return valueType == FilterNumber
+	case primitive.ObjectID:
+		return valueType == FilterObjectID
+	case primitive.DateTime:
+		return valueType == FilterTime
+	}
+	return false
+}
+
+// Signature identifies the sort, so a cursor can't be used with a different one
+func (s *SortOptions) Signature() string {
+	keys := make([]string, len(s.Keys))
+	for i, key := range s.Keys {
+		keys[i] = key.Field + ":" + strconv.Itoa(key.Order)
+	}
+	return strings.Join(keys, ",")
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	SetCursorSecret("secret")
+	sort, err := ParseSort("name", RestaurantSorts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := (&Cursor{Values: []interface{}{"Burger Barn", primitive.NewObjectID()}, Sort: sort.Signature()}).Encode()
+
+	cursor, err := DecodeCursor(token)
+	if err != nil {
+		t.Fatalf("DecodeCursor: %v", err)
+	}
+	if err := cursor.Check(sort); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+
+	SetCursorSecret("another secret")
+	if _, err := DecodeCursor(token); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("cursor signed with another secret: err = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func TestCursorRejectsTamperedTokens(t *testing.T) {
+	SetCursorSecret("secret")
+	token := (&Cursor{Values: []interface{}{"a", primitive.NewObjectID()}, Sort: "name:1,_id:1"}).Encode()
+	tampered := []byte(token)
+	tampered[10] ^= 1
+
+	for name, token := range map[string]string{"tampered": string(tampered), "truncated": token[:20], "not base64": "!!!"} {
+		if _, err := DecodeCursor(token); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: err = %v, want ErrInvalidCursor", name, err)
+		}
+	}
+}
+
+func TestCursorCheckValueTypes(t *testing.T) {
+	sort, err := ParseSort("rating:desc", RestaurantSorts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := primitive.NewObjectID()
+	tests := []struct {
+		name   string
+		values []interface{}
+		ok     bool
+	}{
+		{"number", []interface{}{4.5, id}, true},
+		{"missing field", []interface{}{nil, id}, true},
+		{"operator document", []interface{}{bson.D{{Key: "$gt", Value: ""}}, id}, false},
+		{"array", []interface{}{bson.A{1, 2}, id}, false},
+		{"string for a number", []interface{}{"4.5", id}, false},
+		{"string for the id", []interface{}{4.5, id.Hex()}, false},
+		{"too few values", []interface{}{4.5}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := DecodeCursor((&Cursor{Values: tt.values, Sort: sort.Signature()}).Encode())
+			if err != nil {
+				t.Fatalf("DecodeCursor: %v", err)
+			}
+			if err := cursor.Check(sort); (err == nil) != tt.ok {
+				t.Errorf("Check() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GeoPolygon is a GeoJSON polygon: an outer ring followed by optional holes, each ring a closed
+// list of [longitude, latitude] positions
+type GeoPolygon struct {
+	Type        string        `bson:"type" json:"type" validate:"required,eq=Polygon"`
+	Coordinates [][][]float64 `bson:"coordinates" json:"coordinates" validate:"required"`
+}
+
+// DeliveryZone is an area a restaurant delivers to, with its own fee and minimum order
+type DeliveryZone struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	RestaurantID primitive.ObjectID `bson:"restaurantId" json:"restaurantId"`
+	Name         string             `bson:"name" json:"name" validate:"required,min=2,max=100"`
+	Area         GeoPolygon         `bson:"area" json:"area" validate:"required"`
+	DeliveryFee  float64            `bson:"deliveryFee" json:"deliveryFee" validate:"min=0"`
+	MinimumOrder float64            `bson:"minimumOrder" json:"minimumOrder" validate:"min=0"`
+	CreatedAt    time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// DeliveryQuote answers whether a restaurant delivers to a point, and on what terms
+type DeliveryQuote struct {
+	RestaurantID primitive.ObjectID `json:"restaurantId"`
+	Deliverable  bool               `json:"deliverable"`
+	Zone         *DeliveryZone      `json:"zone,omitempty"`
+	DeliveryFee  float64            `json:"deliveryFee,omitempty"`
+	MinimumOrder float64            `json:"minimumOrder,omitempty"`
+}
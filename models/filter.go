@@ -0,0 +1,176 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Value types a filter field can have
+const (
+	FilterString   = "string"
+	FilterNumber   = "number"
+	FilterObjectID = "objectId"
+	FilterTime     = "time"
+)
+
+// filterOperators maps the operators of the filter syntax to MongoDB's
+var filterOperators = map[string]string{
+	"eq":  "$eq",
+	"ne":  "$ne",
+	"gt":  "$gt",
+	"gte": "$gte",
+	"lt":  "$lt",
+	"lte": "$lte",
+	"in":  "$in",
+}
+
+// FilterField is a field a list endpoint can be filtered on. Field is the stored name when it
+// differs from the public one; Values, if set, are the only values allowed.
+type FilterField struct {
+	Field     string
+	Type      string
+	Operators []string
+	Values    []string
+}
+
+// FilterSchema lists the fields of a resource that can be filtered on, by public name
+type FilterSchema map[string]FilterField
+
+var (
+	equality   = []string{"eq", "ne", "in"}
+	comparison = []string{"eq", "ne", "gt", "gte", "lt", "lte"}
+)
+
+// ItemFilters are the filters of the item listing
+var ItemFilters = FilterSchema{
+	"name":         {Type: FilterString, Operators: equality},
+	"price":        {Type: FilterNumber, Operators: comparison},
+	"status":       {Type: FilterString, Operators: equality, Values: []string{ItemStatusAvailable, ItemStatusUnavailable}},
+	"restaurantId": {Type: FilterObjectID, Operators: equality},
+	"categoryId":   {Type: FilterObjectID, Operators: equality},
+	"createdAt":    {Type: FilterTime, Operators: comparison},
+}
+
+// RestaurantFilters are the filters of the restaurant listings
+var RestaurantFilters = FilterSchema{
+	"name":        {Type: FilterString, Operators: equality},
+	"rating":      {Type: FilterNumber, Operators: comparison},
+	"ratingCount": {Type: FilterNumber, Operators: comparison},
+	"timezone":    {Type: FilterString, Operators: equality},
+	"ownerId":     {Type: FilterObjectID, Operators: equality},
+	"createdAt":   {Type: FilterTime, Operators: comparison},
+}
+
+// ParseFilter parses a filter expression such as "price:gte:10,status:eq:available" into a
+// MongoDB filter. Clauses are separated by commas and all have to match; "in" takes values
+// separated by "|". Only the fields and operators in schema are accepted, and values are
+// converted to the field's type, so nothing from the request reaches the query unchecked.
+func ParseFilter(expression string, schema FilterSchema) (map[string]interface{}, error) {
+	filter := make(map[string]interface{})
+	if strings.TrimSpace(expression) == "" {
+		return filter, nil
+	}
+
+	for _, clause := range strings.Split(expression, ",") {
+		parts := strings.SplitN(strings.TrimSpace(clause), ":", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("filter %q must be field:operator:value", clause)
+		}
+		name, operator, raw := parts[0], parts[1], parts[2]
+
+		field, ok := schema[name]
+		if !ok {
+			return nil, fmt.Errorf("cannot filter on %q; allowed fields are %s", name, strings.Join(sortedNames(schema), ", "))
+		}
+		if !containsString(field.Operators, operator) {
+			return nil, fmt.Errorf("operator %q is not allowed on %s; use %s", operator, name, strings.Join(field.Operators, ", "))
+		}
+
+		var value interface{}
+		if operator == "in" {
+			values := make([]interface{}, 0)
+			for _, item := range strings.Split(raw, "|") {
+				parsed, err := field.parseValue(name, item)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, parsed)
+			}
+			value = values
+		} else {
+			parsed, err := field.parseValue(name, raw)
+			if err != nil {
+				return nil, err
+			}
+			value = parsed
+		}
+
+		key := field.Field
+		if key == "" {
+			key = name
+		}
+		conditions, _ := filter[key].(map[string]interface{})
+		if conditions == nil {
+			conditions = make(map[string]interface{})
+			filter[key] = conditions
+		}
+		mongoOperator := filterOperators[operator]
+		if _, exists := conditions[mongoOperator]; exists {
+			return nil, fmt.Errorf("%s:%s is given more than once", name, operator)
+		}
+		conditions[mongoOperator] = value
+	}
+	return filter, nil
+}
+
+func (f FilterField) parseValue(name, raw string) (interface{}, error) {
+	switch f.Type {
+	case FilterNumber:
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number, got %q", name, raw)
+		}
+		return number, nil
+	case FilterObjectID:
+		id, err := primitive.ObjectIDFromHex(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an ID, got %q", name, raw)
+		}
+		return id, nil
+	case FilterTime:
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an RFC 3339 time, got %q", name, raw)
+		}
+		return t, nil
+	default:
+		if len(f.Values) > 0 && !containsString(f.Values, raw) {
+			return nil, fmt.Errorf("%s must be one of %s, got %q", name, strings.Join(f.Values, ", "), raw)
+		}
+		return raw, nil
+	}
+}
+
+// sortedNames returns the keys of a schema in alphabetical order, for error messages
+func sortedNames[V any](schema map[string]V) []string {
+	names := make([]string, 0, len(schema))
+	for name := range schema {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
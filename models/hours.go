@@ -0,0 +1,258 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	// Embedded so restaurant timezones resolve even where the host has no zoneinfo
+	_ "time/tzdata"
+)
+
+const (
+	minutesPerDay  = 24 * 60
+	minutesPerWeek = 7 * minutesPerDay
+
+	// DefaultTimezone is used for restaurants that don't set one
+	DefaultTimezone = "UTC"
+)
+
+// OpenInterval is a span of the week during which a restaurant is open, in minutes since Sunday
+// 00:00 local time. End is exclusive. Intervals are stored on the restaurant so open-now listings
+// can be filtered in the database.
+type OpenInterval struct {
+	Start int `bson:"start" json:"start"`
+	End   int `bson:"end" json:"end"`
+}
+
+// parseDay accepts a weekday name in any case
+func parseDay(day string) (time.Weekday, bool) {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if strings.EqualFold(day, weekday.String()) {
+			return weekday, true
+		}
+	}
+	return 0, false
+}
+
+// parseClock parses a 24-hour "HH:MM" time into minutes since midnight. "24:00" is allowed as a
+// closing time meaning the end of the day.
+func parseClock(clock string, allowEndOfDay bool) (int, bool) {
+	if allowEndOfDay && clock == "24:00" {
+		return minutesPerDay, true
+	}
+	if len(clock) != 5 {
+		return 0, false
+	}
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, false
+	}
+	return parsed.Hour()*60 + parsed.Minute(), true
+}
+
+// TimeLocation returns the restaurant's timezone, falling back to UTC
+func (r *Restaurant) TimeLocation() (*time.Location, error) {
+	if r.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(r.Timezone)
+}
+
+// NormalizeHours validates the timezone and operating hours, writes day names in their canonical
+// form and recomputes OpenIntervals
+func (r *Restaurant) NormalizeHours() error {
+	if r.Timezone == "" {
+		r.Timezone = DefaultTimezone
+	}
+	if _, err := time.LoadLocation(r.Timezone); err != nil {
+		return fmt.Errorf("unknown timezone %q", r.Timezone)
+	}
+
+	intervals, err := weeklyIntervals(r.OperatingHours)
+	if err != nil {
+		return err
+	}
+	r.OpenIntervals = intervals
+	return nil
+}
+
+// weeklyIntervals validates a weekly schedule, writes its day names in their canonical form and
+// converts it to open intervals. A day may have several entries; an entry whose closing time is
+// not after its opening time runs past midnight into the next day.
+func weeklyIntervals(schedule []OperatingHours) ([]OpenInterval, error) {
+	intervals := make([]OpenInterval, 0, len(schedule))
+	for i, hours := range schedule {
+		weekday, ok := parseDay(hours.Day)
+		if !ok {
+			return nil, fmt.Errorf("%q is not a day of the week", hours.Day)
+		}
+		open, ok := parseClock(hours.OpenTime, false)
+		if !ok {
+			return nil, fmt.Errorf("openTime %q must be HH:MM", hours.OpenTime)
+		}
+		closing, ok := parseClock(hours.CloseTime, true)
+		if !ok {
+			return nil, fmt.Errorf("closeTime %q must be HH:MM", hours.CloseTime)
+		}
+		if open == closing {
+			return nil, fmt.Errorf("%s opens and closes at %s", weekday, hours.OpenTime)
+		}
+
+		schedule[i].Day = weekday.String()
+		start := int(weekday)*minutesPerDay + open
+		end := int(weekday)*minutesPerDay + closing
+		if closing < open {
+			end += minutesPerDay
+		}
+		intervals = append(intervals, OpenInterval{Start: start, End: end})
+	}
+
+	return splitAtWeekEnd(intervals), nil
+}
+
+// splitAtWeekEnd wraps intervals that run past Saturday midnight back to the start of the week
+func splitAtWeekEnd(intervals []OpenInterval) []OpenInterval {
+	split := make([]OpenInterval, 0, len(intervals))
+	for _, interval := range intervals {
+		if interval.End <= minutesPerWeek {
+			split = append(split, interval)
+			continue
+		}
+		split = append(split,
+			OpenInterval{Start: interval.Start, End: minutesPerWeek},
+			OpenInterval{Start: 0, End: interval.End - minutesPerWeek},
+		)
+	}
+	return split
+}
+
+// MinuteOfWeek returns t's position in the week as used by OpenInterval
+func MinuteOfWeek(t time.Time) int {
+	return int(t.Weekday())*minutesPerDay + t.Hour()*60 + t.Minute()
+}
+
+// weeklyOpenAt reports whether the weekly operating hours cover t
+func (r *Restaurant) weeklyOpenAt(t time.Time, loc *time.Location) bool {
+	return intervalsCover(r.OpenIntervals, t, loc)
+}
+
+// intervalsCover reports whether any of the weekly intervals covers t in the given timezone
+func intervalsCover(intervals []OpenInterval, t time.Time, loc *time.Location) bool {
+	minute := MinuteOfWeek(t.In(loc))
+	for _, interval := range intervals {
+		if interval.Start <= minute && minute < interval.End {
+			return true
+		}
+	}
+	return false
+}
+
+// nextWeeklyOpening returns the first interval start in the weekly hours after t, or nil if the
+// restaurant has no hours
+func (r *Restaurant) nextWeeklyOpening(t time.Time, loc *time.Location) *time.Time {
+	local := t.In(loc)
+	minute := MinuteOfWeek(local)
+
+	var next *time.Time
+	for _, interval := range r.OpenIntervals {
+		days := (interval.Start/minutesPerDay - int(local.Weekday()) + 7) % 7
+		if days == 0 && interval.Start <= minute {
+			days = 7
+		}
+		clock := interval.Start % minutesPerDay
+		opening := time.Date(local.Year(), local.Month(), local.Day()+days, clock/60, clock%60, 0, 0, loc)
+		if next == nil || opening.Before(*next) {
+			next = &opening
+		}
+	}
+	return next
+}
+
+// OpenAt reports whether the restaurant is open at t. A closure or pause covering t closes it;
+// otherwise special hours covering t replace the weekly hours.
+func (r *Restaurant) OpenAt(t time.Time) bool {
+	loc, err := r.TimeLocation()
+	if err != nil {
+		loc = time.UTC
+	}
+	if r.OpenIntervals == nil && len(r.OperatingHours) > 0 {
+		// Restaurants stored before intervals were computed
+		_ = r.NormalizeHours()
+	}
+
+	special := false
+	specialOpen := false
+	for _, exception := range r.HoursExceptions {
+		if !exception.activeAt(t) {
+			continue
+		}
+		if exception.closes() {
+			return false
+		}
+		special = true
+		for _, opening := range exception.Opens {
+			if !t.Before(opening.Start) && t.Before(opening.End) {
+				specialOpen = true
+			}
+		}
+	}
+	if special {
+		return specialOpen
+	}
+	return r.weeklyOpenAt(t, loc)
+}
+
+// maxOpeningSteps bounds the search for the next opening; each step moves to the next point at
+// which the open status can change
+const maxOpeningSteps = 100
+
+// NextOpening returns the first time after t at which the restaurant opens, or nil if it doesn't
+// open again within its hours and exceptions
+func (r *Restaurant) NextOpening(t time.Time) *time.Time {
+	loc, err := r.TimeLocation()
+	if err != nil {
+		loc = time.UTC
+	}
+
+	candidate := t
+	for step := 0; step < maxOpeningSteps; step++ {
+		var next *time.Time
+		consider := func(at time.Time) {
+			if at.After(candidate) && (next == nil || at.Before(*next)) {
+				next = &at
+			}
+		}
+
+		if opening := r.nextWeeklyOpening(candidate, loc); opening != nil {
+			consider(*opening)
+		}
+		for _, exception := range r.HoursExceptions {
+			consider(exception.StartsAt)
+			consider(exception.EndsAt)
+			for _, opening := range exception.Opens {
+				consider(opening.Start)
+			}
+		}
+
+		if next == nil {
+			return nil
+		}
+		candidate = *next
+		if r.OpenAt(candidate) {
+			return &candidate
+		}
+	}
+	return nil
+}
+
+// SetOpenStatus fills IsOpen and NextOpenAt for the given time. NextOpenAt is only set while the
+// restaurant is closed.
+func (r *Restaurant) SetOpenStatus(now time.Time) {
+	open := r.OpenAt(now)
+	r.IsOpen = &open
+	r.NextOpenAt = nil
+	if !open {
+		r.NextOpenAt = r.NextOpening(now)
+	}
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// HoursExceptionClosed closes the restaurant for a whole date
+	HoursExceptionClosed = "closed"
+	// HoursExceptionSpecialHours replaces the weekly hours of a date
+	HoursExceptionSpecialHours = "special_hours"
+	// HoursExceptionPause closes the restaurant for a while, such as when the kitchen is too busy
+	HoursExceptionPause = "pause"
+
+	dateLayout = "2006-01-02"
+)
+
+// ClockRange is an opening and closing time of day, "HH:MM"
+type ClockRange struct {
+	OpenTime  string `bson:"openTime" json:"openTime"`
+	CloseTime string `bson:"closeTime" json:"closeTime"`
+}
+
+// TimeRange is a span between two instants. End is exclusive.
+type TimeRange struct {
+	Start time.Time `bson:"start" json:"start"`
+	End   time.Time `bson:"end" json:"end"`
+}
+
+// HoursException overrides the weekly hours between StartsAt and EndsAt. Closed and special hours
+// exceptions are set for a Date in the restaurant's timezone; a pause runs from StartsAt (default
+// now) for DurationMinutes or until EndsAt. StartsAt, EndsAt and Opens are computed on write.
+type HoursException struct {
+	ID              primitive.ObjectID `bson:"_id" json:"id"`
+	Type            string             `bson:"type" json:"type"`
+	Date            string             `bson:"date,omitempty" json:"date,omitempty"`
+	Hours           []ClockRange       `bson:"hours,omitempty" json:"hours,omitempty"`
+	DurationMinutes int                `bson:"-" json:"durationMinutes,omitempty"`
+	Reason          string             `bson:"reason,omitempty" json:"reason,omitempty"`
+	StartsAt        time.Time          `bson:"startsAt" json:"startsAt"`
+	EndsAt          time.Time          `bson:"endsAt" json:"endsAt"`
+	Opens           []TimeRange        `bson:"opens,omitempty" json:"opens,omitempty"`
+	CreatedAt       time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// closes reports whether the exception keeps the restaurant closed for its whole window
+func (e *HoursException) closes() bool {
+	return e.Type == HoursExceptionClosed || e.Type == HoursExceptionPause
+}
+
+// activeAt reports whether t falls within the exception's window
+func (e *HoursException) activeAt(t time.Time) bool {
+	return !t.Before(e.StartsAt) && t.Before(e.EndsAt)
+}
+
+// NormalizeException validates an exception against the restaurant's timezone and computes its
+// window. Special hours that close after midnight extend the window into the next day.
+func (r *Restaurant) NormalizeException(exception *HoursException, now time.Time) error {
+	loc, err := r.TimeLocation()
+	if err != nil {
+		return fmt.Errorf("unknown timezone %q", r.Timezone)
+	}
+
+	switch exception.Type {
+	case HoursExceptionClosed, HoursExceptionSpecialHours:
+		date, err := time.ParseInLocation(dateLayout, exception.Date, loc)
+		if err != nil {
+			return fmt.Errorf("date %q must be YYYY-MM-DD", exception.Date)
+		}
+		exception.StartsAt = date
+		exception.EndsAt = time.Date(date.Year(), date.Month(), date.Day()+1, 0, 0, 0, 0, loc)
+		exception.Opens = nil
+
+		if exception.Type == HoursExceptionClosed {
+			exception.Hours = nil
+			break
+		}
+		if len(exception.Hours) == 0 {
+			return errors.New("special hours need at least one entry in hours")
+		}
+		for _, hours := range exception.Hours {
+			open, ok := parseClock(hours.OpenTime, false)
+			if !ok {
+				return fmt.Errorf("openTime %q must be HH:MM", hours.OpenTime)
+			}
+			closing, ok := parseClock(hours.CloseTime, true)
+			if !ok {
+				return fmt.Errorf("closeTime %q must be HH:MM", hours.CloseTime)
+			}
+			if open == closing {
+				return fmt.Errorf("special hours open and close at %s", hours.OpenTime)
+			}
+			if closing < open {
+				closing += minutesPerDay
+			}
+
+			opening := TimeRange{
+				Start: time.Date(date.Year(), date.Month(), date.Day(), open/60, open%60, 0, 0, loc),
+				End:   time.Date(date.Year(), date.Month(), date.Day(), closing/60, closing%60, 0, 0, loc),
+			}
+			exception.Opens = append(exception.Opens, opening)
+			if opening.End.After(exception.EndsAt) {
+				exception.EndsAt = opening.End
+			}
+		}
+
+	case HoursExceptionPause:
+		exception.Date = ""
+		exception.Hours = nil
+		exception.Opens = nil
+		if exception.StartsAt.IsZero() {
+			exception.StartsAt = now
+		}
+		if exception.DurationMinutes > 0 {
+			exception.EndsAt = exception.StartsAt.Add(time.Duration(exception.DurationMinutes) * time.Minute)
+		}
+		if !exception.EndsAt.After(exception.StartsAt) {
+			return errors.New("a pause needs a positive durationMinutes or an endsAt after startsAt")
+		}
+
+	default:
+		return fmt.Errorf("type must be %s, %s or %s", HoursExceptionClosed, HoursExceptionSpecialHours, HoursExceptionPause)
+	}
+
+	if !exception.EndsAt.After(now) {
+		return errors.New("exception is already over")
+	}
+	exception.DurationMinutes = 0
+	return nil
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestRestaurant(t *testing.T, timezone string, hours ...OperatingHours) *Restaurant {
+	t.Helper()
+	restaurant := &Restaurant{Timezone: timezone, OperatingHours: hours}
+	if err := restaurant.NormalizeHours(); err != nil {
+		t.Fatalf("NormalizeHours: %v", err)
+	}
+	return restaurant
+}
+
+func mustParse(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestOpenAt(t *testing.T) {
+	// 2024-03-08 is a Friday
+	lateNight := newTestRestaurant(t, "UTC",
+		OperatingHours{Day: "friday", OpenTime: "18:00", CloseTime: "02:00"},
+		OperatingHours{Day: "Saturday", OpenTime: "20:00", CloseTime: "03:00"},
+		OperatingHours{Day: "Sunday", OpenTime: "22:00", CloseTime: "01:00"},
+	)
+	// New York moves its clocks forward at 02:00 on 2024-03-10 and back at 02:00 on 2024-11-03
+	newYork := newTestRestaurant(t, "America/New_York",
+		OperatingHours{Day: "Sunday", OpenTime: "01:00", CloseTime: "04:00"},
+	)
+
+	tests := []struct {
+		name       string
+		restaurant *Restaurant
+		at         string
+		want       bool
+	}{
+		{"overnight, before midnight", lateNight, "2024-03-08T23:30:00Z", true},
+		{"overnight, after midnight", lateNight, "2024-03-09T01:30:00Z", true},
+		{"overnight, at closing", lateNight, "2024-03-09T02:00:00Z", false},
+		{"between overnight ranges", lateNight, "2024-03-09T12:00:00Z", false},
+		{"Saturday into Sunday, across the end of the week", lateNight, "2024-03-10T02:30:00Z", true},
+		{"Sunday into Monday", lateNight, "2024-03-11T00:30:00Z", true},
+		{"Monday after the Sunday range", lateNight, "2024-03-11T01:00:00Z", false},
+		{"before the clocks go forward", newYork, "2024-03-10T06:30:00Z", true},
+		{"after the clocks go forward", newYork, "2024-03-10T07:30:00Z", true},
+		{"closed after the shorter night", newYork, "2024-03-10T08:00:00Z", false},
+		{"first 01:30 when the clocks go back", newYork, "2024-11-03T05:30:00Z", true},
+		{"second 01:30 when the clocks go back", newYork, "2024-11-03T06:30:00Z", true},
+		{"closed after the longer night", newYork, "2024-11-03T09:00:00Z", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.restaurant.OpenAt(mustParse(t, tt.at)); got != tt.want {
+				t.Errorf("OpenAt(%s) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenAtComputesLegacyIntervals(t *testing.T) {
+	restaurant := &Restaurant{OperatingHours: []OperatingHours{{Day: "Friday", OpenTime: "09:00", CloseTime: "17:00"}}}
+	if !restaurant.OpenAt(mustParse(t, "2024-03-08T12:00:00Z")) {
+		t.Error("restaurant stored without open intervals is closed during its hours")
+	}
+}
+
+func TestNextOpening(t *testing.T) {
+	newYork := newTestRestaurant(t, "America/New_York",
+		OperatingHours{Day: "Sunday", OpenTime: "09:00", CloseTime: "17:00"},
+	)
+	wrapping := newTestRestaurant(t, "UTC",
+		OperatingHours{Day: "Saturday", OpenTime: "22:00", CloseTime: "02:00"},
+	)
+
+	tests := []struct {
+		name       string
+		restaurant *Restaurant
+		after      string
+		want       string
+	}{
+		// 09:00 is EST the day before the clocks go forward and EDT on the day
+		{"across the clocks going forward", newYork, "2024-03-09T17:00:00Z", "2024-03-10T13:00:00Z"},
+		{"across the clocks going back", newYork, "2024-11-02T17:00:00Z", "2024-11-03T14:00:00Z"},
+		{"later the same week", wrapping, "2024-03-04T12:00:00Z", "2024-03-09T22:00:00Z"},
+		{"from the wrapped end of the week", wrapping, "2024-03-10T03:00:00Z", "2024-03-16T22:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.restaurant.NextOpening(mustParse(t, tt.after))
+			if got == nil || !got.Equal(mustParse(t, tt.want)) {
+				t.Errorf("NextOpening(%s) = %v, want %s", tt.after, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Inventory tracks how many of an item are left. Items without one are never sold out.
+//
+// Stock goes down when an order is accepted. When it reaches zero the item is made unavailable
+// and SoldOut is set, so a restock only brings back items that ran out, not items the owner
+// switched off. With DailyStock set, stock is reset to it every day at ResetTime ("HH:MM",
+// default "00:00") in the restaurant's timezone.
+type Inventory struct {
+	Stock       int        `bson:"stock" json:"stock"`
+	DailyStock  *int       `bson:"dailyStock,omitempty" json:"dailyStock,omitempty"`
+	ResetTime   string     `bson:"resetTime,omitempty" json:"resetTime,omitempty"`
+	NextResetAt *time.Time `bson:"nextResetAt,omitempty" json:"nextResetAt,omitempty"`
+	SoldOut     bool       `bson:"soldOut" json:"soldOut"`
+}
+
+// StockAdjustment changes the stock of one item, either to an absolute Stock or by Delta
+type StockAdjustment struct {
+	ItemID primitive.ObjectID `json:"itemId" binding:"required"`
+	Stock  *int               `json:"stock"`
+	Delta  *int               `json:"delta"`
+}
+
+// Normalize validates the inventory and schedules its next daily reset in the given timezone
+func (inv *Inventory) Normalize(now time.Time, loc *time.Location) error {
+	if inv.Stock < 0 {
+		return errors.New("stock cannot be negative")
+	}
+	if inv.DailyStock == nil {
+		inv.ResetTime = ""
+		inv.NextResetAt = nil
+		return nil
+	}
+	if *inv.DailyStock < 0 {
+		return errors.New("dailyStock cannot be negative")
+	}
+	if inv.ResetTime == "" {
+		inv.ResetTime = "00:00"
+	}
+	if _, ok := parseClock(inv.ResetTime, false); !ok {
+		return fmt.Errorf("resetTime %q must be HH:MM", inv.ResetTime)
+	}
+
+	next := inv.NextReset(now, loc)
+	inv.NextResetAt = &next
+	return nil
+}
+
+// NextReset returns the first daily reset after t. Days on which ResetTime doesn't exist, because
+// of a daylight saving change, reset at the time it normalizes to.
+func (inv *Inventory) NextReset(t time.Time, loc *time.Location) time.Time {
+	clock, _ := parseClock(inv.ResetTime, false)
+	local := t.In(loc)
+	next := time.Date(local.Year(), local.Month(), local.Day(), clock/60, clock%60, 0, 0, loc)
+	if !next.After(t) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+1, clock/60, clock%60, 0, 0, loc)
+	}
+	return next
+}
+
+// ApplyStockStatus sets the item's status from its stock: out of stock makes it unavailable and
+// sold out, and stock on a sold out item makes it available again
+func (item *Item) ApplyStockStatus() {
+	if item.Inventory == nil {
+		return
+	}
+	if item.Inventory.Stock <= 0 {
+		if item.Status == ItemStatusAvailable {
+			item.Inventory.SoldOut = true
+		}
+		item.Status = ItemStatusUnavailable
+		return
+	}
+	if item.Inventory.SoldOut {
+		item.Status = ItemStatusAvailable
+		item.Inventory.SoldOut = false
+	}
+}
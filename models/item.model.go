@@ -6,14 +6,57 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	ItemStatusAvailable   = "available"
+	ItemStatusUnavailable = "unavailable"
+)
+
 type Item struct {
-	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	RestaurantID primitive.ObjectID `bson:"restaurantId" json:"restaurantId" validate:"required"`
-	Name         string             `bson:"name" json:"name" validate:"required,min=2,max=100"`
-	Description  string             `bson:"description" json:"description" validate:"required,min=10,max=500"`
-	Price        float64            `bson:"price" json:"price" validate:"required,gt=0"`
-	ImageURL     string             `bson:"imageUrl" json:"imageUrl" validate:"required,url"`
-	Status       string             `bson:"status" json:"status" validate:"required,oneof=available unavailable"`
-	CreatedAt    time.Time          `bson:"createdAt" json:"createdAt"`
-	UpdatedAt    time.Time          `bson:"updatedAt" json:"updatedAt"`
+	ID           primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	RestaurantID primitive.ObjectID  `bson:"restaurantId" json:"restaurantId" validate:"required"`
+	CategoryID   *primitive.ObjectID `bson:"categoryId,omitempty" json:"categoryId,omitempty"`
+	Position     int                 `bson:"position" json:"position"`
+	Name         string              `bson:"name" json:"name" validate:"required,min=2,max=100"`
+	Description  string              `bson:"description" json:"description" validate:"required,min=10,max=500"`
+	Price        float64             `bson:"price" json:"price" validate:"required,gt=0"`
+	ImageURL     string              `bson:"imageUrl" json:"imageUrl" validate:"required,url"`
+	Status       string              `bson:"status" json:"status" validate:"required,oneof=available unavailable"`
+	CreatedAt    time.Time           `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time           `bson:"updatedAt" json:"updatedAt"`
+
+	// ModifierGroups are the choices made when ordering the item, such as size or toppings
+	ModifierGroups []ModifierGroup `bson:"modifierGroups,omitempty" json:"modifierGroups,omitempty"`
+
+	// Inventory is set for items with limited stock
+	Inventory *Inventory `bson:"inventory,omitempty" json:"inventory,omitempty"`
+}
+
+// ModifierOption is one choice in a modifier group. PriceDelta is added to the item's price for
+// each unit ordered with the option and may be negative.
+type ModifierOption struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	Name       string             `bson:"name" json:"name" validate:"required"`
+	PriceDelta float64            `bson:"priceDelta" json:"priceDelta"`
+	Status     string             `bson:"status" json:"status" validate:"oneof=available unavailable"`
+}
+
+// ModifierGroup is a set of options the customer picks between MinSelections and MaxSelections
+// of, such as "choose 1 size" (1 and 1) or "add up to 3 toppings" (0 and 3). Each option can be
+// picked once.
+type ModifierGroup struct {
+	ID            primitive.ObjectID `bson:"_id" json:"id"`
+	Name          string             `bson:"name" json:"name" validate:"required"`
+	MinSelections int                `bson:"minSelections" json:"minSelections" validate:"min=0"`
+	MaxSelections int                `bson:"maxSelections" json:"maxSelections" validate:"gtefield=MinSelections"`
+	Options       []ModifierOption   `bson:"options" json:"options" validate:"required,min=1"`
+}
+
+// SelectedModifier is an option picked for a cart or order line. Only the IDs are taken from the
+// customer; the names and price delta are copied from the item when the line is priced.
+type SelectedModifier struct {
+	GroupID    primitive.ObjectID `bson:"groupId" json:"groupId"`
+	OptionID   primitive.ObjectID `bson:"optionId" json:"optionId"`
+	GroupName  string             `bson:"groupName" json:"groupName"`
+	OptionName string             `bson:"optionName" json:"optionName"`
+	PriceDelta float64            `bson:"priceDelta" json:"priceDelta"`
 }
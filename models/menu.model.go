@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Menu is a named set of categories and items offered during a weekly schedule, such as
+// "Breakfast" or "Late night". The schedule uses the same format as OperatingHours, in the
+// restaurant's timezone.
+//
+// Items in no menu, directly or through their category, can be ordered whenever the restaurant
+// is open. Items in at least one menu can only be ordered while one of their menus is on.
+type Menu struct {
+	ID            primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	RestaurantID  primitive.ObjectID   `bson:"restaurantId" json:"restaurantId"`
+	Name          string               `bson:"name" json:"name" validate:"required,min=2,max=100"`
+	Description   string               `bson:"description" json:"description"`
+	Schedule      []OperatingHours     `bson:"schedule" json:"schedule" validate:"required,min=1"`
+	OpenIntervals []OpenInterval       `bson:"openIntervals" json:"-"`
+	CategoryIDs   []primitive.ObjectID `bson:"categoryIds" json:"categoryIds"`
+	ItemIDs       []primitive.ObjectID `bson:"itemIds" json:"itemIds"`
+	CreatedAt     time.Time            `bson:"createdAt" json:"createdAt"`
+	UpdatedAt     time.Time            `bson:"updatedAt" json:"updatedAt"`
+}
+
+// NormalizeSchedule validates the schedule, writes day names in their canonical form and
+// recomputes OpenIntervals
+func (m *Menu) NormalizeSchedule() error {
+	intervals, err := weeklyIntervals(m.Schedule)
+	if err != nil {
+		return err
+	}
+	m.OpenIntervals = intervals
+	return nil
+}
+
+// ActiveAt reports whether the menu's schedule covers t in the restaurant's timezone
+func (m *Menu) ActiveAt(t time.Time, loc *time.Location) bool {
+	return intervalsCover(m.OpenIntervals, t, loc)
+}
+
+// MenuAvailability is the effect of a set of menus at one moment: which items and categories
+// are on a menu at all, and which are on a menu that is on
+type MenuAvailability struct {
+	ScheduledItems      []primitive.ObjectID
+	ScheduledCategories []primitive.ObjectID
+	ActiveItems         []primitive.ObjectID
+	ActiveCategories    []primitive.ObjectID
+}
+
+// NewMenuAvailability works out which items the menus allow at t. location returns the timezone
+// of each menu's restaurant.
+func NewMenuAvailability(menus []Menu, t time.Time, location func(restaurantID primitive.ObjectID) *time.Location) *MenuAvailability {
+	availability := &MenuAvailability{
+		ScheduledItems:      []primitive.ObjectID{},
+		ScheduledCategories: []primitive.ObjectID{},
+		ActiveItems:         []primitive.ObjectID{},
+		ActiveCategories:    []primitive.ObjectID{},
+	}
+	for _, menu := range menus {
+		availability.ScheduledItems = append(availability.ScheduledItems, menu.ItemIDs...)
+		availability.ScheduledCategories = append(availability.ScheduledCategories, menu.CategoryIDs...)
+		if menu.ActiveAt(t, location(menu.RestaurantID)) {
+			availability.ActiveItems = append(availability.ActiveItems, menu.ItemIDs...)
+			availability.ActiveCategories = append(availability.ActiveCategories, menu.CategoryIDs...)
+		}
+	}
+	return availability
+}
+
+// Allows reports whether the item can be ordered under these menus
+func (a *MenuAvailability) Allows(item *Item) bool {
+	if containsID(a.ActiveItems, item.ID) || (item.CategoryID != nil && containsID(a.ActiveCategories, *item.CategoryID)) {
+		return true
+	}
+	scheduled := containsID(a.ScheduledItems, item.ID) || (item.CategoryID != nil && containsID(a.ScheduledCategories, *item.CategoryID))
+	return !scheduled
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MenuCategory is a section of a restaurant's menu, such as "Starters" or "Drinks". Categories
+// are shown in ascending Position.
+type MenuCategory struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	RestaurantID primitive.ObjectID `bson:"restaurantId" json:"restaurantId"`
+	Name         string             `bson:"name" json:"name" validate:"required,min=2,max=100"`
+	Description  string             `bson:"description" json:"description"`
+	Position     int                `bson:"position" json:"position"`
+	CreatedAt    time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// MenuSection is a category together with its items in display order
+type MenuSection struct {
+	MenuCategory `bson:",inline"`
+	Items        []Item `bson:"items" json:"items"`
+}
+
+// RestaurantMenu is a restaurant's full structured menu. Items without a category are listed separately.
+type RestaurantMenu struct {
+	RestaurantID  primitive.ObjectID `json:"restaurantId"`
+	Categories    []MenuSection      `json:"categories"`
+	Uncategorized []Item             `json:"uncategorized"`
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	OrderStatusPlaced    = "placed"
+	OrderStatusAccepted  = "accepted"
+	OrderStatusPreparing = "preparing"
+	OrderStatusReady     = "ready"
+	OrderStatusPickedUp  = "picked_up"
+	OrderStatusDelivered = "delivered"
+	OrderStatusCancelled = "cancelled"
+	OrderStatusRejected  = "rejected"
+)
+
+// orderStatusTransitions lists, for every status, the statuses an order may move to next.
+// Delivered, cancelled and rejected are terminal.
+var orderStatusTransitions = map[string][]string{
+	OrderStatusPlaced:    {OrderStatusAccepted, OrderStatusRejected, OrderStatusCancelled},
+	OrderStatusAccepted:  {OrderStatusPreparing, OrderStatusCancelled},
+	OrderStatusPreparing: {OrderStatusReady, OrderStatusCancelled},
+	OrderStatusReady:     {OrderStatusPickedUp},
+	OrderStatusPickedUp:  {OrderStatusDelivered},
+	OrderStatusDelivered: {},
+	OrderStatusCancelled: {},
+	OrderStatusRejected:  {},
+}
+
+// orderStatusRoles lists which roles may move an order into each status
+var orderStatusRoles = map[string][]string{
+	OrderStatusAccepted:  {RoleRestaurantOwner},
+	OrderStatusPreparing: {RoleRestaurantOwner},
+	OrderStatusReady:     {RoleRestaurantOwner},
+	OrderStatusRejected:  {RoleRestaurantOwner},
+	OrderStatusPickedUp:  {RoleCourier},
+	OrderStatusDelivered: {RoleCourier},
+	OrderStatusCancelled: {RoleRestaurantOwner, RoleCustomer},
+}
+
+// OrderItem is a snapshot of an item at the time the order was placed,
+// so later menu changes don't alter what the customer paid for.
+// Price is the item's base price and UnitPrice includes the modifiers.
+type OrderItem struct {
+	ItemID    primitive.ObjectID `bson:"itemId" json:"itemId" validate:"required"`
+	Name      string             `bson:"name" json:"name"`
+	Price     float64            `bson:"price" json:"price"`
+	Modifiers []SelectedModifier `bson:"modifiers,omitempty" json:"modifiers,omitempty"`
+	UnitPrice float64            `bson:"unitPrice" json:"unitPrice"`
+	Quantity  int                `bson:"quantity" json:"quantity" validate:"required,min=1"`
+	LineTotal float64            `bson:"lineTotal" json:"lineTotal"`
+}
+
+type OrderStatusChange struct {
+	Status    string    `bson:"status" json:"status"`
+	ChangedAt time.Time `bson:"changedAt" json:"changedAt"`
+}
+
+type Order struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	CustomerID    primitive.ObjectID  `bson:"customerId" json:"customerId"`
+	RestaurantID  primitive.ObjectID  `bson:"restaurantId" json:"restaurantId" validate:"required"`
+	Items         []OrderItem         `bson:"items" json:"items" validate:"required,min=1,dive"`
+	Pricing       PriceBreakdown      `bson:"pricing" json:"pricing"`
+	Status        string              `bson:"status" json:"status"`
+	StatusHistory []OrderStatusChange `bson:"statusHistory" json:"statusHistory"`
+	CourierID     *primitive.ObjectID `bson:"courierId,omitempty" json:"courierId,omitempty"`
+	CreatedAt     time.Time           `bson:"createdAt" json:"createdAt"`
+	UpdatedAt     time.Time           `bson:"updatedAt" json:"updatedAt"`
+}
+
+// IsValidOrderStatus reports whether status is one of the known order statuses
+func IsValidOrderStatus(status string) bool {
+	_, ok := orderStatusTransitions[status]
+	return ok
+}
+
+// CanTransitionTo reports whether the order may move from its current status to status
+func (o *Order) CanTransitionTo(status string) bool {
+	for _, next := range orderStatusTransitions[o.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// HasReservedStock reports whether the order holds stock that cancelling it must put back. Its items
+// are taken from stock when the restaurant accepts it, and it can be cancelled until it is ready.
+func (o *Order) HasReservedStock() bool {
+	return o.Status == OrderStatusAccepted || o.Status == OrderStatusPreparing
+}
+
+// VisibleToCourier reports whether the courier may see and deliver the order. An order waiting for
+// pickup is open to every courier; once picked up it belongs to the courier who took it.
+func (o *Order) VisibleToCourier(courierID primitive.ObjectID) bool {
+	if o.CourierID == nil {
+		return o.Status == OrderStatusReady
+	}
+	return *o.CourierID == courierID
+}
+
+// CanBeMovedBy reports whether a user with role may move the order to status. Customers can only
+// cancel orders the restaurant hasn't accepted yet.
+func (o *Order) CanBeMovedBy(role, status string) bool {
+	if role == RoleCustomer && o.Status != OrderStatusPlaced {
+		return false
+	}
+	for _, allowed := range orderStatusRoles[status] {
+		if allowed == role {
+			return true
+		}
+	}
+	return false
+}
@@ -1,10 +1,36 @@
 package models
 
+// Page is one page of a listing: its rows and where it sits in the listing
+type Page[T any] struct {
+	Data []T `json:"data"`
+	Pagination
+}
+
+// NewPage puts rows on a page, never leaving Data nil so it is listed as []
+func NewPage[T any](data []T, pagination Pagination) *Page[T] {
+	if data == nil {
+		data = []T{}
+	}
+	return &Page[T]{Data: data, Pagination: pagination}
+}
+
+// WholePage puts a listing that is never paginated, being bounded per restaurant, on a single page
+// holding all of it, so it is listed in the same envelope as paginated ones
+func WholePage[T any](data []T) *Page[T] {
+	pagination := Pagination{Page: 1, PageSize: int64(len(data))}
+	pagination.SetTotal(int64(len(data)))
+	return NewPage(data, pagination)
+}
+
 type Pagination struct {
-	Data     []interface{} `json:"data,omitempty"`
-	Page     int64         `json:"page" validate:"required,min=1"`
-	PageSize int64         `json:"pageSize" validate:"required,min=1,max=100"`
-	Total    int64         `json:"total,omitempty"`
+	Page       int64  `json:"page,omitempty" validate:"required,min=1"`
+	PageSize   int64  `json:"pageSize" validate:"required,min=1,max=100"`
+	Total      *int64 `json:"total,omitempty"`
+	TotalPages *int64 `json:"totalPages,omitempty"`
+
+	// Cursors of the next and previous pages, set while there are rows in that direction
+	NextCursor string `json:"nextCursor,omitempty"`
+	PrevCursor string `json:"prevCursor,omitempty"`
 }
 
 // NewPagination creates a new Pagination with default values
@@ -39,18 +65,20 @@ func (p *Pagination) GetLimit() int64 {
 	return p.PageSize
 }
 
-// SetTotal sets the total number of documents
+// SetTotal sets the total number of documents and the number of pages it makes
 func (p *Pagination) SetTotal(total int64) {
-	p.Total = total
+	p.Total = &total
+	totalPages := p.GetTotalPages()
+	p.TotalPages = &totalPages
 }
 
 // GetTotalPages returns the total number of pages
 func (p *Pagination) GetTotalPages() int64 {
-	if p.Total == 0 {
+	if p.Total == nil || *p.Total == 0 {
 		return 0
 	}
-	totalPages := p.Total / p.PageSize
-	if p.Total%p.PageSize > 0 {
+	totalPages := *p.Total / p.PageSize
+	if *p.Total%p.PageSize > 0 {
 		totalPages++
 	}
 	return totalPages
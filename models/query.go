@@ -3,15 +3,34 @@ package models
 type PaginationOptions struct {
 	Page     int64
 	PageSize int64
+
+	// Cursor, when set, is used instead of Page to find where the page starts
+	Cursor *Cursor
+	// WithTotal counts every matching document, which costs another query
+	WithTotal bool
 }
 
-type SortOptions struct {
+// SortKey is one key of a sort
+type SortKey struct {
 	Field string
+	Type  string
 	Order int // 1 for ascending, -1 for descending
 }
 
+// SortOptions is an ordered list of sort keys; later keys break ties between earlier ones
+type SortOptions struct {
+	Keys []SortKey
+}
+
 type QueryOptions struct {
 	Pagination *PaginationOptions
 	Sort       *SortOptions
 	Filter     map[string]interface{}
 }
+
+// NearbyQuery is a point to search around and how far from it to look, in meters
+type NearbyQuery struct {
+	Latitude     float64
+	Longitude    float64
+	RadiusMeters float64
+}
@@ -1,11 +1,15 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// OperatingHours is one opening interval of the weekly schedule, in the restaurant's timezone.
+// Times are "HH:MM"; a closing time at or before the opening time means the restaurant closes
+// after midnight.
 type OperatingHours struct {
 	Day       string `bson:"day" json:"day"`
 	OpenTime  string `bson:"openTime" json:"openTime"`
@@ -13,23 +17,69 @@ type OperatingHours struct {
 }
 
 type Restaurant struct {
-	ID primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	// OwnerID     primitive.ObjectID `bson:"ownerId" json:"ownerId" validate:"required"`
-	Name        string `bson:"name" json:"name" validate:"required,min=2,max=100"`
-	Description string `bson:"description" json:"description"`
-	Address     string `bson:"address" json:"address" validate:"required"`
-	ImageURL    string `bson:"imageUrl" json:"imageUrl"`
-	// Normally I would do something like this
-	// Rating      float64            `bson:"rating" json:"rating" validate:"min=0,max=5"`
-	// RatingCount int                `bson:"ratingCount" json:"ratingCount"`
-	Location struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	OwnerID     primitive.ObjectID `bson:"ownerId" json:"ownerId"`
+	Name        string             `bson:"name" json:"name" validate:"required,min=2,max=100"`
+	Description string             `bson:"description" json:"description"`
+	Address     string             `bson:"address" json:"address" validate:"required"`
+	ImageURL    string             `bson:"imageUrl" json:"imageUrl"`
+	Location    struct {
 		Type        string    `bson:"type" json:"type"`
 		Coordinates []float64 `bson:"coordinates" json:"coordinates"`
 	} `bson:"location" json:"location"`
+	Timezone       string           `bson:"timezone" json:"timezone"`
 	OperatingHours []OperatingHours `bson:"operatingHours" json:"operatingHours"`
+	OpenIntervals  []OpenInterval   `bson:"openIntervals" json:"-"`
 	CreatedAt      time.Time        `bson:"createdAt" json:"createdAt"`
 	UpdatedAt      time.Time        `bson:"updatedAt" json:"updatedAt"`
 
-	AverageRating float64 `bson:"averageRating,omitempty" json:"averageRating,omitempty"`
-	Items         []Item  `bson:"items,omitempty" json:"items,omitempty"`
+	// Date-specific overrides of the weekly hours, managed through the hours exceptions endpoints
+	HoursExceptions []HoursException `bson:"hoursExceptions,omitempty" json:"hoursExceptions,omitempty"`
+
+	// Open status at the time of the request, computed from the operating hours and exceptions
+	IsOpen     *bool      `bson:"-" json:"isOpen,omitempty"`
+	NextOpenAt *time.Time `bson:"-" json:"nextOpenAt,omitempty"`
+
+	// Rating aggregates are maintained by the review repository on every review write
+	Rating          float64          `bson:"rating" json:"rating"`
+	RatingCount     int64            `bson:"ratingCount" json:"ratingCount"`
+	RatingTotal     int64            `bson:"ratingTotal" json:"-"`
+	RatingHistogram map[string]int64 `bson:"ratingHistogram" json:"ratingHistogram"`
+
+	Items []Item `bson:"items,omitempty" json:"items,omitempty"`
+
+	// Distance from the searched point in meters, only set by nearby searches
+	Distance *float64 `bson:"distance,omitempty" json:"distance,omitempty"`
+}
+
+// AverageRating is the mean star rating of the restaurant's reviews, or 0 without reviews
+func (r *Restaurant) AverageRating() float64 {
+	if r.RatingCount == 0 {
+		return 0
+	}
+	return float64(r.RatingTotal) / float64(r.RatingCount)
+}
+
+// MarshalJSON adds averageRating, which API clients read before the rating aggregates were stored
+func (r Restaurant) MarshalJSON() ([]byte, error) {
+	type restaurant Restaurant
+	return json.Marshal(struct {
+		restaurant
+		AverageRating float64 `json:"averageRating,omitempty"`
+	}{restaurant(r), r.AverageRating()})
+}
+
+// RatingStats are a restaurant's rating aggregates as computed from its reviews.
+// The histogram is keyed by star rating, "1" to "5".
+type RatingStats struct {
+	RestaurantID primitive.ObjectID `bson:"_id" json:"restaurantId"`
+	Average      float64            `bson:"averageRating" json:"averageRating"`
+	Count        int64              `bson:"count" json:"count"`
+	Total        int64              `bson:"total" json:"total"`
+	Histogram    map[string]int64   `bson:"histogram" json:"histogram"`
+}
+
+// EmptyRatingHistogram returns a histogram with every star rating at zero
+func EmptyRatingHistogram() map[string]int64 {
+	return map[string]int64{"1": 0, "2": 0, "3": 0, "4": 0, "5": 0}
 }
@@ -8,11 +8,11 @@ import (
 
 type Review struct {
 	ID                 primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	// UserID             primitive.ObjectID `bson:"userId" json:"userId" validate:"required"`
-	RestaurantID       primitive.ObjectID `bson:"restaurantId" json:"restaurantId" validate:"required"`
-	OrderID            primitive.ObjectID `bson:"orderId" json:"orderId" validate:"required"`
-	Rating             int                `bson:"rating" json:"rating" validate:"required,min=1,max=5"`
-	Comment            string             `bson:"comment" json:"comment" validate:"required,min=10,max=1000"`
+	UserID             primitive.ObjectID `bson:"userId" json:"userId"`
+	RestaurantID       primitive.ObjectID `bson:"restaurantId" json:"restaurantId" binding:"required"`
+	OrderID            primitive.ObjectID `bson:"orderId" json:"orderId" binding:"required"`
+	Rating             int                `bson:"rating" json:"rating" binding:"required,min=1,max=5"`
+	Comment            string             `bson:"comment" json:"comment" binding:"required,min=10,max=1000"`
 	RestaurantResponse struct {
 		Comment   string    `bson:"comment" json:"comment"`
 		CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
@@ -0,0 +1,170 @@
+package models
+
+import (
+	"html"
+	"math"
+	"strings"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// earthRadiusMeters is the mean radius used for $centerSphere and distances
+const earthRadiusMeters = 6371008.8
+
+// SearchQuery is a full-text search, optionally limited to restaurants near a point
+type SearchQuery struct {
+	Text  string
+	Near  *NearbyQuery
+	Limit int
+}
+
+// ScoredRestaurant is a restaurant matched by a text search with its relevance score
+type ScoredRestaurant struct {
+	Restaurant `bson:",inline"`
+	Score      float64 `bson:"score"`
+}
+
+// ScoredItem is an item matched by a text search with its relevance score
+type ScoredItem struct {
+	Item  `bson:",inline"`
+	Score float64 `bson:"score"`
+}
+
+// SearchResult is one restaurant in the search results with its matching items. Score combines
+// the restaurant's own relevance, that of its best item and, for searches near a point, distance.
+// Highlights hold the matching fields with the matched words wrapped in <em> tags.
+type SearchResult struct {
+	Restaurant Restaurant        `json:"restaurant"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights,omitempty"`
+	Items      []ItemMatch       `json:"items"`
+}
+
+// ItemMatch is an item found by a search
+type ItemMatch struct {
+	Item       Item              `json:"item"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights,omitempty"`
+}
+
+const (
+	SuggestionRestaurant = "restaurant"
+	SuggestionItem       = "item"
+)
+
+// Suggestion is a name to complete a search with. Restaurants carry their ID; dishes are
+// suggested by name with the number of items that have it.
+type Suggestion struct {
+	Type  string              `json:"type"`
+	Text  string              `json:"text"`
+	ID    *primitive.ObjectID `json:"id,omitempty"`
+	Count int                 `json:"count,omitempty"`
+}
+
+// RadiusFilter matches GeoJSON points within the query's radius. Unlike $near it can be combined
+// with $text.
+func (q NearbyQuery) RadiusFilter() map[string]interface{} {
+	return map[string]interface{}{
+		"$geoWithin": map[string]interface{}{
+			"$centerSphere": []interface{}{
+				[]float64{q.Longitude, q.Latitude},
+				q.RadiusMeters / earthRadiusMeters,
+			},
+		},
+	}
+}
+
+// DistanceMeters returns the great-circle distance from the query point to a [lng, lat] position
+func (q NearbyQuery) DistanceMeters(coordinates []float64) float64 {
+	if len(coordinates) != 2 {
+		return math.Inf(1)
+	}
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	lat1, lat2 := toRadians(q.Latitude), toRadians(coordinates[1])
+	dLat := lat2 - lat1
+	dLng := toRadians(coordinates[0] - q.Longitude)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(h))
+}
+
+// SearchTerms splits a search into lowercase terms. Negated terms are dropped since they never
+// appear in results.
+func SearchTerms(text string) []string {
+	var terms []string
+	for _, field := range strings.Fields(text) {
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+		terms = append(terms, splitWords(strings.ToLower(field))...)
+	}
+	return terms
+}
+
+// Highlight wraps the words of text that match a search term in <em> tags. A word matches when
+// it starts with the term, or the term starts with it, which roughly follows the stemming of the
+// text index ("burger" matches "burgers"). The rest of the text is HTML-escaped, since clients
+// render the result as HTML and the text is written by restaurant owners. It reports whether
+// anything matched.
+func Highlight(text string, terms []string) (string, bool) {
+	var b strings.Builder
+	matched := false
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		j := i
+		if !isWordRune(runes[i]) {
+			for j < len(runes) && !isWordRune(runes[j]) {
+				j++
+			}
+			b.WriteString(html.EscapeString(string(runes[i:j])))
+			i = j
+			continue
+		}
+
+		for j < len(runes) && isWordRune(runes[j]) {
+			j++
+		}
+		word := html.EscapeString(string(runes[i:j]))
+		if matchesTerm(strings.ToLower(string(runes[i:j])), terms) {
+			b.WriteString("<em>" + word + "</em>")
+			matched = true
+		} else {
+			b.WriteString(word)
+		}
+		i = j
+	}
+	return b.String(), matched
+}
+
+// TermMatches counts the words of text that match a search term, the words Highlight would mark
+func TermMatches(text string, terms []string) int {
+	count := 0
+	for _, word := range splitWords(strings.ToLower(text)) {
+		if matchesTerm(word, terms) {
+			count++
+		}
+	}
+	return count
+}
+
+func matchesTerm(word string, terms []string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+		if len([]rune(word)) >= 3 && strings.HasPrefix(term, word) && len(term)-len(word) <= 3 {
+			return true
+		}
+	}
+	return false
+}
+
+func splitWords(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool { return !isWordRune(r) })
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
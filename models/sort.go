@@ -0,0 +1,110 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxSortKeys bounds how many keys a client can sort on
+const maxSortKeys = 3
+
+// SortField is a field a list endpoint can be sorted on: its stored name and its value type, one
+// of the filter value types. Cursors are checked against the type.
+type SortField struct {
+	Field string
+	Type  string
+}
+
+// SortSchema lists the fields of a resource that can be sorted on, by public name
+type SortSchema map[string]SortField
+
+// idSortKey ends every sort
+var idSortKey = SortKey{Field: "_id", Type: FilterObjectID, Order: 1}
+
+// ItemSorts are the sort keys of the item listing
+var ItemSorts = SortSchema{
+	"name":      {Field: "name", Type: FilterString},
+	"price":     {Field: "price", Type: FilterNumber},
+	"position":  {Field: "position", Type: FilterNumber},
+	"createdAt": {Field: "createdAt", Type: FilterTime},
+}
+
+// RestaurantSorts are the sort keys of the restaurant listings. distance only applies to
+// searches around a point.
+var RestaurantSorts = SortSchema{
+	"rating":      {Field: "rating", Type: FilterNumber},
+	"ratingCount": {Field: "ratingCount", Type: FilterNumber},
+	"distance":    {Field: "distance", Type: FilterNumber},
+	"name":        {Field: "name", Type: FilterString},
+	"createdAt":   {Field: "createdAt", Type: FilterTime},
+}
+
+// ReviewSorts are the sort keys of a restaurant's reviews
+var ReviewSorts = SortSchema{
+	"rating":    {Field: "rating", Type: FilterNumber},
+	"createdAt": {Field: "createdAt", Type: FilterTime},
+}
+
+// OrderSorts are the sort keys of a restaurant's orders
+var OrderSorts = SortSchema{
+	"createdAt": {Field: "createdAt", Type: FilterTime},
+}
+
+// ParseSort parses a sort expression such as "rating:desc,name" into sort options. Keys are
+// separated by commas and sort ascending unless followed by ":desc". Only fields in schema are
+// accepted. The result always ends with _id so that documents with equal keys keep the same
+// order from one page to the next.
+func ParseSort(expression string, schema SortSchema) (*SortOptions, error) {
+	options := &SortOptions{}
+	seen := make(map[string]bool)
+
+	if strings.TrimSpace(expression) != "" {
+		keys := strings.Split(expression, ",")
+		if len(keys) > maxSortKeys {
+			return nil, fmt.Errorf("sort takes at most %d keys", maxSortKeys)
+		}
+		for _, key := range keys {
+			name, direction, _ := strings.Cut(strings.TrimSpace(key), ":")
+			field, ok := schema[name]
+			if !ok {
+				return nil, fmt.Errorf("cannot sort on %q; allowed fields are %s", name, strings.Join(sortedNames(schema), ", "))
+			}
+			if seen[field.Field] {
+				return nil, fmt.Errorf("%s is sorted on more than once", name)
+			}
+			seen[field.Field] = true
+
+			order, err := sortOrder(direction)
+			if err != nil {
+				return nil, err
+			}
+			options.Keys = append(options.Keys, SortKey{Field: field.Field, Type: field.Type, Order: order})
+		}
+	}
+
+	options.Keys = append(options.Keys, idSortKey)
+	return options, nil
+}
+
+func sortOrder(direction string) (int, error) {
+	switch direction {
+	case "", "asc":
+		return 1, nil
+	case "desc":
+		return -1, nil
+	}
+	return 0, fmt.Errorf("sort direction must be asc or desc, got %q", direction)
+}
+
+// Has reports whether the options sort on field
+func (s *SortOptions) Has(field string) bool {
+	if s == nil {
+		return false
+	}
+	for _, key := range s.Keys {
+		if key.Field == field {
+			return true
+		}
+	}
+	return false
+}
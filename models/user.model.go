@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	RoleCustomer        = "customer"
+	RoleRestaurantOwner = "restaurant_owner"
+	RoleCourier         = "courier"
+	RoleAdmin           = "admin"
+)
+
+type User struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name         string             `bson:"name" json:"name" validate:"required,min=2,max=100"`
+	Email        string             `bson:"email" json:"email" validate:"required,email"`
+	PasswordHash string             `bson:"passwordHash" json:"-"`
+	Role         string             `bson:"role" json:"role" validate:"required,oneof=customer restaurant_owner courier admin"`
+	CreatedAt    time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// AuthUser is the caller attached to a request once its token has been verified
+type AuthUser struct {
+	ID   primitive.ObjectID `json:"id"`
+	Role string             `json:"role"`
+}
+
+// IsAdmin reports whether the caller is an administrator
+func (u *AuthUser) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
@@ -0,0 +1,216 @@
+package policies
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/aldiandyaIrsyad/uber-eats/services"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxPeekedBodySize bounds the request bodies rules read, which are small JSON objects
+const maxPeekedBodySize = 1 << 20
+
+var (
+	errInvalidBody  = services.Validation("malformed_json", "the request body is not valid JSON")
+	errBodyTooLarge = services.Validation("body_too_large", "the request body is too large")
+)
+
+// invalidID reports a path parameter or body field that should hold an ObjectID
+func invalidID(name string) error {
+	return services.InvalidField(name, "invalid_id", name+" must be a 24-character hex ID")
+}
+
+// RestaurantOwner passes when the caller owns the restaurant named by the path parameter
+func (a *Authorizer) RestaurantOwner(param string) Rule {
+	return Rule{
+		Description: "ownership of the restaurant",
+		Allow: func(ctx *gin.Context, user *models.AuthUser) (bool, error) {
+			restaurantID, err := primitive.ObjectIDFromHex(ctx.Param(param))
+			if err != nil {
+				return false, invalidID(param)
+			}
+			return a.ownsRestaurant(ctx.Request.Context(), restaurantID, user)
+		},
+	}
+}
+
+// RestaurantOwnerOfBody passes when the caller owns the restaurant referenced by field in the JSON body.
+// The body is restored afterwards so the controller can still bind it.
+func (a *Authorizer) RestaurantOwnerOfBody(field string) Rule {
+	return Rule{
+		Description: "ownership of the restaurant",
+		Allow: func(ctx *gin.Context, user *models.AuthUser) (bool, error) {
+			hex, err := peekBodyField(ctx, field)
+			if err != nil {
+				return false, err
+			}
+			restaurantID, err := primitive.ObjectIDFromHex(hex)
+			if err != nil {
+				return false, invalidID(field)
+			}
+			return a.ownsRestaurant(ctx.Request.Context(), restaurantID, user)
+		},
+	}
+}
+
+// ItemOwner passes when the caller owns the restaurant that owns the item named by the path parameter
+func (a *Authorizer) ItemOwner(param string) Rule {
+	return Rule{
+		Description: "ownership of the restaurant that owns this item",
+		Allow: func(ctx *gin.Context, user *models.AuthUser) (bool, error) {
+			itemID, err := primitive.ObjectIDFromHex(ctx.Param(param))
+			if err != nil {
+				return false, invalidID(param)
+			}
+			item, err := a.itemRepo.GetItemByID(ctx.Request.Context(), itemID)
+			if err != nil {
+				return false, err
+			}
+			return a.ownsRestaurant(ctx.Request.Context(), item.RestaurantID, user)
+		},
+	}
+}
+
+// OrderCustomer passes when the caller placed the order named by the path parameter
+func (a *Authorizer) OrderCustomer(param string) Rule {
+	return Rule{
+		Description: "being the customer who placed this order",
+		Allow: func(ctx *gin.Context, user *models.AuthUser) (bool, error) {
+			order, err := a.order(ctx, param)
+			if err != nil {
+				return false, err
+			}
+			return order.CustomerID == user.ID, nil
+		},
+	}
+}
+
+// OrderRestaurantOwner passes when the caller owns the restaurant the order was placed at
+func (a *Authorizer) OrderRestaurantOwner(param string) Rule {
+	return Rule{
+		Description: "ownership of the restaurant this order was placed at",
+		Allow: func(ctx *gin.Context, user *models.AuthUser) (bool, error) {
+			order, err := a.order(ctx, param)
+			if err != nil {
+				return false, err
+			}
+			return a.ownsRestaurant(ctx.Request.Context(), order.RestaurantID, user)
+		},
+	}
+}
+
+// OrderCourier passes when the caller is a courier who may pick up or deliver the order named by the
+// path parameter: it is waiting for pickup, or they picked it up
+func (a *Authorizer) OrderCourier(param string) Rule {
+	return Rule{
+		Description: "being a courier who can pick up or deliver this order",
+		Allow: func(ctx *gin.Context, user *models.AuthUser) (bool, error) {
+			if user.Role != models.RoleCourier {
+				return false, nil
+			}
+			order, err := a.order(ctx, param)
+			if err != nil {
+				return false, err
+			}
+			return order.VisibleToCourier(user.ID), nil
+		},
+	}
+}
+
+// OrderStatusChange passes when the caller may move the order named by the path parameter to the
+// status in the request body: owners of its restaurant run the kitchen side, the courier picking it
+// up or delivering it the delivery side, and the customer who placed it may cancel it before it is
+// accepted.
+func (a *Authorizer) OrderStatusChange(param string) Rule {
+	return Rule{
+		Description: "a role and relationship to this order that allows the status change",
+		Allow: func(ctx *gin.Context, user *models.AuthUser) (bool, error) {
+			order, err := a.order(ctx, param)
+			if err != nil {
+				return false, err
+			}
+			status, err := peekBodyField(ctx, "status")
+			if err != nil {
+				return false, err
+			}
+			if !models.IsValidOrderStatus(status) {
+				return false, services.ErrInvalidOrderStatus
+			}
+			if !order.CanBeMovedBy(user.Role, status) {
+				return false, nil
+			}
+
+			switch user.Role {
+			case models.RoleCustomer:
+				return order.CustomerID == user.ID, nil
+			case models.RoleRestaurantOwner:
+				return a.ownsRestaurant(ctx.Request.Context(), order.RestaurantID, user)
+			case models.RoleCourier:
+				return order.VisibleToCourier(user.ID), nil
+			default:
+				return false, nil
+			}
+		},
+	}
+}
+
+// ReviewAuthor passes when the caller wrote the review named by the path parameter
+func (a *Authorizer) ReviewAuthor(param string) Rule {
+	return Rule{
+		Description: "being the author of this review",
+		Allow: func(ctx *gin.Context, user *models.AuthUser) (bool, error) {
+			reviewID, err := primitive.ObjectIDFromHex(ctx.Param(param))
+			if err != nil {
+				return false, invalidID(param)
+			}
+			review, err := a.reviewRepo.GetReviewByID(ctx.Request.Context(), reviewID)
+			if err != nil {
+				return false, err
+			}
+			return review.UserID == user.ID, nil
+		},
+	}
+}
+
+func (a *Authorizer) ownsRestaurant(ctx context.Context, restaurantID primitive.ObjectID, user *models.AuthUser) (bool, error) {
+	ownerID, err := a.restaurantRepo.GetRestaurantOwnerID(ctx, restaurantID)
+	if err != nil {
+		return false, err
+	}
+	return ownerID == user.ID, nil
+}
+
+func (a *Authorizer) order(ctx *gin.Context, param string) (*models.Order, error) {
+	orderID, err := primitive.ObjectIDFromHex(ctx.Param(param))
+	if err != nil {
+		return nil, invalidID(param)
+	}
+	return a.orderRepo.GetOrderByID(ctx.Request.Context(), orderID)
+}
+
+// peekBodyField reads a string field from the JSON body and restores the body for the controller
+func peekBodyField(ctx *gin.Context, field string) (string, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxPeekedBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return "", errBodyTooLarge
+		}
+		return "", err
+	}
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return "", errInvalidBody
+	}
+	value, _ := fields[field].(string)
+	return value, nil
+}
@@ -0,0 +1,84 @@
+package policies
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aldiandyaIrsyad/uber-eats/middleware"
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/aldiandyaIrsyad/uber-eats/repos"
+	"github.com/aldiandyaIrsyad/uber-eats/services"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestRequireAuditsDenials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := repos.NewMemoryStore()
+	r := repos.NewMemoryRepositories(store)
+	authService := services.NewAuthService(r.Users, []byte("test secret"))
+	authorizer := NewAuthorizer(r.Restaurants, r.Items, r.Orders, r.Reviews, r.Audit)
+
+	tokens := make(map[string]string)
+	users := make(map[string]*models.User)
+	for _, role := range []string{models.RoleCustomer, models.RoleRestaurantOwner} {
+		user := &models.User{Name: role, Email: role + "@example.com", Role: role}
+		token, err := authService.Signup(context.Background(), user, "password123")
+		if err != nil {
+			t.Fatalf("Signup: %v", err)
+		}
+		tokens[role], users[role] = token, user
+	}
+
+	router := gin.New()
+	router.Use(middleware.Errors(), middleware.Authenticate(authService))
+	router.POST("/restaurants", authorizer.Require("create restaurant", Roles(models.RoleRestaurantOwner)), func(ctx *gin.Context) {
+		ctx.Status(http.StatusCreated)
+	})
+
+	tests := []struct {
+		name    string
+		token   string
+		status  int
+		audited bool
+	}{
+		{"owner", tokens[models.RoleRestaurantOwner], http.StatusCreated, false},
+		{"customer", tokens[models.RoleCustomer], http.StatusForbidden, true},
+		{"anonymous", "", http.StatusUnauthorized, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, _ := store.Count("audit_logs", nil)
+
+			request := httptest.NewRequest(http.MethodPost, "/restaurants", nil)
+			if tt.token != "" {
+				request.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+			if recorder.Code != tt.status {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.status)
+			}
+
+			after, err := store.Count("audit_logs", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if audited := after > before; audited != tt.audited {
+				t.Errorf("audited = %v, want %v", audited, tt.audited)
+			}
+		})
+	}
+
+	count, err := store.Count("audit_logs", bson.M{
+		"userId": users[models.RoleCustomer].ID,
+		"role":   models.RoleCustomer,
+		"policy": "create restaurant",
+		"path":   "/restaurants",
+	})
+	if err != nil || count != 1 {
+		t.Errorf("audit events of the customer's denial = %d, %v, want 1", count, err)
+	}
+}
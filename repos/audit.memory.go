@@ -0,0 +1,24 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MemoryAuditRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryAuditRepository(store *MemoryStore) *MemoryAuditRepository {
+	return &MemoryAuditRepository{store: store}
+}
+
+func (r *MemoryAuditRepository) CreateEvent(ctx context.Context, event *models.AuditEvent) error {
+	event.ID = primitive.NewObjectID()
+	event.CreatedAt = time.Now()
+
+	return r.store.Insert("audit_logs", event)
+}
@@ -0,0 +1,32 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AuditRepository stores the audit log of authorization decisions
+type AuditRepository interface {
+	CreateEvent(ctx context.Context, event *models.AuditEvent) error
+}
+
+type MongoAuditRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoAuditRepository(db *mongo.Database) *MongoAuditRepository {
+	collection := db.Collection("audit_logs")
+	return &MongoAuditRepository{collection: collection}
+}
+
+func (r *MongoAuditRepository) CreateEvent(ctx context.Context, event *models.AuditEvent) error {
+	event.ID = primitive.NewObjectID()
+	event.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, event)
+	return err
+}
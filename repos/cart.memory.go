@@ -0,0 +1,53 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MemoryCartRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryCartRepository(store *MemoryStore) *MemoryCartRepository {
+	return &MemoryCartRepository{store: store}
+}
+
+func (r *MemoryCartRepository) GetCartByCustomerID(ctx context.Context, customerID primitive.ObjectID) (*models.Cart, error) {
+	var cart models.Cart
+	if err := r.store.findOne("carts", bson.M{"customerId": customerID}, nil, &cart); err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// SaveCart replaces the customer's cart, creating it on first use
+func (r *MemoryCartRepository) SaveCart(ctx context.Context, cart *models.Cart) error {
+	now := time.Now()
+	if cart.ID.IsZero() {
+		cart.ID = primitive.NewObjectID()
+		cart.CreatedAt = now
+	}
+	cart.UpdatedAt = now
+
+	replacement, err := toDocument(cart)
+	if err != nil {
+		return err
+	}
+	matched, err := r.store.modify("carts", bson.M{"customerId": cart.CustomerID}, false, func(bson.M) (bson.M, error) {
+		return replacement, nil
+	})
+	if err != nil || matched > 0 {
+		return err
+	}
+	return r.store.Insert("carts", replacement)
+}
+
+func (r *MemoryCartRepository) DeleteCart(ctx context.Context, customerID primitive.ObjectID) error {
+	_, err := r.store.remove("carts", bson.M{"customerId": customerID}, false)
+	return err
+}
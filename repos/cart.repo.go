@@ -0,0 +1,54 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CartRepository stores customers' carts, one per customer
+type CartRepository interface {
+	GetCartByCustomerID(ctx context.Context, customerID primitive.ObjectID) (*models.Cart, error)
+	SaveCart(ctx context.Context, cart *models.Cart) error
+	DeleteCart(ctx context.Context, customerID primitive.ObjectID) error
+}
+
+type MongoCartRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoCartRepository(db *mongo.Database) *MongoCartRepository {
+	collection := db.Collection("carts")
+	return &MongoCartRepository{collection: collection}
+}
+
+func (r *MongoCartRepository) GetCartByCustomerID(ctx context.Context, customerID primitive.ObjectID) (*models.Cart, error) {
+	var cart models.Cart
+	if err := r.collection.FindOne(ctx, bson.M{"customerId": customerID}).Decode(&cart); err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// SaveCart replaces the customer's cart, creating it on first use
+func (r *MongoCartRepository) SaveCart(ctx context.Context, cart *models.Cart) error {
+	now := time.Now()
+	if cart.ID.IsZero() {
+		cart.ID = primitive.NewObjectID()
+		cart.CreatedAt = now
+	}
+	cart.UpdatedAt = now
+
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"customerId": cart.CustomerID}, cart, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *MongoCartRepository) DeleteCart(ctx context.Context, customerID primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"customerId": customerID})
+	return err
+}
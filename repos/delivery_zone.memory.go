@@ -0,0 +1,86 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MemoryDeliveryZoneRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryDeliveryZoneRepository(store *MemoryStore) *MemoryDeliveryZoneRepository {
+	return &MemoryDeliveryZoneRepository{store: store}
+}
+
+func (r *MemoryDeliveryZoneRepository) CreateZone(ctx context.Context, zone *models.DeliveryZone) error {
+	zone.ID = primitive.NewObjectID()
+	zone.CreatedAt = time.Now()
+	zone.UpdatedAt = zone.CreatedAt
+
+	return r.store.Insert("delivery_zones", zone)
+}
+
+func (r *MemoryDeliveryZoneRepository) GetZonesByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID) ([]models.DeliveryZone, error) {
+	documents, err := r.store.find("delivery_zones", bson.M{"restaurantId": restaurantID}, bson.D{{Key: "deliveryFee", Value: 1}}, 0)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAll[models.DeliveryZone](documents)
+}
+
+// UpdateZone replaces a zone's name, area and terms. The restaurant is part of the filter so a
+// zone can only be changed through the restaurant it belongs to.
+func (r *MemoryDeliveryZoneRepository) UpdateZone(ctx context.Context, zone *models.DeliveryZone) error {
+	zone.UpdatedAt = time.Now()
+	filter := bson.M{"_id": zone.ID, "restaurantId": zone.RestaurantID}
+	update := bson.M{"$set": bson.M{
+		"name":         zone.Name,
+		"area":         zone.Area,
+		"deliveryFee":  zone.DeliveryFee,
+		"minimumOrder": zone.MinimumOrder,
+		"updatedAt":    zone.UpdatedAt,
+	}}
+
+	matched, err := r.store.Update("delivery_zones", filter, update)
+	if err != nil {
+		return err
+	}
+	if matched == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *MemoryDeliveryZoneRepository) DeleteZone(ctx context.Context, restaurantID, zoneID primitive.ObjectID) error {
+	deleted, err := r.store.remove("delivery_zones", bson.M{"_id": zoneID, "restaurantId": restaurantID}, false)
+	if err != nil {
+		return err
+	}
+	if len(deleted) == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// FindZoneContaining returns the cheapest of the restaurant's zones that covers the point
+func (r *MemoryDeliveryZoneRepository) FindZoneContaining(ctx context.Context, restaurantID primitive.ObjectID, lng, lat float64) (*models.DeliveryZone, error) {
+	filter := bson.M{
+		"restaurantId": restaurantID,
+		"area": bson.M{"$geoIntersects": bson.M{
+			"$geometry": bson.M{"type": "Point", "coordinates": bson.A{lng, lat}},
+		}},
+	}
+	sortBy := bson.D{{Key: "deliveryFee", Value: 1}, {Key: "minimumOrder", Value: 1}}
+
+	var zone models.DeliveryZone
+	if err := r.store.findOne("delivery_zones", filter, sortBy, &zone); err != nil {
+		return nil, err
+	}
+	return &zone, nil
+}
@@ -0,0 +1,107 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DeliveryZoneRepository stores the areas restaurants deliver to
+type DeliveryZoneRepository interface {
+	CreateZone(ctx context.Context, zone *models.DeliveryZone) error
+	GetZonesByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID) ([]models.DeliveryZone, error)
+	UpdateZone(ctx context.Context, zone *models.DeliveryZone) error
+	DeleteZone(ctx context.Context, restaurantID, zoneID primitive.ObjectID) error
+	FindZoneContaining(ctx context.Context, restaurantID primitive.ObjectID, lng, lat float64) (*models.DeliveryZone, error)
+}
+
+type MongoDeliveryZoneRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoDeliveryZoneRepository(db *mongo.Database) *MongoDeliveryZoneRepository {
+	collection := db.Collection("delivery_zones")
+	return &MongoDeliveryZoneRepository{collection: collection}
+}
+
+func (r *MongoDeliveryZoneRepository) CreateZone(ctx context.Context, zone *models.DeliveryZone) error {
+	zone.ID = primitive.NewObjectID()
+	zone.CreatedAt = time.Now()
+	zone.UpdatedAt = zone.CreatedAt
+
+	_, err := r.collection.InsertOne(ctx, zone)
+	return err
+}
+
+func (r *MongoDeliveryZoneRepository) GetZonesByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID) ([]models.DeliveryZone, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "deliveryFee", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"restaurantId": restaurantID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	zones := []models.DeliveryZone{}
+	if err = cursor.All(ctx, &zones); err != nil {
+		return nil, err
+	}
+
+	return zones, nil
+}
+
+// UpdateZone replaces a zone's name, area and terms. The restaurant is part of the filter so a
+// zone can only be changed through the restaurant it belongs to.
+func (r *MongoDeliveryZoneRepository) UpdateZone(ctx context.Context, zone *models.DeliveryZone) error {
+	zone.UpdatedAt = time.Now()
+	filter := bson.M{"_id": zone.ID, "restaurantId": zone.RestaurantID}
+	update := bson.M{"$set": bson.M{
+		"name":         zone.Name,
+		"area":         zone.Area,
+		"deliveryFee":  zone.DeliveryFee,
+		"minimumOrder": zone.MinimumOrder,
+		"updatedAt":    zone.UpdatedAt,
+	}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *MongoDeliveryZoneRepository) DeleteZone(ctx context.Context, restaurantID, zoneID primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": zoneID, "restaurantId": restaurantID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// FindZoneContaining returns the cheapest of the restaurant's zones that covers the point, using
+// $geoIntersects against the area 2dsphere index
+func (r *MongoDeliveryZoneRepository) FindZoneContaining(ctx context.Context, restaurantID primitive.ObjectID, lng, lat float64) (*models.DeliveryZone, error) {
+	filter := bson.M{
+		"restaurantId": restaurantID,
+		"area": bson.M{"$geoIntersects": bson.M{
+			"$geometry": bson.M{"type": "Point", "coordinates": bson.A{lng, lat}},
+		}},
+	}
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "deliveryFee", Value: 1}, {Key: "minimumOrder", Value: 1}})
+
+	var zone models.DeliveryZone
+	if err := r.collection.FindOne(ctx, filter, findOptions).Decode(&zone); err != nil {
+		return nil, err
+	}
+	return &zone, nil
+}
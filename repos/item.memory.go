@@ -0,0 +1,166 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MemoryItemRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryItemRepository(store *MemoryStore) *MemoryItemRepository {
+	return &MemoryItemRepository{store: store}
+}
+
+func (r *MemoryItemRepository) CreateItem(ctx context.Context, item *models.Item) error {
+	if item.ID.IsZero() {
+		item.ID = primitive.NewObjectID()
+	}
+	return r.store.Insert("items", item)
+}
+
+func (r *MemoryItemRepository) GetItemByID(ctx context.Context, id primitive.ObjectID) (*models.Item, error) {
+	var item models.Item
+	if err := r.store.findOne("items", bson.M{"_id": id}, nil, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *MemoryItemRepository) CountItems(ctx context.Context, filter bson.M) (int64, error) {
+	return r.store.Count("items", filter)
+}
+
+func (r *MemoryItemRepository) UpdateItem(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	matched, err := r.store.Update("items", bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if matched == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *MemoryItemRepository) DeleteItem(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.store.remove("items", bson.M{"_id": id}, false)
+	return err
+}
+
+// setStock changes the stock of the item matching filter and derives its status from it, under
+// the store's lock. It returns how many items matched.
+func (r *MemoryItemRepository) setStock(filter bson.M, stock func(current int) int, change func(*models.Item)) (int64, error) {
+	return modifyAs(r.store, "items", filter, false, func(item *models.Item) error {
+		item.Inventory.Stock = stock(item.Inventory.Stock)
+		if change != nil {
+			change(item)
+		}
+		item.UpdatedAt = time.Now()
+		item.ApplyStockStatus()
+		return nil
+	})
+}
+
+// DecrementStock takes quantity from an item's stock, only if that much is left. It reports false
+// when the item doesn't have enough.
+func (r *MemoryItemRepository) DecrementStock(ctx context.Context, id primitive.ObjectID, quantity int) (bool, error) {
+	filter := bson.M{"_id": id, "inventory.stock": bson.M{"$gte": quantity}}
+	matched, err := r.setStock(filter, func(current int) int { return current - quantity }, nil)
+	return matched > 0, err
+}
+
+// IncrementStock puts quantity back into a tracked item's stock
+func (r *MemoryItemRepository) IncrementStock(ctx context.Context, id primitive.ObjectID, quantity int) error {
+	filter := bson.M{"_id": id, "inventory": bson.M{"$exists": true}}
+	_, err := r.setStock(filter, func(current int) int { return current + quantity }, nil)
+	return err
+}
+
+// AdjustStock applies stock adjustments to the restaurant's tracked items. A delta never takes
+// stock below zero. It returns how many items matched.
+func (r *MemoryItemRepository) AdjustStock(ctx context.Context, restaurantID primitive.ObjectID, adjustments []models.StockAdjustment) (int64, error) {
+	var total int64
+	for _, adjustment := range adjustments {
+		filter := bson.M{"_id": adjustment.ItemID, "restaurantId": restaurantID, "inventory": bson.M{"$exists": true}}
+		matched, err := r.setStock(filter, func(current int) int {
+			if adjustment.Stock != nil {
+				return *adjustment.Stock
+			}
+			return max(0, current+*adjustment.Delta)
+		}, nil)
+		if err != nil {
+			return total, err
+		}
+		total += matched
+	}
+	return total, nil
+}
+
+// SetStockedStatus sets a tracked item's status and derives it from the stock again, so an item
+// with no stock stays unavailable
+func (r *MemoryItemRepository) SetStockedStatus(ctx context.Context, id primitive.ObjectID, status string) error {
+	filter := bson.M{"_id": id, "inventory": bson.M{"$exists": true}}
+	_, err := r.setStock(filter, func(current int) int { return current }, func(item *models.Item) {
+		item.Status = status
+		item.Inventory.SoldOut = false
+	})
+	return err
+}
+
+// GetItemsDueForReset returns the items whose daily stock reset is at or before now
+func (r *MemoryItemRepository) GetItemsDueForReset(ctx context.Context, now time.Time) ([]models.Item, error) {
+	return r.findItems(bson.M{"inventory.nextResetAt": bson.M{"$lte": now}})
+}
+
+// ResetStock sets an item's stock back to its daily stock and schedules the next reset. The reset
+// being replaced is part of the filter so an item is reset once even if several workers run.
+func (r *MemoryItemRepository) ResetStock(ctx context.Context, item *models.Item, next time.Time) error {
+	filter := bson.M{"_id": item.ID, "inventory.nextResetAt": item.Inventory.NextResetAt}
+	dailyStock := *item.Inventory.DailyStock
+	_, err := r.setStock(filter, func(int) int { return dailyStock }, func(item *models.Item) {
+		item.Inventory.NextResetAt = &next
+	})
+	return err
+}
+
+// GetItemNames returns every item, for their IDs, restaurants and names
+func (r *MemoryItemRepository) GetItemNames(ctx context.Context) ([]models.Item, error) {
+	return r.findItems(bson.M{})
+}
+
+// SearchItems runs a text search over available item names and descriptions, best matches first.
+// A non-nil restaurantIDs limits the search to those restaurants.
+func (r *MemoryItemRepository) SearchItems(ctx context.Context, text string, restaurantIDs []primitive.ObjectID, limit int64) ([]models.ScoredItem, error) {
+	filter := bson.M{"status": models.ItemStatusAvailable}
+	if restaurantIDs != nil {
+		filter["restaurantId"] = bson.M{"$in": restaurantIDs}
+	}
+
+	documents, err := r.store.textSearch("items", text, filter, limit)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAll[models.ScoredItem](documents)
+}
+
+func (r *MemoryItemRepository) FindWithOptions(ctx context.Context, queryOpts models.QueryOptions) (*models.Page[models.Item], error) {
+	documents, err := r.store.find("items", bson.M(queryOpts.Filter), nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	return pageDocuments[models.Item](documents, queryOpts)
+}
+
+func (r *MemoryItemRepository) findItems(filter bson.M) ([]models.Item, error) {
+	documents, err := r.store.find("items", filter, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAll[models.Item](documents)
+}
@@ -2,6 +2,7 @@ package repos
 
 import (
 	"context"
+	"time"
 
 	"github.com/aldiandyaIrsyad/uber-eats/models"
 	"go.mongodb.org/mongo-driver/bson"
@@ -10,85 +11,233 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-type ItemRepository struct {
+// ItemRepository stores menu items and their stock
+type ItemRepository interface {
+	CreateItem(ctx context.Context, item *models.Item) error
+	GetItemByID(ctx context.Context, id primitive.ObjectID) (*models.Item, error)
+	CountItems(ctx context.Context, filter bson.M) (int64, error)
+	UpdateItem(ctx context.Context, id primitive.ObjectID, update bson.M) error
+	DeleteItem(ctx context.Context, id primitive.ObjectID) error
+	DecrementStock(ctx context.Context, id primitive.ObjectID, quantity int) (bool, error)
+	IncrementStock(ctx context.Context, id primitive.ObjectID, quantity int) error
+	AdjustStock(ctx context.Context, restaurantID primitive.ObjectID, adjustments []models.StockAdjustment) (int64, error)
+	SetStockedStatus(ctx context.Context, id primitive.ObjectID, status string) error
+	GetItemsDueForReset(ctx context.Context, now time.Time) ([]models.Item, error)
+	ResetStock(ctx context.Context, item *models.Item, next time.Time) error
+	GetItemNames(ctx context.Context) ([]models.Item, error)
+	SearchItems(ctx context.Context, text string, restaurantIDs []primitive.ObjectID, limit int64) ([]models.ScoredItem, error)
+	FindWithOptions(ctx context.Context, queryOpts models.QueryOptions) (*models.Page[models.Item], error)
+}
+
+type MongoItemRepository struct {
 	collection *mongo.Collection
 }
 
-func NewItemRepository(client *mongo.Client) *ItemRepository {
-	collection := client.Database("testing").Collection("items")
-	return &ItemRepository{collection: collection}
+func NewMongoItemRepository(db *mongo.Database) *MongoItemRepository {
+	collection := db.Collection("items")
+	return &MongoItemRepository{collection: collection}
 }
 
-func (r *ItemRepository) CreateItem(ctx context.Context, item *models.Item) error {
-	_, err := r.collection.InsertOne(ctx, item)
-	return err
+func (r *MongoItemRepository) CreateItem(ctx context.Context, item *models.Item) error {
+	result, err := r.collection.InsertOne(ctx, item)
+	if err != nil {
+		return err
+	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		item.ID = id
+	}
+	return nil
 }
 
-func (r *ItemRepository) GetItemByID(ctx context.Context, id primitive.ObjectID) (*models.Item, error) {
+func (r *MongoItemRepository) GetItemByID(ctx context.Context, id primitive.ObjectID) (*models.Item, error) {
 	var item models.Item
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&item)
-	return &item, err
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&item); err != nil {
+		return nil, err
+	}
+	return &item, nil
 }
 
-func (r *ItemRepository) UpdateItem(ctx context.Context, id primitive.ObjectID, update bson.M) error {
-	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
-	return err
+func (r *MongoItemRepository) CountItems(ctx context.Context, filter bson.M) (int64, error) {
+	return r.collection.CountDocuments(ctx, filter)
 }
 
-func (r *ItemRepository) DeleteItem(ctx context.Context, id primitive.ObjectID) error {
+func (r *MongoItemRepository) UpdateItem(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *MongoItemRepository) DeleteItem(ctx context.Context, id primitive.ObjectID) error {
 	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
 	return err
 }
 
-func (r *ItemRepository) FindWithOptions(ctx context.Context, queryOpts models.QueryOptions) (*models.Pagination, error) {
-	filter := bson.M{}
+// stockUpdate sets an item's stock and then derives its status from it, the same way as
+// Item.ApplyStockStatus, in one atomic update. set holds any other fields to change.
+func stockUpdate(stock interface{}, set bson.M) mongo.Pipeline {
+	stockSet := bson.M{"inventory.stock": stock, "updatedAt": time.Now()}
+	for k, v := range set {
+		stockSet[k] = v
+	}
+	outOfStock := bson.M{"$lte": bson.A{"$inventory.stock", 0}}
+
+	return mongo.Pipeline{
+		{{Key: "$set", Value: stockSet}},
+		{{Key: "$set", Value: bson.M{
+			"status": bson.M{"$switch": bson.M{
+				"branches": bson.A{
+					bson.M{"case": outOfStock, "then": models.ItemStatusUnavailable},
+					bson.M{"case": "$inventory.soldOut", "then": models.ItemStatusAvailable},
+				},
+				"default": "$status",
+			}},
+			"inventory.soldOut": bson.M{"$cond": bson.A{
+				outOfStock,
+				bson.M{"$or": bson.A{"$inventory.soldOut", bson.M{"$eq": bson.A{"$status", models.ItemStatusAvailable}}}},
+				false,
+			}},
+		}}},
+	}
+}
 
-	// Merge custom filters
-	if queryOpts.Filter != nil {
-		for k, v := range queryOpts.Filter {
-			filter[k] = v
+// DecrementStock takes quantity from an item's stock, only if that much is left. It reports false
+// when the item doesn't have enough.
+func (r *MongoItemRepository) DecrementStock(ctx context.Context, id primitive.ObjectID, quantity int) (bool, error) {
+	filter := bson.M{"_id": id, "inventory.stock": bson.M{"$gte": quantity}}
+	update := stockUpdate(bson.M{"$subtract": bson.A{"$inventory.stock", quantity}}, nil)
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// IncrementStock puts quantity back into a tracked item's stock
+func (r *MongoItemRepository) IncrementStock(ctx context.Context, id primitive.ObjectID, quantity int) error {
+	filter := bson.M{"_id": id, "inventory": bson.M{"$exists": true}}
+	update := stockUpdate(bson.M{"$add": bson.A{"$inventory.stock", quantity}}, nil)
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// AdjustStock applies stock adjustments to the restaurant's tracked items in one bulk write. A delta
+// never takes stock below zero. It returns how many items matched.
+func (r *MongoItemRepository) AdjustStock(ctx context.Context, restaurantID primitive.ObjectID, adjustments []models.StockAdjustment) (int64, error) {
+	writes := make([]mongo.WriteModel, 0, len(adjustments))
+	for _, adjustment := range adjustments {
+		var stock interface{}
+		if adjustment.Stock != nil {
+			stock = *adjustment.Stock
+		} else {
+			stock = bson.M{"$max": bson.A{0, bson.M{"$add": bson.A{"$inventory.stock", *adjustment.Delta}}}}
 		}
+
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": adjustment.ItemID, "restaurantId": restaurantID, "inventory": bson.M{"$exists": true}}).
+			SetUpdate(stockUpdate(stock, nil)))
+	}
+
+	result, err := r.collection.BulkWrite(ctx, writes)
+	if err != nil {
+		return 0, err
 	}
+	return result.MatchedCount, nil
+}
+
+// SetStockedStatus sets a tracked item's status and derives it from the stock again in one atomic
+// update, so an item with no stock stays unavailable
+func (r *MongoItemRepository) SetStockedStatus(ctx context.Context, id primitive.ObjectID, status string) error {
+	filter := bson.M{"_id": id, "inventory": bson.M{"$exists": true}}
+	update := stockUpdate("$inventory.stock", bson.M{"status": status, "inventory.soldOut": false})
 
-	// Set up options
-	findOptions := options.Find()
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
 
-	// Handle pagination
-	if queryOpts.Pagination != nil {
-		findOptions.SetSkip((queryOpts.Pagination.Page - 1) * queryOpts.Pagination.PageSize)
-		findOptions.SetLimit(queryOpts.Pagination.PageSize)
+// GetItemsDueForReset returns the items whose daily stock reset is at or before now
+func (r *MongoItemRepository) GetItemsDueForReset(ctx context.Context, now time.Time) ([]models.Item, error) {
+	findOptions := options.Find().SetProjection(bson.M{"restaurantId": 1, "inventory": 1})
+	cursor, err := r.collection.Find(ctx, bson.M{"inventory.nextResetAt": bson.M{"$lte": now}}, findOptions)
+	if err != nil {
+		return nil, err
 	}
+	defer cursor.Close(ctx)
 
-	// Handle sorting
-	if queryOpts.Sort != nil {
-		findOptions.SetSort(bson.D{{Key: queryOpts.Sort.Field, Value: queryOpts.Sort.Order}})
+	var items []models.Item
+	if err = cursor.All(ctx, &items); err != nil {
+		return nil, err
 	}
+	return items, nil
+}
+
+// ResetStock sets an item's stock back to its daily stock and schedules the next reset. The reset
+// being replaced is part of the filter so an item is reset once even if several workers run.
+func (r *MongoItemRepository) ResetStock(ctx context.Context, item *models.Item, next time.Time) error {
+	filter := bson.M{"_id": item.ID, "inventory.nextResetAt": item.Inventory.NextResetAt}
+	update := stockUpdate(*item.Inventory.DailyStock, bson.M{"inventory.nextResetAt": next})
 
-	// Get total count
-	total, err := r.collection.CountDocuments(ctx, filter)
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// GetItemNames returns the ID, restaurant and name of every item
+func (r *MongoItemRepository) GetItemNames(ctx context.Context) ([]models.Item, error) {
+	findOptions := options.Find().SetProjection(bson.M{"restaurantId": 1, "name": 1})
+	cursor, err := r.collection.Find(ctx, bson.M{}, findOptions)
 	if err != nil {
 		return nil, err
 	}
+	defer cursor.Close(ctx)
+
+	var items []models.Item
+	if err = cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// SearchItems runs a text search over available item names and descriptions, best matches first.
+// A non-nil restaurantIDs limits the search to those restaurants.
+func (r *MongoItemRepository) SearchItems(ctx context.Context, text string, restaurantIDs []primitive.ObjectID, limit int64) ([]models.ScoredItem, error) {
+	filter := bson.M{"$text": bson.M{"$search": text}, "status": models.ItemStatusAvailable}
+	if restaurantIDs != nil {
+		filter["restaurantId"] = bson.M{"$in": restaurantIDs}
+	}
+	score := bson.M{"$meta": "textScore"}
+	findOptions := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}}).
+		SetLimit(limit)
 
-	// Execute query
 	cursor, err := r.collection.Find(ctx, filter, findOptions)
 	if err != nil {
 		return nil, err
 	}
 	defer cursor.Close(ctx)
 
-	var items []models.Item
+	var items []models.ScoredItem
 	if err = cursor.All(ctx, &items); err != nil {
 		return nil, err
 	}
+	return items, nil
+}
+
+func (r *MongoItemRepository) FindWithOptions(ctx context.Context, queryOpts models.QueryOptions) (*models.Page[models.Item], error) {
+	filter := bson.M{}
 
-	// Create pagination result
-	pagination := models.NewPagination(queryOpts.Pagination.Page, queryOpts.Pagination.PageSize)
-	pagination.Total = total
-	pagination.Data = make([]interface{}, len(items))
-	for i, item := range items {
-		pagination.Data[i] = item
+	// Merge custom filters
+	if queryOpts.Filter != nil {
+		for k, v := range queryOpts.Filter {
+			filter[k] = v
+		}
 	}
 
-	return pagination, nil
+	return findPage[models.Item](ctx, r.collection, mongo.Pipeline{{{Key: "$match", Value: filter}}}, queryOpts)
 }
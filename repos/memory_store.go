@@ -0,0 +1,943 @@
+package repos
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memoryUniqueKeys mirrors the unique indexes created in config, so the in-memory store rejects
+// the same duplicates as MongoDB
+var memoryUniqueKeys = map[string][]string{
+	"users":   {"email"},
+	"carts":   {"customerId"},
+	"reviews": {"orderId"},
+}
+
+// memoryOperators are the query operators the in-memory store understands. Filters using any
+// other operator are rejected rather than silently matching nothing.
+var memoryOperators = map[string]bool{
+	"$and": true, "$or": true, "$nor": true,
+	"$eq": true, "$ne": true, "$gt": true, "$gte": true, "$lt": true, "$lte": true,
+	"$in": true, "$nin": true, "$exists": true, "$not": true, "$elemMatch": true,
+	"$geoWithin": true, "$centerSphere": true, "$geoIntersects": true, "$geometry": true,
+}
+
+// MemoryStore keeps collections of documents in memory for the Memory* repositories. Documents are
+// stored as BSON documents, so they go through the same bson tags as with MongoDB, and filters are
+// evaluated with the part of the query language the repositories and services use. Everything is
+// lost when the process exits.
+type MemoryStore struct {
+	mu          sync.RWMutex
+	collections map[string][]bson.M
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{collections: make(map[string][]bson.M)}
+}
+
+// Insert adds a document to a collection, giving it an _id if it has none
+func (s *MemoryStore) Insert(collection string, document interface{}) error {
+	doc, err := toDocument(document)
+	if err != nil {
+		return err
+	}
+	if _, ok := doc["_id"]; !ok {
+		doc["_id"] = primitive.NewObjectID()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.collections[collection] {
+		if compareValues(existing["_id"], doc["_id"]) == 0 {
+			return duplicateKeyError(collection, "_id")
+		}
+	}
+	if err := s.checkUnique(collection, doc); err != nil {
+		return err
+	}
+	s.collections[collection] = append(s.collections[collection], doc)
+	return nil
+}
+
+// Update applies a $set, $unset, $inc, $push or $pull update to every document matching filter and
+// returns how many matched
+func (s *MemoryStore) Update(collection string, filter, update interface{}) (int64, error) {
+	changes, err := normalize(update)
+	if err != nil {
+		return 0, err
+	}
+	return s.modify(collection, filter, true, func(doc bson.M) (bson.M, error) {
+		return doc, applyUpdate(doc, changes)
+	})
+}
+
+// Count returns how many documents of a collection match filter
+func (s *MemoryStore) Count(collection string, filter interface{}) (int64, error) {
+	query, err := normalizeFilter(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var count int64
+	for _, doc := range s.collections[collection] {
+		if matches(doc, query) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// find returns copies of the documents matching filter in the order of sortBy, at most limit of
+// them when limit is positive
+func (s *MemoryStore) find(collection string, filter interface{}, sortBy bson.D, limit int64) ([]bson.M, error) {
+	query, err := normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	var found []bson.M
+	for _, doc := range s.collections[collection] {
+		if matches(doc, query) {
+			found = append(found, doc)
+		}
+	}
+	copies := make([]bson.M, len(found))
+	for i, doc := range found {
+		if copies[i], err = toDocument(doc); err != nil {
+			s.mu.RUnlock()
+			return nil, err
+		}
+	}
+	s.mu.RUnlock()
+
+	sortDocuments(copies, sortBy)
+	if limit > 0 && int64(len(copies)) > limit {
+		copies = copies[:limit]
+	}
+	return copies, nil
+}
+
+// findOne decodes the first document matching filter in the order of sortBy into result. It
+// returns mongo.ErrNoDocuments when nothing matches, like FindOne.
+func (s *MemoryStore) findOne(collection string, filter interface{}, sortBy bson.D, result interface{}) error {
+	found, err := s.find(collection, filter, sortBy, 1)
+	if err != nil {
+		return err
+	}
+	if len(found) == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return fromDocument(found[0], result)
+}
+
+// pageDocuments returns one page of the documents of a listing, the way findPage does with an
+// aggregation
+func pageDocuments[T any](documents []bson.M, opts models.QueryOptions) (*models.Page[T], error) {
+	query := newPageQuery(opts)
+	total := int64(len(documents))
+
+	if keyset := query.keyset(); keyset != nil {
+		filter, err := normalize(keyset)
+		if err != nil {
+			return nil, err
+		}
+		selected := documents[:0]
+		for _, doc := range documents {
+			if matches(doc, filter) {
+				selected = append(selected, doc)
+			}
+		}
+		documents = selected
+	}
+	sortDocuments(documents, query.order())
+	documents = documents[min(query.skip(), int64(len(documents))):]
+	documents = documents[:min(query.limit(), int64(len(documents)))]
+
+	rows := make([]bson.Raw, len(documents))
+	for i, doc := range documents {
+		raw, err := bson.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		rows[i] = raw
+	}
+	return buildPage[T](query, rows, func() (int64, error) { return total, nil })
+}
+
+// textSearch runs a text search over the name and description of a collection's documents,
+// weighted like the text indexes, and returns the matches with their score, best first. Words
+// prefixed with "-" exclude the documents containing them.
+func (s *MemoryStore) textSearch(collection, text string, filter bson.M, limit int64) ([]bson.M, error) {
+	documents, err := s.find(collection, filter, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := models.SearchTerms(text)
+	var excluded []string
+	for _, field := range strings.Fields(text) {
+		if strings.HasPrefix(field, "-") {
+			excluded = append(excluded, models.SearchTerms(field[1:])...)
+		}
+	}
+
+	var found []bson.M
+	for _, doc := range documents {
+		name, _ := doc["name"].(string)
+		description, _ := doc["description"].(string)
+		if len(excluded) > 0 && models.TermMatches(name+" "+description, excluded) > 0 {
+			continue
+		}
+		score := 10*float64(models.TermMatches(name, terms)) + 2*float64(models.TermMatches(description, terms))
+		if score == 0 {
+			continue
+		}
+		doc["score"] = score
+		found = append(found, doc)
+	}
+
+	sortDocuments(found, bson.D{{Key: "score", Value: -1}, {Key: "_id", Value: 1}})
+	if limit > 0 && int64(len(found)) > limit {
+		found = found[:limit]
+	}
+	return found, nil
+}
+
+// modify replaces each document matching filter, or only the first one unless many, with what
+// change returns. It runs under the store's lock, so a check in filter and the change that
+// follows are atomic, as with a single update in MongoDB. It returns how many documents matched.
+func (s *MemoryStore) modify(collection string, filter interface{}, many bool, change func(bson.M) (bson.M, error)) (int64, error) {
+	query, err := normalizeFilter(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	documents := s.collections[collection]
+	var matched int64
+	for i, doc := range documents {
+		if !matches(doc, query) {
+			continue
+		}
+		matched++
+
+		working, err := toDocument(doc)
+		if err != nil {
+			return matched, err
+		}
+		changed, err := change(working)
+		if err != nil {
+			return matched, err
+		}
+		changed["_id"] = doc["_id"]
+		if err := s.checkUnique(collection, changed); err != nil {
+			return matched, err
+		}
+		documents[i] = changed
+
+		if !many {
+			break
+		}
+	}
+	return matched, nil
+}
+
+// modifyAs is modify for changes made on the decoded document
+func modifyAs[T any](s *MemoryStore, collection string, filter interface{}, many bool, change func(*T) error) (int64, error) {
+	return s.modify(collection, filter, many, func(doc bson.M) (bson.M, error) {
+		var value T
+		if err := fromDocument(doc, &value); err != nil {
+			return nil, err
+		}
+		if err := change(&value); err != nil {
+			return nil, err
+		}
+		return toDocument(value)
+	})
+}
+
+// remove deletes the documents matching filter, or only the first one unless many, and returns
+// them
+func (s *MemoryStore) remove(collection string, filter interface{}, many bool) ([]bson.M, error) {
+	query, err := normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	documents := s.collections[collection]
+	kept := documents[:0]
+	var deleted []bson.M
+	for _, doc := range documents {
+		if (many || len(deleted) == 0) && matches(doc, query) {
+			deleted = append(deleted, doc)
+			continue
+		}
+		kept = append(kept, doc)
+	}
+	s.collections[collection] = kept
+	return deleted, nil
+}
+
+// distinct returns the different values of a field among the documents matching filter
+func (s *MemoryStore) distinct(collection, field string, filter interface{}) ([]interface{}, error) {
+	found, err := s.find(collection, filter, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []interface{}
+	for _, doc := range found {
+		for _, value := range expand(lookup(doc, strings.Split(field, "."))) {
+			if _, isArray := value.(bson.A); isArray {
+				continue
+			}
+			seen := false
+			for _, existing := range values {
+				if compareValues(existing, value) == 0 {
+					seen = true
+					break
+				}
+			}
+			if !seen {
+				values = append(values, value)
+			}
+		}
+	}
+	return values, nil
+}
+
+// checkUnique reports a duplicate key error if doc has the same value as another document for one
+// of the collection's unique keys. The caller holds the lock.
+func (s *MemoryStore) checkUnique(collection string, doc bson.M) error {
+	for _, key := range memoryUniqueKeys[collection] {
+		value, ok := doc[key]
+		if !ok {
+			continue
+		}
+		for _, other := range s.collections[collection] {
+			if compareValues(other["_id"], doc["_id"]) == 0 {
+				continue
+			}
+			if existing, ok := other[key]; ok && compareValues(existing, value) == 0 {
+				return duplicateKeyError(collection, key)
+			}
+		}
+	}
+	return nil
+}
+
+// duplicateKeyError is the error MongoDB returns for a unique index violation, so that
+// mongo.IsDuplicateKeyError recognizes it
+func duplicateKeyError(collection, key string) error {
+	return mongo.WriteException{WriteErrors: mongo.WriteErrors{{
+		Code:    11000,
+		Message: fmt.Sprintf("E11000 duplicate key error collection: %s index: %s", collection, key),
+	}}}
+}
+
+// toDocument converts a value to the form documents are kept in: a bson.M holding the driver's
+// types, such as primitive.DateTime for times and bson.A for slices. It also deep copies documents.
+func toDocument(value interface{}) (bson.M, error) {
+	raw, err := bson.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// fromDocument decodes a stored document into result
+func fromDocument(doc bson.M, result interface{}) error {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(raw, result)
+}
+
+// decodeAll decodes stored documents into values
+func decodeAll[T any](documents []bson.M) ([]T, error) {
+	values := make([]T, len(documents))
+	for i, doc := range documents {
+		if err := fromDocument(doc, &values[i]); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// normalize converts a filter or update to the stored form so its values compare with stored ones
+func normalize(value interface{}) (bson.M, error) {
+	if value == nil {
+		return bson.M{}, nil
+	}
+	return toDocument(value)
+}
+
+// normalizeFilter normalizes a filter and checks that it only uses supported operators
+func normalizeFilter(filter interface{}) (bson.M, error) {
+	query, err := normalize(filter)
+	if err != nil {
+		return nil, err
+	}
+	return query, checkOperators(query)
+}
+
+func checkOperators(value interface{}) error {
+	switch v := value.(type) {
+	case bson.M:
+		for key, child := range v {
+			if strings.HasPrefix(key, "$") && !memoryOperators[key] {
+				return fmt.Errorf("memory store: %s is not supported", key)
+			}
+			if err := checkOperators(child); err != nil {
+				return err
+			}
+		}
+	case bson.A:
+		for _, child := range v {
+			if err := checkOperators(child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// matches reports whether doc matches filter
+func matches(doc bson.M, filter bson.M) bool {
+	for key, condition := range filter {
+		switch key {
+		case "$and", "$or", "$nor":
+			clauses, _ := condition.(bson.A)
+			matched := 0
+			for _, clause := range clauses {
+				if sub, ok := clause.(bson.M); ok && matches(doc, sub) {
+					matched++
+				}
+			}
+			switch {
+			case key == "$and" && matched != len(clauses),
+				key == "$or" && matched == 0,
+				key == "$nor" && matched > 0:
+				return false
+			}
+		default:
+			if !matchValues(lookup(doc, strings.Split(key, ".")), condition) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// lookup returns the values at a dotted path. Through arrays of documents it collects the field of
+// every element, as MongoDB does; it returns nothing when the path doesn't exist.
+func lookup(value interface{}, path []string) []interface{} {
+	if len(path) == 0 {
+		return []interface{}{value}
+	}
+	switch v := value.(type) {
+	case bson.M:
+		child, ok := v[path[0]]
+		if !ok {
+			return nil
+		}
+		return lookup(child, path[1:])
+	case bson.A:
+		if index, err := strconv.Atoi(path[0]); err == nil {
+			if index < len(v) {
+				return lookup(v[index], path[1:])
+			}
+			return nil
+		}
+		var found []interface{}
+		for _, element := range v {
+			if _, ok := element.(bson.M); ok {
+				found = append(found, lookup(element, path)...)
+			}
+		}
+		return found
+	}
+	return nil
+}
+
+// expand adds the elements of array values to values, since a condition on an array field
+// matches if any element does
+func expand(values []interface{}) []interface{} {
+	expanded := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		expanded = append(expanded, value)
+		if array, ok := value.(bson.A); ok {
+			expanded = append(expanded, array...)
+		}
+	}
+	return expanded
+}
+
+// operatorDocument returns condition as a document of operators, such as {"$gt": 1}
+func operatorDocument(condition interface{}) (bson.M, bool) {
+	doc, ok := condition.(bson.M)
+	if !ok || len(doc) == 0 {
+		return nil, false
+	}
+	for key := range doc {
+		if !strings.HasPrefix(key, "$") {
+			return nil, false
+		}
+	}
+	return doc, true
+}
+
+func matchValues(values []interface{}, condition interface{}) bool {
+	operators, ok := operatorDocument(condition)
+	if !ok {
+		return equalsAny(values, condition)
+	}
+	for operator, argument := range operators {
+		if !matchOperator(values, operator, argument) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalsAny reports whether one of values, or one of their elements, equals target. Missing
+// fields equal null.
+func equalsAny(values []interface{}, target interface{}) bool {
+	if target == nil && len(values) == 0 {
+		return true
+	}
+	for _, value := range expand(values) {
+		if compareValues(value, target) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func matchOperator(values []interface{}, operator string, argument interface{}) bool {
+	switch operator {
+	case "$eq":
+		return equalsAny(values, argument)
+	case "$ne":
+		return !equalsAny(values, argument)
+	case "$in", "$nin":
+		candidates, _ := argument.(bson.A)
+		in := false
+		for _, candidate := range candidates {
+			if equalsAny(values, candidate) {
+				in = true
+				break
+			}
+		}
+		return in == (operator == "$in")
+	case "$gt", "$gte", "$lt", "$lte":
+		if argument == nil {
+			return operator != "$gt" && operator != "$lt" && equalsAny(values, nil)
+		}
+		for _, value := range expand(values) {
+			// Like MongoDB, only values of the same type are compared
+			if typeClass(value) != typeClass(argument) {
+				continue
+			}
+			c := compareValues(value, argument)
+			if (operator == "$gt" && c > 0) || (operator == "$gte" && c >= 0) ||
+				(operator == "$lt" && c < 0) || (operator == "$lte" && c <= 0) {
+				return true
+			}
+		}
+		return false
+	case "$exists":
+		return (len(values) > 0) == truthy(argument)
+	case "$not":
+		return !matchValues(values, argument)
+	case "$elemMatch":
+		condition, _ := argument.(bson.M)
+		for _, value := range values {
+			array, ok := value.(bson.A)
+			if !ok {
+				continue
+			}
+			for _, element := range array {
+				if _, isOperators := operatorDocument(condition); isOperators {
+					if matchValues([]interface{}{element}, condition) {
+						return true
+					}
+				} else if doc, ok := element.(bson.M); ok && matches(doc, condition) {
+					return true
+				}
+			}
+		}
+		return false
+	case "$geoWithin":
+		sphere, _ := argument.(bson.M)["$centerSphere"].(bson.A)
+		if len(sphere) != 2 {
+			return false
+		}
+		center, radius := positionOf(sphere[0]), toFloat(sphere[1])
+		for _, value := range values {
+			if point := geoPoint(value); point != nil && center != nil && angularDistance(center, point) <= radius {
+				return true
+			}
+		}
+		return false
+	case "$geoIntersects":
+		geometry, _ := argument.(bson.M)["$geometry"].(bson.M)
+		point := geoPoint(geometry)
+		for _, value := range values {
+			if point != nil && polygonContains(value, point) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+func truthy(value interface{}) bool {
+	if b, ok := value.(bool); ok {
+		return b
+	}
+	return isNumber(value) && toFloat(value) != 0
+}
+
+// typeClass ranks BSON types in MongoDB's comparison order
+func typeClass(value interface{}) int {
+	switch value.(type) {
+	case nil, primitive.Null, primitive.Undefined:
+		return 1
+	case int, int32, int64, float64:
+		return 2
+	case string, primitive.Symbol:
+		return 3
+	case bson.M:
+		return 4
+	case bson.A:
+		return 5
+	case primitive.Binary:
+		return 6
+	case primitive.ObjectID:
+		return 7
+	case bool:
+		return 8
+	case primitive.DateTime:
+		return 9
+	}
+	return 10
+}
+
+// compareValues orders two stored values as MongoDB does: by type first, then by value
+func compareValues(a, b interface{}) int {
+	if ca, cb := typeClass(a), typeClass(b); ca != cb {
+		return ca - cb
+	}
+	switch x := a.(type) {
+	case int, int32, int64, float64:
+		return compareFloats(toFloat(x), toFloat(b))
+	case string:
+		return strings.Compare(x, b.(string))
+	case primitive.ObjectID:
+		y := b.(primitive.ObjectID)
+		return bytes.Compare(x[:], y[:])
+	case bool:
+		y := b.(bool)
+		switch {
+		case x == y:
+			return 0
+		case !x:
+			return -1
+		}
+		return 1
+	case primitive.DateTime:
+		return compareFloats(float64(x), float64(b.(primitive.DateTime)))
+	case bson.A:
+		y := b.(bson.A)
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if c := compareValues(x[i], y[i]); c != 0 {
+				return c
+			}
+		}
+		return len(x) - len(y)
+	case bson.M:
+		y := b.(bson.M)
+		keys := sortedKeys(x, y)
+		for _, key := range keys {
+			xv, xok := x[key]
+			yv, yok := y[key]
+			if xok != yok {
+				if xok {
+					return 1
+				}
+				return -1
+			}
+			if c := compareValues(xv, yv); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func sortedKeys(docs ...bson.M) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, doc := range docs {
+		for key := range doc {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func isNumber(value interface{}) bool {
+	switch value.(type) {
+	case int, int32, int64, float64:
+		return true
+	}
+	return false
+}
+
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+// sortDocuments sorts documents by a sort document such as {rating: -1, _id: 1}. Missing fields
+// sort as null, before everything else.
+func sortDocuments(documents []bson.M, order bson.D) {
+	sort.SliceStable(documents, func(i, j int) bool {
+		for _, key := range order {
+			path := strings.Split(key.Key, ".")
+			c := compareValues(sortValue(documents[i], path), sortValue(documents[j], path))
+			if c == 0 {
+				continue
+			}
+			if toFloat(key.Value) < 0 {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+func sortValue(doc bson.M, path []string) interface{} {
+	if values := lookup(doc, path); len(values) > 0 {
+		return values[0]
+	}
+	return nil
+}
+
+// applyUpdate applies update operators to doc
+func applyUpdate(doc bson.M, update bson.M) error {
+	for operator, argument := range update {
+		fields, _ := argument.(bson.M)
+		for path, value := range fields {
+			keys := strings.Split(path, ".")
+			switch operator {
+			case "$set":
+				setPath(doc, keys, value)
+			case "$unset":
+				unsetPath(doc, keys)
+			case "$inc":
+				current := sortValue(doc, keys)
+				switch {
+				case !isNumber(value):
+					return fmt.Errorf("memory store: cannot $inc %s by a non-numeric value", path)
+				case current == nil:
+					setPath(doc, keys, value)
+				case !isNumber(current):
+					return fmt.Errorf("memory store: cannot $inc non-numeric field %s", path)
+				default:
+					setPath(doc, keys, addNumbers(current, value))
+				}
+			case "$push":
+				array, _ := sortValue(doc, keys).(bson.A)
+				setPath(doc, keys, append(array, value))
+			case "$pull":
+				array, ok := sortValue(doc, keys).(bson.A)
+				if !ok {
+					continue
+				}
+				kept := bson.A{}
+				for _, element := range array {
+					if !pulls(element, value) {
+						kept = append(kept, element)
+					}
+				}
+				setPath(doc, keys, kept)
+			default:
+				return fmt.Errorf("memory store: update operator %s is not supported", operator)
+			}
+		}
+	}
+	return nil
+}
+
+// pulls reports whether a $pull condition removes an array element
+func pulls(element, condition interface{}) bool {
+	if query, ok := condition.(bson.M); ok {
+		if _, isOperators := operatorDocument(query); !isOperators {
+			doc, isDocument := element.(bson.M)
+			return isDocument && matches(doc, query)
+		}
+	}
+	return matchValues([]interface{}{element}, condition)
+}
+
+// addNumbers adds two numbers, keeping them integers when both are
+func addNumbers(a, b interface{}) interface{} {
+	x, xInt32 := a.(int32)
+	y, yInt32 := b.(int32)
+	switch {
+	case xInt32 && yInt32:
+		return x + y
+	case isInteger(a) && isInteger(b):
+		return toInt64(a) + toInt64(b)
+	}
+	return toFloat(a) + toFloat(b)
+}
+
+func isInteger(value interface{}) bool {
+	switch value.(type) {
+	case int, int32, int64:
+		return true
+	}
+	return false
+}
+
+func toInt64(value interface{}) int64 {
+	switch v := value.(type) {
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	}
+	return int64(toFloat(value))
+}
+
+func setPath(doc bson.M, keys []string, value interface{}) {
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := doc[key].(bson.M)
+		if !ok {
+			child = bson.M{}
+			doc[key] = child
+		}
+		doc = child
+	}
+	doc[keys[len(keys)-1]] = value
+}
+
+func unsetPath(doc bson.M, keys []string) {
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := doc[key].(bson.M)
+		if !ok {
+			return
+		}
+		doc = child
+	}
+	delete(doc, keys[len(keys)-1])
+}
+
+// positionOf reads a [longitude, latitude] position
+func positionOf(value interface{}) []float64 {
+	array, ok := value.(bson.A)
+	if !ok || len(array) != 2 || !isNumber(array[0]) || !isNumber(array[1]) {
+		return nil
+	}
+	return []float64{toFloat(array[0]), toFloat(array[1])}
+}
+
+// geoPoint reads the position of a GeoJSON point
+func geoPoint(value interface{}) []float64 {
+	doc, ok := value.(bson.M)
+	if !ok || doc["type"] != "Point" {
+		return nil
+	}
+	return positionOf(doc["coordinates"])
+}
+
+// angularDistance is the great-circle distance between two positions in radians
+func angularDistance(a, b []float64) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	lat1, lat2 := toRadians(a[1]), toRadians(b[1])
+	dLat := lat2 - lat1
+	dLng := toRadians(b[0] - a[0])
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * math.Asin(math.Sqrt(math.Min(h, 1)))
+}
+
+// polygonContains reports whether a GeoJSON polygon contains a point: inside its outer ring and
+// outside its holes. Edges are treated as straight lines in longitude and latitude, which is close
+// enough for delivery zones.
+func polygonContains(value interface{}, point []float64) bool {
+	doc, ok := value.(bson.M)
+	if !ok || doc["type"] != "Polygon" {
+		return false
+	}
+	rings, _ := doc["coordinates"].(bson.A)
+	for i, ring := range rings {
+		positions, _ := ring.(bson.A)
+		inside := false
+		for j, k := 0, len(positions)-1; j < len(positions); k, j = j, j+1 {
+			a, b := positionOf(positions[j]), positionOf(positions[k])
+			if a == nil || b == nil {
+				continue
+			}
+			if (a[1] > point[1]) != (b[1] > point[1]) &&
+				point[0] < (b[0]-a[0])*(point[1]-a[1])/(b[1]-a[1])+a[0] {
+				inside = !inside
+			}
+		}
+		if inside != (i == 0) {
+			return false
+		}
+	}
+	return len(rings) > 0
+}
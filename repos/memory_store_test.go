@@ -0,0 +1,196 @@
+package repos
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func newFilterTestStore(t *testing.T) *MemoryStore {
+	t.Helper()
+	s := NewMemoryStore()
+	documents := []bson.M{
+		{
+			"name":     "a",
+			"rating":   4.5,
+			"tags":     bson.A{"vegan", "spicy"},
+			"owner":    bson.M{"name": "x"},
+			"items":    bson.A{bson.M{"name": "fries", "qty": 2}, bson.M{"name": "burger", "qty": 1}},
+			"location": bson.M{"type": "Point", "coordinates": bson.A{0.0, 0.0}},
+			"area": bson.M{"type": "Polygon", "coordinates": bson.A{
+				bson.A{bson.A{-1.0, -1.0}, bson.A{1.0, -1.0}, bson.A{1.0, 1.0}, bson.A{-1.0, 1.0}, bson.A{-1.0, -1.0}},
+				bson.A{bson.A{0.5, 0.5}, bson.A{0.9, 0.5}, bson.A{0.9, 0.9}, bson.A{0.5, 0.9}, bson.A{0.5, 0.5}},
+			}},
+		},
+		{
+			"name":     "b",
+			"rating":   3,
+			"tags":     bson.A{"spicy"},
+			"items":    bson.A{bson.M{"name": "fries", "qty": 5}},
+			"location": bson.M{"type": "Point", "coordinates": bson.A{10.0, 10.0}},
+		},
+		{
+			"name":   "c",
+			"closed": true,
+		},
+	}
+	for _, doc := range documents {
+		if err := s.Insert("things", doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func TestMemoryStoreFilters(t *testing.T) {
+	s := newFilterTestStore(t)
+	tests := []struct {
+		name   string
+		filter bson.M
+		want   []string
+	}{
+		{"empty", bson.M{}, []string{"a", "b", "c"}},
+		{"equality", bson.M{"name": "a"}, []string{"a"}},
+		{"equality on an array element", bson.M{"tags": "vegan"}, []string{"a"}},
+		{"dotted path", bson.M{"owner.name": "x"}, []string{"a"}},
+		{"dotted path through an array", bson.M{"items.name": "burger"}, []string{"a"}},
+		{"null matches missing fields", bson.M{"rating": nil}, []string{"c"}},
+		{"$eq", bson.M{"rating": bson.M{"$eq": 3}}, []string{"b"}},
+		{"$ne", bson.M{"rating": bson.M{"$ne": 3}}, []string{"a", "c"}},
+		{"$gt", bson.M{"rating": bson.M{"$gt": 3}}, []string{"a"}},
+		{"$gte", bson.M{"rating": bson.M{"$gte": 3}}, []string{"a", "b"}},
+		{"$lt", bson.M{"rating": bson.M{"$lt": 4}}, []string{"b"}},
+		{"$lte", bson.M{"rating": bson.M{"$lte": 4.5}}, []string{"a", "b"}},
+		{"range", bson.M{"rating": bson.M{"$gt": 3, "$lte": 5}}, []string{"a"}},
+		{"comparisons skip other types", bson.M{"rating": bson.M{"$gt": "0"}}, nil},
+		{"$in", bson.M{"name": bson.M{"$in": bson.A{"a", "c"}}}, []string{"a", "c"}},
+		{"$in on an array", bson.M{"tags": bson.M{"$in": bson.A{"spicy"}}}, []string{"a", "b"}},
+		{"$nin", bson.M{"name": bson.M{"$nin": bson.A{"a", "c"}}}, []string{"b"}},
+		{"$exists", bson.M{"rating": bson.M{"$exists": true}}, []string{"a", "b"}},
+		{"$exists false", bson.M{"rating": bson.M{"$exists": false}}, []string{"c"}},
+		{"$not", bson.M{"rating": bson.M{"$not": bson.M{"$gt": 4}}}, []string{"b", "c"}},
+		{"$and", bson.M{"$and": bson.A{bson.M{"tags": "spicy"}, bson.M{"rating": bson.M{"$lt": 4}}}}, []string{"b"}},
+		{"$or", bson.M{"$or": bson.A{bson.M{"name": "a"}, bson.M{"closed": true}}}, []string{"a", "c"}},
+		{"$nor", bson.M{"$nor": bson.A{bson.M{"name": "a"}, bson.M{"closed": true}}}, []string{"b"}},
+		{"$elemMatch on documents", bson.M{"items": bson.M{"$elemMatch": bson.M{"name": "fries", "qty": bson.M{"$gt": 3}}}}, []string{"b"}},
+		{"$elemMatch needs one element to match all", bson.M{"items": bson.M{"$elemMatch": bson.M{"name": "burger", "qty": 2}}}, nil},
+		{"$elemMatch on scalars", bson.M{"tags": bson.M{"$elemMatch": bson.M{"$eq": "vegan"}}}, []string{"a"}},
+		{"$geoWithin", bson.M{"location": bson.M{"$geoWithin": bson.M{"$centerSphere": bson.A{bson.A{0.1, 0.1}, 0.01}}}}, []string{"a"}},
+		{"$geoWithin far away", bson.M{"location": bson.M{"$geoWithin": bson.M{"$centerSphere": bson.A{bson.A{50.0, 50.0}, 0.01}}}}, nil},
+		{"$geoIntersects", bson.M{"area": bson.M{"$geoIntersects": bson.M{"$geometry": bson.M{"type": "Point", "coordinates": bson.A{0.2, 0.2}}}}}, []string{"a"}},
+		{"$geoIntersects outside", bson.M{"area": bson.M{"$geoIntersects": bson.M{"$geometry": bson.M{"type": "Point", "coordinates": bson.A{2.0, 2.0}}}}}, nil},
+		{"$geoIntersects in a hole", bson.M{"area": bson.M{"$geoIntersects": bson.M{"$geometry": bson.M{"type": "Point", "coordinates": bson.A{0.7, 0.7}}}}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := s.find("things", tt.filter, bson.D{{Key: "name", Value: 1}}, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, doc := range found {
+				names = append(names, doc["name"].(string))
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("got %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreRejectsUnsupportedOperators(t *testing.T) {
+	s := newFilterTestStore(t)
+	if _, err := s.find("things", bson.M{"name": bson.M{"$regex": "a"}}, nil, 0); err == nil {
+		t.Error("find with $regex: expected an error")
+	}
+	if _, err := s.Update("things", bson.M{}, bson.M{"$rename": bson.M{"name": "title"}}); err == nil {
+		t.Error("update with $rename: expected an error")
+	}
+}
+
+func TestMemoryStoreUpdates(t *testing.T) {
+	tests := []struct {
+		name    string
+		update  bson.M
+		field   string
+		want    interface{}
+		missing bool
+		wantErr bool
+	}{
+		{name: "$set", update: bson.M{"$set": bson.M{"name": "z"}}, field: "name", want: "z"},
+		{name: "$set a nested field", update: bson.M{"$set": bson.M{"nested.b": 2}}, field: "nested", want: bson.M{"a": 1, "b": 2}},
+		{name: "$unset", update: bson.M{"$unset": bson.M{"nested.a": ""}}, field: "nested", want: bson.M{}},
+		{name: "$unset a missing field", update: bson.M{"$unset": bson.M{"other": ""}}, field: "other", missing: true},
+		{name: "$inc", update: bson.M{"$inc": bson.M{"count": 2}}, field: "count", want: int32(3)},
+		{name: "$inc by a negative number", update: bson.M{"$inc": bson.M{"count": -1}}, field: "count", want: int32(0)},
+		{name: "$inc by a float", update: bson.M{"$inc": bson.M{"count": 0.5}}, field: "count", want: 1.5},
+		{name: "$inc a missing field", update: bson.M{"$inc": bson.M{"other": 4}}, field: "other", want: int32(4)},
+		{name: "$inc a string", update: bson.M{"$inc": bson.M{"name": 1}}, wantErr: true},
+		{name: "$inc by a string", update: bson.M{"$inc": bson.M{"count": "1"}}, wantErr: true},
+		{name: "$push", update: bson.M{"$push": bson.M{"tags": "c"}}, field: "tags", want: bson.A{"a", "b", "c"}},
+		{name: "$push to a missing array", update: bson.M{"$push": bson.M{"other": "c"}}, field: "other", want: bson.A{"c"}},
+		{name: "$pull a value", update: bson.M{"$pull": bson.M{"tags": "a"}}, field: "tags", want: bson.A{"b"}},
+		{name: "$pull with an operator", update: bson.M{"$pull": bson.M{"tags": bson.M{"$in": bson.A{"a", "b"}}}}, field: "tags", want: bson.A{}},
+		{name: "$pull documents", update: bson.M{"$pull": bson.M{"items": bson.M{"name": "x"}}}, field: "items", want: bson.A{bson.M{"name": "y"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMemoryStore()
+			doc := bson.M{
+				"name":   "a",
+				"count":  int32(1),
+				"tags":   bson.A{"a", "b"},
+				"items":  bson.A{bson.M{"name": "x"}, bson.M{"name": "y"}},
+				"nested": bson.M{"a": 1},
+			}
+			if err := s.Insert("things", doc); err != nil {
+				t.Fatal(err)
+			}
+
+			matched, err := s.Update("things", bson.M{}, tt.update)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil || matched != 1 {
+				t.Fatalf("Update() = %d, %v", matched, err)
+			}
+
+			found, err := s.find("things", bson.M{}, nil, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := found[0][tt.field]
+			if tt.missing {
+				if ok {
+					t.Errorf("%s = %v, want it unset", tt.field, got)
+				}
+				return
+			}
+			want, err := toDocument(bson.M{"v": tt.want})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want["v"]) {
+				t.Errorf("%s = %#v, want %#v", tt.field, got, want["v"])
+			}
+		})
+	}
+}
+
+func TestMemoryStoreUniqueKeys(t *testing.T) {
+	s := NewMemoryStore()
+	if err := s.Insert("users", bson.M{"email": "a@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Insert("users", bson.M{"email": "a@example.com"}); !mongo.IsDuplicateKeyError(err) {
+		t.Errorf("second user with the same email: err = %v, want a duplicate key error", err)
+	}
+	if err := s.Insert("users", bson.M{"email": "b@example.com"}); err != nil {
+		t.Errorf("user with another email: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MemoryMenuRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryMenuRepository(store *MemoryStore) *MemoryMenuRepository {
+	return &MemoryMenuRepository{store: store}
+}
+
+func (r *MemoryMenuRepository) CreateMenu(ctx context.Context, menu *models.Menu) error {
+	menu.ID = primitive.NewObjectID()
+	menu.CreatedAt = time.Now()
+	menu.UpdatedAt = menu.CreatedAt
+
+	return r.store.Insert("menus", menu)
+}
+
+func (r *MemoryMenuRepository) GetMenusByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID) ([]models.Menu, error) {
+	return r.GetMenusByRestaurantIDs(ctx, []primitive.ObjectID{restaurantID})
+}
+
+func (r *MemoryMenuRepository) GetMenusByRestaurantIDs(ctx context.Context, restaurantIDs []primitive.ObjectID) ([]models.Menu, error) {
+	documents, err := r.store.find("menus", bson.M{"restaurantId": bson.M{"$in": restaurantIDs}}, bson.D{{Key: "name", Value: 1}}, 0)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAll[models.Menu](documents)
+}
+
+// UpdateMenu replaces a menu's name, schedule and contents. The restaurant is part of the filter
+// so a menu can only be changed through the restaurant it belongs to.
+func (r *MemoryMenuRepository) UpdateMenu(ctx context.Context, menu *models.Menu) error {
+	menu.UpdatedAt = time.Now()
+	filter := bson.M{"_id": menu.ID, "restaurantId": menu.RestaurantID}
+	update := bson.M{"$set": bson.M{
+		"name":          menu.Name,
+		"description":   menu.Description,
+		"schedule":      menu.Schedule,
+		"openIntervals": menu.OpenIntervals,
+		"categoryIds":   menu.CategoryIDs,
+		"itemIds":       menu.ItemIDs,
+		"updatedAt":     menu.UpdatedAt,
+	}}
+
+	matched, err := r.store.Update("menus", filter, update)
+	if err != nil {
+		return err
+	}
+	if matched == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *MemoryMenuRepository) DeleteMenu(ctx context.Context, restaurantID, menuID primitive.ObjectID) error {
+	deleted, err := r.store.remove("menus", bson.M{"_id": menuID, "restaurantId": restaurantID}, false)
+	if err != nil {
+		return err
+	}
+	if len(deleted) == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
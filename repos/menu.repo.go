@@ -0,0 +1,108 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MenuRepository stores restaurants' scheduled menus
+type MenuRepository interface {
+	CreateMenu(ctx context.Context, menu *models.Menu) error
+	GetMenusByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID) ([]models.Menu, error)
+	GetMenusByRestaurantIDs(ctx context.Context, restaurantIDs []primitive.ObjectID) ([]models.Menu, error)
+	UpdateMenu(ctx context.Context, menu *models.Menu) error
+	DeleteMenu(ctx context.Context, restaurantID, menuID primitive.ObjectID) error
+}
+
+type MongoMenuRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoMenuRepository(db *mongo.Database) *MongoMenuRepository {
+	collection := db.Collection("menus")
+	return &MongoMenuRepository{collection: collection}
+}
+
+func (r *MongoMenuRepository) CreateMenu(ctx context.Context, menu *models.Menu) error {
+	menu.ID = primitive.NewObjectID()
+	menu.CreatedAt = time.Now()
+	menu.UpdatedAt = menu.CreatedAt
+
+	_, err := r.collection.InsertOne(ctx, menu)
+	return err
+}
+
+func (r *MongoMenuRepository) GetMenusByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID) ([]models.Menu, error) {
+	return r.GetMenusByRestaurantIDs(ctx, []primitive.ObjectID{restaurantID})
+}
+
+func (r *MongoMenuRepository) GetMenusByRestaurantIDs(ctx context.Context, restaurantIDs []primitive.ObjectID) ([]models.Menu, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"restaurantId": bson.M{"$in": restaurantIDs}}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	menus := []models.Menu{}
+	if err = cursor.All(ctx, &menus); err != nil {
+		return nil, err
+	}
+
+	return menus, nil
+}
+
+// UpdateMenu replaces a menu's name, schedule and contents. The restaurant is part of the filter
+// so a menu can only be changed through the restaurant it belongs to.
+func (r *MongoMenuRepository) UpdateMenu(ctx context.Context, menu *models.Menu) error {
+	menu.UpdatedAt = time.Now()
+	filter := bson.M{"_id": menu.ID, "restaurantId": menu.RestaurantID}
+	update := bson.M{"$set": bson.M{
+		"name":          menu.Name,
+		"description":   menu.Description,
+		"schedule":      menu.Schedule,
+		"openIntervals": menu.OpenIntervals,
+		"categoryIds":   menu.CategoryIDs,
+		"itemIds":       menu.ItemIDs,
+		"updatedAt":     menu.UpdatedAt,
+	}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *MongoMenuRepository) DeleteMenu(ctx context.Context, restaurantID, menuID primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": menuID, "restaurantId": restaurantID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// MenuAvailabilityFilter matches the items the menus allow: those on a menu that is on, and
+// those on no menu at all
+func MenuAvailabilityFilter(availability *models.MenuAvailability) bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{"_id": bson.M{"$in": availability.ActiveItems}},
+		bson.M{"categoryId": bson.M{"$in": availability.ActiveCategories}},
+		bson.M{
+			"_id":        bson.M{"$nin": availability.ScheduledItems},
+			"categoryId": bson.M{"$nin": availability.ScheduledCategories},
+		},
+	}}
+}
@@ -0,0 +1,118 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MemoryMenuCategoryRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryMenuCategoryRepository(store *MemoryStore) *MemoryMenuCategoryRepository {
+	return &MemoryMenuCategoryRepository{store: store}
+}
+
+func (r *MemoryMenuCategoryRepository) CreateCategory(ctx context.Context, category *models.MenuCategory) error {
+	category.ID = primitive.NewObjectID()
+	category.CreatedAt = time.Now()
+	category.UpdatedAt = category.CreatedAt
+
+	return r.store.Insert("menu_categories", category)
+}
+
+func (r *MemoryMenuCategoryRepository) GetCategoryByID(ctx context.Context, id primitive.ObjectID) (*models.MenuCategory, error) {
+	var category models.MenuCategory
+	if err := r.store.findOne("menu_categories", bson.M{"_id": id}, nil, &category); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *MemoryMenuCategoryRepository) GetCategoriesByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID) ([]models.MenuCategory, error) {
+	documents, err := r.store.find("menu_categories", bson.M{"restaurantId": restaurantID}, menuOrder, 0)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAll[models.MenuCategory](documents)
+}
+
+// UpdateCategory changes a category's name, description and position. The restaurant is part of
+// the filter so a category can only be changed through the restaurant it belongs to.
+func (r *MemoryMenuCategoryRepository) UpdateCategory(ctx context.Context, category *models.MenuCategory) error {
+	category.UpdatedAt = time.Now()
+	filter := bson.M{"_id": category.ID, "restaurantId": category.RestaurantID}
+	update := bson.M{"$set": bson.M{
+		"name":        category.Name,
+		"description": category.Description,
+		"position":    category.Position,
+		"updatedAt":   category.UpdatedAt,
+	}}
+
+	matched, err := r.store.Update("menu_categories", filter, update)
+	if err != nil {
+		return err
+	}
+	if matched == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// DeleteCategory removes a category and takes it out of the menus listing it. Its items stay on
+// the menu, uncategorized.
+func (r *MemoryMenuCategoryRepository) DeleteCategory(ctx context.Context, restaurantID, categoryID primitive.ObjectID) error {
+	deleted, err := r.store.remove("menu_categories", bson.M{"_id": categoryID, "restaurantId": restaurantID}, false)
+	if err != nil {
+		return err
+	}
+	if len(deleted) == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	if _, err := r.store.Update("items", bson.M{"categoryId": categoryID}, bson.M{"$unset": bson.M{"categoryId": ""}}); err != nil {
+		return err
+	}
+	_, err = r.store.Update("menus", bson.M{"categoryIds": categoryID}, bson.M{"$pull": bson.M{"categoryIds": categoryID}})
+	return err
+}
+
+// GetRestaurantMenu returns a restaurant's categories with their items in display order, and the
+// items that are in no category
+func (r *MemoryMenuCategoryRepository) GetRestaurantMenu(ctx context.Context, restaurantID primitive.ObjectID) (*models.RestaurantMenu, error) {
+	categories, err := r.GetCategoriesByRestaurantID(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	menu := &models.RestaurantMenu{RestaurantID: restaurantID, Categories: []models.MenuSection{}, Uncategorized: []models.Item{}}
+	for _, category := range categories {
+		documents, err := r.store.find("items", bson.M{"restaurantId": restaurantID, "categoryId": category.ID}, menuOrder, 0)
+		if err != nil {
+			return nil, err
+		}
+		items, err := decodeAll[models.Item](documents)
+		if err != nil {
+			return nil, err
+		}
+		menu.Categories = append(menu.Categories, models.MenuSection{MenuCategory: category, Items: items})
+	}
+
+	filter := bson.M{"restaurantId": restaurantID, "categoryId": bson.M{"$exists": false}}
+	documents, err := r.store.find("items", filter, menuOrder, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(documents) > 0 {
+		if menu.Uncategorized, err = decodeAll[models.Item](documents); err != nil {
+			return nil, err
+		}
+	}
+
+	return menu, nil
+}
@@ -0,0 +1,154 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// menuOrder sorts categories and items for display; _id keeps equal positions in a stable order
+var menuOrder = bson.D{{Key: "position", Value: 1}, {Key: "_id", Value: 1}}
+
+// MenuCategoryRepository stores menu categories and builds the structured menu of a restaurant
+type MenuCategoryRepository interface {
+	CreateCategory(ctx context.Context, category *models.MenuCategory) error
+	GetCategoryByID(ctx context.Context, id primitive.ObjectID) (*models.MenuCategory, error)
+	GetCategoriesByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID) ([]models.MenuCategory, error)
+	UpdateCategory(ctx context.Context, category *models.MenuCategory) error
+	DeleteCategory(ctx context.Context, restaurantID, categoryID primitive.ObjectID) error
+	GetRestaurantMenu(ctx context.Context, restaurantID primitive.ObjectID) (*models.RestaurantMenu, error)
+}
+
+type MongoMenuCategoryRepository struct {
+	collection     *mongo.Collection
+	itemCollection *mongo.Collection
+	menuCollection *mongo.Collection
+}
+
+func NewMongoMenuCategoryRepository(db *mongo.Database) *MongoMenuCategoryRepository {
+	collection := db.Collection("menu_categories")
+	itemCollection := db.Collection("items")
+	menuCollection := db.Collection("menus")
+	return &MongoMenuCategoryRepository{collection: collection, itemCollection: itemCollection, menuCollection: menuCollection}
+}
+
+func (r *MongoMenuCategoryRepository) CreateCategory(ctx context.Context, category *models.MenuCategory) error {
+	category.ID = primitive.NewObjectID()
+	category.CreatedAt = time.Now()
+	category.UpdatedAt = category.CreatedAt
+
+	_, err := r.collection.InsertOne(ctx, category)
+	return err
+}
+
+func (r *MongoMenuCategoryRepository) GetCategoryByID(ctx context.Context, id primitive.ObjectID) (*models.MenuCategory, error) {
+	var category models.MenuCategory
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&category); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *MongoMenuCategoryRepository) GetCategoriesByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID) ([]models.MenuCategory, error) {
+	findOptions := options.Find().SetSort(menuOrder)
+	cursor, err := r.collection.Find(ctx, bson.M{"restaurantId": restaurantID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	categories := []models.MenuCategory{}
+	if err = cursor.All(ctx, &categories); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// UpdateCategory changes a category's name, description and position. The restaurant is part of
+// the filter so a category can only be changed through the restaurant it belongs to.
+func (r *MongoMenuCategoryRepository) UpdateCategory(ctx context.Context, category *models.MenuCategory) error {
+	category.UpdatedAt = time.Now()
+	filter := bson.M{"_id": category.ID, "restaurantId": category.RestaurantID}
+	update := bson.M{"$set": bson.M{
+		"name":        category.Name,
+		"description": category.Description,
+		"position":    category.Position,
+		"updatedAt":   category.UpdatedAt,
+	}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// DeleteCategory removes a category and takes it out of the menus listing it. Its items stay on
+// the menu, uncategorized.
+func (r *MongoMenuCategoryRepository) DeleteCategory(ctx context.Context, restaurantID, categoryID primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": categoryID, "restaurantId": restaurantID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	if _, err := r.itemCollection.UpdateMany(ctx, bson.M{"categoryId": categoryID}, bson.M{"$unset": bson.M{"categoryId": ""}}); err != nil {
+		return err
+	}
+	_, err = r.menuCollection.UpdateMany(ctx, bson.M{"categoryIds": categoryID}, bson.M{"$pull": bson.M{"categoryIds": categoryID}})
+	return err
+}
+
+// GetRestaurantMenu returns a restaurant's categories with their items joined in display order,
+// and the items that are in no category
+func (r *MongoMenuCategoryRepository) GetRestaurantMenu(ctx context.Context, restaurantID primitive.ObjectID) (*models.RestaurantMenu, error) {
+	matchStage := bson.D{{Key: "$match", Value: bson.D{{Key: "restaurantId", Value: restaurantID}}}}
+	sortStage := bson.D{{Key: "$sort", Value: menuOrder}}
+	lookupStage := bson.D{{Key: "$lookup", Value: bson.D{
+		{Key: "from", Value: "items"},
+		{Key: "let", Value: bson.D{{Key: "categoryId", Value: "$_id"}}},
+		{Key: "pipeline", Value: mongo.Pipeline{
+			{{Key: "$match", Value: bson.D{
+				{Key: "restaurantId", Value: restaurantID},
+				{Key: "$expr", Value: bson.D{{Key: "$eq", Value: bson.A{"$categoryId", "$$categoryId"}}}},
+			}}},
+			{{Key: "$sort", Value: menuOrder}},
+		}},
+		{Key: "as", Value: "items"},
+	}}}
+
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{matchStage, sortStage, lookupStage})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	menu := &models.RestaurantMenu{RestaurantID: restaurantID, Categories: []models.MenuSection{}, Uncategorized: []models.Item{}}
+	if err = cursor.All(ctx, &menu.Categories); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"restaurantId": restaurantID, "categoryId": bson.M{"$exists": false}}
+	itemCursor, err := r.itemCollection.Find(ctx, filter, options.Find().SetSort(menuOrder))
+	if err != nil {
+		return nil, err
+	}
+	defer itemCursor.Close(ctx)
+
+	if err = itemCursor.All(ctx, &menu.Uncategorized); err != nil {
+		return nil, err
+	}
+
+	return menu, nil
+}
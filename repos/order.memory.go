@@ -0,0 +1,62 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MemoryOrderRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryOrderRepository(store *MemoryStore) *MemoryOrderRepository {
+	return &MemoryOrderRepository{store: store}
+}
+
+func (r *MemoryOrderRepository) CreateOrder(ctx context.Context, order *models.Order) error {
+	order.ID = primitive.NewObjectID()
+	order.CreatedAt = time.Now()
+	order.UpdatedAt = order.CreatedAt
+
+	return r.store.Insert("orders", order)
+}
+
+func (r *MemoryOrderRepository) GetOrderByID(ctx context.Context, id primitive.ObjectID) (*models.Order, error) {
+	var order models.Order
+	if err := r.store.findOne("orders", bson.M{"_id": id}, nil, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetOrdersByRestaurantID returns one page of a restaurant's orders
+func (r *MemoryOrderRepository) GetOrdersByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID, opts models.QueryOptions) (*models.Page[models.Order], error) {
+	orders, err := r.store.find("orders", bson.M{"restaurantId": restaurantID}, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	return pageDocuments[models.Order](orders, opts)
+}
+
+// UpdateOrderStatus moves an order from one status to another, recording courierID as its courier
+// unless it is nil. The current status is part of the filter so two concurrent transitions can't
+// both succeed; it reports false when nothing matched.
+func (r *MemoryOrderRepository) UpdateOrderStatus(ctx context.Context, id primitive.ObjectID, from, to string, courierID primitive.ObjectID) (bool, error) {
+	now := time.Now()
+	filter := bson.M{"_id": id, "status": from}
+	set := bson.M{"status": to, "updatedAt": now}
+	if !courierID.IsZero() {
+		set["courierId"] = courierID
+	}
+	update := bson.M{
+		"$set":  set,
+		"$push": bson.M{"statusHistory": models.OrderStatusChange{Status: to, ChangedAt: now}},
+	}
+
+	matched, err := r.store.Update("orders", filter, update)
+	return matched > 0, err
+}
@@ -0,0 +1,73 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// OrderRepository stores orders and their status history
+type OrderRepository interface {
+	CreateOrder(ctx context.Context, order *models.Order) error
+	GetOrderByID(ctx context.Context, id primitive.ObjectID) (*models.Order, error)
+	GetOrdersByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID, opts models.QueryOptions) (*models.Page[models.Order], error)
+	UpdateOrderStatus(ctx context.Context, id primitive.ObjectID, from, to string, courierID primitive.ObjectID) (bool, error)
+}
+
+type MongoOrderRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoOrderRepository(db *mongo.Database) *MongoOrderRepository {
+	collection := db.Collection("orders")
+	return &MongoOrderRepository{collection: collection}
+}
+
+func (r *MongoOrderRepository) CreateOrder(ctx context.Context, order *models.Order) error {
+	order.ID = primitive.NewObjectID()
+	order.CreatedAt = time.Now()
+	order.UpdatedAt = order.CreatedAt
+
+	_, err := r.collection.InsertOne(ctx, order)
+	return err
+}
+
+func (r *MongoOrderRepository) GetOrderByID(ctx context.Context, id primitive.ObjectID) (*models.Order, error) {
+	var order models.Order
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetOrdersByRestaurantID returns one page of a restaurant's orders
+func (r *MongoOrderRepository) GetOrdersByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID, opts models.QueryOptions) (*models.Page[models.Order], error) {
+	matchStage := bson.D{{Key: "$match", Value: bson.D{{Key: "restaurantId", Value: restaurantID}}}}
+	return findPage[models.Order](ctx, r.collection, mongo.Pipeline{matchStage}, opts)
+}
+
+// UpdateOrderStatus moves an order from one status to another, recording courierID as its courier
+// unless it is nil. The current status is part of the filter so two concurrent transitions can't
+// both succeed; it reports false when nothing matched.
+func (r *MongoOrderRepository) UpdateOrderStatus(ctx context.Context, id primitive.ObjectID, from, to string, courierID primitive.ObjectID) (bool, error) {
+	now := time.Now()
+	filter := bson.M{"_id": id, "status": from}
+	set := bson.M{"status": to, "updatedAt": now}
+	if !courierID.IsZero() {
+		set["courierId"] = courierID
+	}
+	update := bson.M{
+		"$set":  set,
+		"$push": bson.M{"statusHistory": models.OrderStatusChange{Status: to, ChangedAt: now}},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
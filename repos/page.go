@@ -0,0 +1,224 @@
+package repos
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// pageQuery describes how one page of a listing is read: in the order of the sort, starting right
+// after a cursor's row (or ending right before it for a previous cursor), or at a page number
+// without one. Both the MongoDB and the in-memory repositories read pages with it.
+type pageQuery struct {
+	sort     *models.SortOptions
+	paging   *models.PaginationOptions
+	backward bool
+}
+
+func newPageQuery(opts models.QueryOptions) pageQuery {
+	sort := opts.Sort
+	if sort == nil || len(sort.Keys) == 0 {
+		sort = &models.SortOptions{Keys: []models.SortKey{{Field: "_id", Type: models.FilterObjectID, Order: 1}}}
+	}
+	cursor := opts.Pagination.Cursor
+	return pageQuery{sort: sort, paging: opts.Pagination, backward: cursor != nil && cursor.Before}
+}
+
+// keyset returns the filter selecting the rows past the cursor, or nil without a cursor
+func (q pageQuery) keyset() bson.D {
+	if q.paging.Cursor == nil {
+		return nil
+	}
+	return keysetFilter(q.sort.Keys, q.paging.Cursor.Values, q.backward)
+}
+
+// order returns the sort document rows are read in. Walking back from a cursor reads them in
+// reverse; buildPage puts them in order again.
+func (q pageQuery) order() bson.D {
+	order := sortDocument(q.sort)
+	if q.backward {
+		for i := range order {
+			order[i].Value = -q.sort.Keys[i].Order
+		}
+	}
+	return order
+}
+
+// skip returns how many rows come before the page when paging by number
+func (q pageQuery) skip() int64 {
+	if q.paging.Cursor != nil || q.paging.Page <= 1 {
+		return 0
+	}
+	return (q.paging.Page - 1) * q.paging.PageSize
+}
+
+// limit returns how many rows to read: one more than the page, which tells whether there is
+// another page
+func (q pageQuery) limit() int64 {
+	return q.paging.PageSize + 1
+}
+
+// findPage runs stages, which select the documents of a listing, and returns one page of them in
+// the order of opts.Sort. With a cursor the page starts right after the cursor's row (or ends right
+// before it for a previous cursor) using a range match on the sort keys, so deep pages cost no
+// more than the first one. Without one it falls back to page numbers and $skip. The page carries
+// the cursors of the neighbouring pages and, if asked for, the total.
+func findPage[T any](ctx context.Context, collection *mongo.Collection, stages mongo.Pipeline, opts models.QueryOptions) (*models.Page[T], error) {
+	query := newPageQuery(opts)
+
+	pipeline := append(mongo.Pipeline{}, stages...)
+	if keyset := query.keyset(); keyset != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: keyset}})
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$sort", Value: query.order()}})
+	if skip := query.skip(); skip > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$skip", Value: skip}})
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$limit", Value: query.limit()}})
+
+	results, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close(ctx)
+
+	var rows []bson.Raw
+	if err = results.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	return buildPage[T](query, rows, func() (int64, error) {
+		return countStages(ctx, collection, stages)
+	})
+}
+
+// buildPage makes a page out of the rows read for query, at most one more than the page size.
+// count is only called when the total was asked for.
+func buildPage[T any](query pageQuery, rows []bson.Raw, count func() (int64, error)) (*models.Page[T], error) {
+	paging, cursor, backward := query.paging, query.paging.Cursor, query.backward
+
+	more := int64(len(rows)) > paging.PageSize
+	if more {
+		rows = rows[:paging.PageSize]
+	}
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	documents := make([]T, len(rows))
+	for i, row := range rows {
+		if err := bson.Unmarshal(row, &documents[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	pagination := models.Pagination{PageSize: paging.PageSize}
+	if cursor == nil {
+		pagination.Page = paging.Page
+	}
+	hasNext, hasPrev := more, cursor != nil || paging.Page > 1
+	if backward {
+		hasNext, hasPrev = true, more
+	}
+	switch {
+	case len(rows) > 0:
+		if hasNext {
+			pagination.NextCursor = pageCursor(rows[len(rows)-1], query.sort, false)
+		}
+		if hasPrev {
+			pagination.PrevCursor = pageCursor(rows[0], query.sort, true)
+		}
+	case cursor != nil:
+		// Past either end; the cursor's own row is still the way back
+		back := models.Cursor{Values: cursor.Values, Before: !backward, Sort: cursor.Sort}
+		if backward {
+			pagination.NextCursor = back.Encode()
+		} else {
+			pagination.PrevCursor = back.Encode()
+		}
+	}
+
+	if paging.WithTotal {
+		total, err := count()
+		if err != nil {
+			return nil, err
+		}
+		pagination.SetTotal(total)
+	}
+
+	return models.NewPage(documents, pagination), nil
+}
+
+// keysetFilter matches the documents that come after values in the order of keys, or before them
+// when backward: those past the first key, or equal on it and past the second, and so on. Missing
+// fields sort before every value, as in MongoDB's own ordering.
+func keysetFilter(keys []models.SortKey, values []interface{}, backward bool) bson.D {
+	branches := bson.A{}
+	for i, key := range keys {
+		var past bson.D
+		value := values[i]
+		switch {
+		case (key.Order == 1) != backward:
+			if value == nil {
+				past = bson.D{{Key: "$ne", Value: nil}}
+			} else {
+				past = bson.D{{Key: "$gt", Value: value}}
+			}
+		case value != nil:
+			// Unlike $lt, this also matches documents without the field
+			past = bson.D{{Key: "$not", Value: bson.D{{Key: "$gte", Value: value}}}}
+		}
+
+		if past != nil {
+			branch := bson.D{}
+			for j := 0; j < i; j++ {
+				branch = append(branch, bson.E{Key: keys[j].Field, Value: values[j]})
+			}
+			branch = append(branch, bson.E{Key: key.Field, Value: past})
+			branches = append(branches, branch)
+		}
+	}
+	return bson.D{{Key: "$or", Value: branches}}
+}
+
+// pageCursor returns the cursor of a row: its values for the sort keys
+func pageCursor(row bson.Raw, sort *models.SortOptions, before bool) string {
+	values := make([]interface{}, len(sort.Keys))
+	for i, key := range sort.Keys {
+		value, err := row.LookupErr(strings.Split(key.Field, ".")...)
+		if err != nil {
+			continue
+		}
+		var decoded interface{}
+		if err := value.Unmarshal(&decoded); err == nil {
+			values[i] = decoded
+		}
+	}
+	cursor := models.Cursor{Values: values, Before: before, Sort: sort.Signature()}
+	return cursor.Encode()
+}
+
+// countStages counts the documents selected by stages
+func countStages(ctx context.Context, collection *mongo.Collection, stages mongo.Pipeline) (int64, error) {
+	pipeline := append(append(mongo.Pipeline{}, stages...), bson.D{{Key: "$count", Value: "total"}})
+	results, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	defer results.Close(ctx)
+
+	var count struct {
+		Total int64 `bson:"total"`
+	}
+	if results.Next(ctx) {
+		if err := results.Decode(&count); err != nil {
+			return 0, err
+		}
+	}
+	return count.Total, results.Err()
+}
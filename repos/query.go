@@ -0,0 +1,19 @@
+package repos
+
+import (
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// sortDocument turns sort options into a sort document, keeping the order of the keys. Without
+// options it sorts by _id so pages stay stable.
+func sortDocument(sort *models.SortOptions) bson.D {
+	if sort == nil || len(sort.Keys) == 0 {
+		return bson.D{{Key: "_id", Value: 1}}
+	}
+	document := make(bson.D, 0, len(sort.Keys))
+	for _, key := range sort.Keys {
+		document = append(document, bson.E{Key: key.Field, Value: key.Order})
+	}
+	return document
+}
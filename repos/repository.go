@@ -0,0 +1,50 @@
+package repos
+
+import "go.mongodb.org/mongo-driver/mongo"
+
+// Repositories holds one implementation of every repository, so the rest of the app can be wired
+// the same way whether it runs against MongoDB or in memory
+type Repositories struct {
+	Users       UserRepository
+	Restaurants RestaurantRepository
+	Items       ItemRepository
+	Reviews     ReviewRepository
+	Orders      OrderRepository
+	Carts       CartRepository
+	Audit       AuditRepository
+	Zones       DeliveryZoneRepository
+	Categories  MenuCategoryRepository
+	Menus       MenuRepository
+}
+
+// NewMongoRepositories returns the repositories backed by a MongoDB database
+func NewMongoRepositories(db *mongo.Database) Repositories {
+	return Repositories{
+		Users:       NewMongoUserRepository(db),
+		Restaurants: NewMongoRestaurantRepository(db),
+		Items:       NewMongoItemRepository(db),
+		Reviews:     NewMongoReviewRepository(db),
+		Orders:      NewMongoOrderRepository(db),
+		Carts:       NewMongoCartRepository(db),
+		Audit:       NewMongoAuditRepository(db),
+		Zones:       NewMongoDeliveryZoneRepository(db),
+		Categories:  NewMongoMenuCategoryRepository(db),
+		Menus:       NewMongoMenuRepository(db),
+	}
+}
+
+// NewMemoryRepositories returns repositories that keep their data in store
+func NewMemoryRepositories(store *MemoryStore) Repositories {
+	return Repositories{
+		Users:       NewMemoryUserRepository(store),
+		Restaurants: NewMemoryRestaurantRepository(store),
+		Items:       NewMemoryItemRepository(store),
+		Reviews:     NewMemoryReviewRepository(store),
+		Orders:      NewMemoryOrderRepository(store),
+		Carts:       NewMemoryCartRepository(store),
+		Audit:       NewMemoryAuditRepository(store),
+		Zones:       NewMemoryDeliveryZoneRepository(store),
+		Categories:  NewMemoryMenuCategoryRepository(store),
+		Menus:       NewMemoryMenuRepository(store),
+	}
+}
@@ -0,0 +1,275 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MemoryRestaurantRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryRestaurantRepository(store *MemoryStore) *MemoryRestaurantRepository {
+	return &MemoryRestaurantRepository{store: store}
+}
+
+func (r *MemoryRestaurantRepository) CreateRestaurant(ctx context.Context, restaurant *models.Restaurant) error {
+	if restaurant.ID.IsZero() {
+		restaurant.ID = primitive.NewObjectID()
+	}
+	return r.store.Insert("restaurants", restaurant)
+}
+
+// GetRestaurantByID returns a restaurant with a preview of its items. itemFilter, if not nil,
+// narrows the items that are joined.
+func (r *MemoryRestaurantRepository) GetRestaurantByID(ctx context.Context, id primitive.ObjectID, itemFilter bson.M) (*models.Restaurant, error) {
+	var restaurant models.Restaurant
+	if err := r.store.findOne("restaurants", bson.M{"_id": id}, nil, &restaurant); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"restaurantId": id}
+	if itemFilter != nil {
+		filter = bson.M{"$and": bson.A{itemFilter, filter}}
+	}
+	documents, err := r.store.find("items", filter, menuOrder, restaurantItemPreviewLimit)
+	if err != nil {
+		return nil, err
+	}
+	if restaurant.Items, err = decodeAll[models.Item](documents); err != nil {
+		return nil, err
+	}
+
+	return &restaurant, nil
+}
+
+// GetRestaurantOwnerID returns only the owner of a restaurant, without joining its items
+func (r *MemoryRestaurantRepository) GetRestaurantOwnerID(ctx context.Context, id primitive.ObjectID) (primitive.ObjectID, error) {
+	var restaurant models.Restaurant
+	if err := r.store.findOne("restaurants", bson.M{"_id": id}, nil, &restaurant); err != nil {
+		return primitive.NilObjectID, err
+	}
+	return restaurant.OwnerID, nil
+}
+
+// GetRestaurantHours returns a restaurant without its items, for its timezone, operating hours
+// and hours exceptions
+func (r *MemoryRestaurantRepository) GetRestaurantHours(ctx context.Context, id primitive.ObjectID) (*models.Restaurant, error) {
+	var restaurant models.Restaurant
+	if err := r.store.findOne("restaurants", bson.M{"_id": id}, nil, &restaurant); err != nil {
+		return nil, err
+	}
+	return &restaurant, nil
+}
+
+// GetTimezones returns the timezone of every restaurant matching filter, keyed by restaurant
+func (r *MemoryRestaurantRepository) GetTimezones(ctx context.Context, filter bson.M) (map[primitive.ObjectID]string, error) {
+	restaurants, err := r.findRestaurants(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	timezones := make(map[primitive.ObjectID]string, len(restaurants))
+	for _, restaurant := range restaurants {
+		timezones[restaurant.ID] = restaurant.Timezone
+	}
+	return timezones, nil
+}
+
+func (r *MemoryRestaurantRepository) UpdateRestaurant(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	matched, err := r.store.Update("restaurants", bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if matched == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *MemoryRestaurantRepository) DeleteRestaurant(ctx context.Context, id primitive.ObjectID) error {
+	removed, err := r.store.remove("restaurants", bson.M{"_id": id}, false)
+	if err != nil {
+		return err
+	}
+	if len(removed) == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// GetAllRestaurants returns one page of the restaurants matching filter
+func (r *MemoryRestaurantRepository) GetAllRestaurants(ctx context.Context, filter bson.M, opts models.QueryOptions) (*models.Page[models.Restaurant], error) {
+	documents, err := r.store.find("restaurants", filter, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	return pageDocuments[models.Restaurant](documents, opts)
+}
+
+// FindNearby returns restaurants within query.RadiusMeters of the query point with their distance
+// in meters, in the order of opts.Sort
+func (r *MemoryRestaurantRepository) FindNearby(ctx context.Context, query models.NearbyQuery, filter bson.M, opts models.QueryOptions) (*models.Page[models.Restaurant], error) {
+	filter = bson.M{"$and": bson.A{filter, bson.M{"location": query.RadiusFilter()}}}
+	documents, err := r.store.find("restaurants", filter, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, doc := range documents {
+		if location, ok := doc["location"].(bson.M); ok {
+			if position := positionOf(location["coordinates"]); position != nil {
+				doc["distance"] = query.DistanceMeters(position)
+			}
+		}
+	}
+	return pageDocuments[models.Restaurant](documents, opts)
+}
+
+// SearchRestaurants runs a text search over restaurant names and descriptions, best matches first.
+// With near, only restaurants within its radius are searched.
+func (r *MemoryRestaurantRepository) SearchRestaurants(ctx context.Context, text string, near *models.NearbyQuery, limit int64) ([]models.ScoredRestaurant, error) {
+	filter := bson.M{}
+	if near != nil {
+		filter["location"] = near.RadiusFilter()
+	}
+
+	documents, err := r.store.textSearch("restaurants", text, filter, limit)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAll[models.ScoredRestaurant](documents)
+}
+
+// GetRestaurantIDs returns the IDs of the restaurants matching filter
+func (r *MemoryRestaurantRepository) GetRestaurantIDs(ctx context.Context, filter bson.M) ([]primitive.ObjectID, error) {
+	values, err := r.store.distinct("restaurants", "_id", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(primitive.ObjectID); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// GetRestaurantNames returns every restaurant, for their IDs and names
+func (r *MemoryRestaurantRepository) GetRestaurantNames(ctx context.Context) ([]models.Restaurant, error) {
+	return r.findRestaurants(bson.M{})
+}
+
+// GetRestaurantsByIDs returns the restaurants with the given IDs, without their items
+func (r *MemoryRestaurantRepository) GetRestaurantsByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Restaurant, error) {
+	return r.findRestaurants(bson.M{"_id": bson.M{"$in": ids}})
+}
+
+// ReplaceRatingStats overwrites the stored rating aggregates with stats. Restaurants that are not in
+// stats have no reviews and are reset to zero.
+func (r *MemoryRestaurantRepository) ReplaceRatingStats(ctx context.Context, stats []models.RatingStats) error {
+	reviewed := make([]primitive.ObjectID, len(stats))
+	for i, stat := range stats {
+		reviewed[i] = stat.RestaurantID
+		_, err := r.store.Update("restaurants", bson.M{"_id": stat.RestaurantID}, bson.M{"$set": bson.M{
+			"rating":          stat.Average,
+			"ratingCount":     stat.Count,
+			"ratingTotal":     stat.Total,
+			"ratingHistogram": stat.Histogram,
+		}})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := r.store.Update("restaurants", bson.M{"_id": bson.M{"$nin": reviewed}}, bson.M{"$set": bson.M{
+		"rating":          0.0,
+		"ratingCount":     int64(0),
+		"ratingTotal":     int64(0),
+		"ratingHistogram": models.EmptyRatingHistogram(),
+	}})
+	return err
+}
+
+// OpenAtFilter builds a filter matching restaurants that are open at t
+func (r *MemoryRestaurantRepository) OpenAtFilter(ctx context.Context, t time.Time) (bson.M, error) {
+	timezones, err := r.store.distinct("restaurants", "timezone", bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	return openAtFilter(t, timezones), nil
+}
+
+// AddHoursException appends an exception to a restaurant's hours, dropping exceptions that are over
+func (r *MemoryRestaurantRepository) AddHoursException(ctx context.Context, restaurantID primitive.ObjectID, exception *models.HoursException) error {
+	exception.ID = primitive.NewObjectID()
+	exception.CreatedAt = time.Now()
+
+	prune := bson.M{"$pull": bson.M{"hoursExceptions": bson.M{"endsAt": bson.M{"$lte": exception.CreatedAt}}}}
+	if _, err := r.store.Update("restaurants", bson.M{"_id": restaurantID}, prune); err != nil {
+		return err
+	}
+
+	matched, err := r.store.Update("restaurants", bson.M{"_id": restaurantID}, bson.M{"$push": bson.M{"hoursExceptions": exception}})
+	if err != nil {
+		return err
+	}
+	if matched == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// UpdateHoursException replaces one of a restaurant's exceptions
+func (r *MemoryRestaurantRepository) UpdateHoursException(ctx context.Context, restaurantID primitive.ObjectID, exception *models.HoursException) error {
+	replacement, err := toDocument(exception)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": restaurantID, "hoursExceptions._id": exception.ID}
+	matched, err := r.store.modify("restaurants", filter, false, func(restaurant bson.M) (bson.M, error) {
+		exceptions, _ := restaurant["hoursExceptions"].(bson.A)
+		for i, existing := range exceptions {
+			if doc, ok := existing.(bson.M); ok && compareValues(doc["_id"], exception.ID) == 0 {
+				exceptions[i] = replacement
+				break
+			}
+		}
+		return restaurant, nil
+	})
+	if err != nil {
+		return err
+	}
+	if matched == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *MemoryRestaurantRepository) DeleteHoursException(ctx context.Context, restaurantID, exceptionID primitive.ObjectID) error {
+	filter := bson.M{"_id": restaurantID, "hoursExceptions._id": exceptionID}
+	matched, err := r.store.Update("restaurants", filter, bson.M{"$pull": bson.M{"hoursExceptions": bson.M{"_id": exceptionID}}})
+	if err != nil {
+		return err
+	}
+	if matched == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *MemoryRestaurantRepository) findRestaurants(filter bson.M) ([]models.Restaurant, error) {
+	documents, err := r.store.find("restaurants", filter, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAll[models.Restaurant](documents)
+}
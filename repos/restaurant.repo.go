@@ -2,6 +2,7 @@ package repos
 
 import (
 	"context"
+	"time"
 
 	"github.com/aldiandyaIrsyad/uber-eats/models"
 	"go.mongodb.org/mongo-driver/bson"
@@ -10,31 +11,74 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-type RestaurantRepository struct {
+// restaurantItemPreviewLimit is how many items GetRestaurantByID embeds
+const restaurantItemPreviewLimit = 10
+
+// RestaurantRepository stores restaurants with their hours and rating aggregates
+type RestaurantRepository interface {
+	CreateRestaurant(ctx context.Context, restaurant *models.Restaurant) error
+	GetRestaurantByID(ctx context.Context, id primitive.ObjectID, itemFilter bson.M) (*models.Restaurant, error)
+	GetRestaurantOwnerID(ctx context.Context, id primitive.ObjectID) (primitive.ObjectID, error)
+	GetRestaurantHours(ctx context.Context, id primitive.ObjectID) (*models.Restaurant, error)
+	GetTimezones(ctx context.Context, filter bson.M) (map[primitive.ObjectID]string, error)
+	UpdateRestaurant(ctx context.Context, id primitive.ObjectID, update bson.M) error
+	DeleteRestaurant(ctx context.Context, id primitive.ObjectID) error
+	GetAllRestaurants(ctx context.Context, filter bson.M, opts models.QueryOptions) (*models.Page[models.Restaurant], error)
+	FindNearby(ctx context.Context, query models.NearbyQuery, filter bson.M, opts models.QueryOptions) (*models.Page[models.Restaurant], error)
+	SearchRestaurants(ctx context.Context, text string, near *models.NearbyQuery, limit int64) ([]models.ScoredRestaurant, error)
+	GetRestaurantIDs(ctx context.Context, filter bson.M) ([]primitive.ObjectID, error)
+	GetRestaurantNames(ctx context.Context) ([]models.Restaurant, error)
+	GetRestaurantsByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Restaurant, error)
+	ReplaceRatingStats(ctx context.Context, stats []models.RatingStats) error
+	OpenAtFilter(ctx context.Context, t time.Time) (bson.M, error)
+	AddHoursException(ctx context.Context, restaurantID primitive.ObjectID, exception *models.HoursException) error
+	UpdateHoursException(ctx context.Context, restaurantID primitive.ObjectID, exception *models.HoursException) error
+	DeleteHoursException(ctx context.Context, restaurantID, exceptionID primitive.ObjectID) error
+}
+
+type MongoRestaurantRepository struct {
 	collection *mongo.Collection
 }
 
-func NewRestaurantRepository(client *mongo.Client) *RestaurantRepository {
-	collection := client.Database("testing").Collection("restaurants")
-	return &RestaurantRepository{collection: collection}
+func NewMongoRestaurantRepository(db *mongo.Database) *MongoRestaurantRepository {
+	collection := db.Collection("restaurants")
+	return &MongoRestaurantRepository{collection: collection}
 }
 
-func (r *RestaurantRepository) CreateRestaurant(ctx context.Context, restaurant *models.Restaurant) error {
-	_, err := r.collection.InsertOne(ctx, restaurant)
-	return err
+func (r *MongoRestaurantRepository) CreateRestaurant(ctx context.Context, restaurant *models.Restaurant) error {
+	result, err := r.collection.InsertOne(ctx, restaurant)
+	if err != nil {
+		return err
+	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		restaurant.ID = id
+	}
+	return nil
 }
 
-func (r *RestaurantRepository) GetRestaurantByID(ctx context.Context, id primitive.ObjectID) (*models.Restaurant, error) {
+// GetRestaurantByID returns a restaurant with a preview of its items. itemFilter, if not nil,
+// narrows the items that are joined.
+func (r *MongoRestaurantRepository) GetRestaurantByID(ctx context.Context, id primitive.ObjectID, itemFilter bson.M) (*models.Restaurant, error) {
 	matchStage := bson.D{{Key: "$match", Value: bson.D{{Key: "_id", Value: id}}}}
+	itemPipeline := mongo.Pipeline{}
+	if itemFilter != nil {
+		itemPipeline = append(itemPipeline, bson.D{{Key: "$match", Value: itemFilter}})
+	}
+	// The limit belongs inside the join: it caps the embedded items, not the matched restaurants.
+	// The full menu is served by the menu endpoint.
+	itemPipeline = append(itemPipeline,
+		bson.D{{Key: "$sort", Value: menuOrder}},
+		bson.D{{Key: "$limit", Value: restaurantItemPreviewLimit}},
+	)
 	lookupStage := bson.D{{Key: "$lookup", Value: bson.D{
 		{Key: "from", Value: "items"},
 		{Key: "localField", Value: "_id"},
 		{Key: "foreignField", Value: "restaurantId"},
+		{Key: "pipeline", Value: itemPipeline},
 		{Key: "as", Value: "items"},
 	}}}
-	limitStage := bson.D{{Key: "$limit", Value: 10}}
 
-	pipeline := mongo.Pipeline{matchStage, lookupStage, limitStage}
+	pipeline := mongo.Pipeline{matchStage, lookupStage}
 	cursor, err := r.collection.Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
@@ -53,21 +97,31 @@ func (r *RestaurantRepository) GetRestaurantByID(ctx context.Context, id primiti
 	return &restaurants[0], nil
 }
 
-func (r *RestaurantRepository) UpdateRestaurant(ctx context.Context, id primitive.ObjectID, update bson.M) error {
-	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
-	return err
+// GetRestaurantOwnerID returns only the owner of a restaurant, without joining its items
+func (r *MongoRestaurantRepository) GetRestaurantOwnerID(ctx context.Context, id primitive.ObjectID) (primitive.ObjectID, error) {
+	var result struct {
+		OwnerID primitive.ObjectID `bson:"ownerId"`
+	}
+	findOptions := options.FindOne().SetProjection(bson.M{"ownerId": 1})
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}, findOptions).Decode(&result); err != nil {
+		return primitive.NilObjectID, err
+	}
+	return result.OwnerID, nil
 }
 
-func (r *RestaurantRepository) DeleteRestaurant(ctx context.Context, id primitive.ObjectID) error {
-	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
-	return err
+// GetRestaurantHours returns a restaurant with only its timezone, operating hours and hours exceptions
+func (r *MongoRestaurantRepository) GetRestaurantHours(ctx context.Context, id primitive.ObjectID) (*models.Restaurant, error) {
+	var restaurant models.Restaurant
+	findOptions := options.FindOne().SetProjection(bson.M{"timezone": 1, "operatingHours": 1, "openIntervals": 1, "hoursExceptions": 1})
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}, findOptions).Decode(&restaurant); err != nil {
+		return nil, err
+	}
+	return &restaurant, nil
 }
 
-func (r *RestaurantRepository) GetAllRestaurants(ctx context.Context, filter bson.M, pagination *models.Pagination) ([]models.Restaurant, error) {
-	findOptions := options.Find()
-	findOptions.SetSkip(pagination.GetSkip())
-	findOptions.SetLimit(pagination.GetLimit())
-
+// GetTimezones returns the timezone of every restaurant matching filter, keyed by restaurant
+func (r *MongoRestaurantRepository) GetTimezones(ctx context.Context, filter bson.M) (map[primitive.ObjectID]string, error) {
+	findOptions := options.Find().SetProjection(bson.M{"timezone": 1})
 	cursor, err := r.collection.Find(ctx, filter, findOptions)
 	if err != nil {
 		return nil, err
@@ -75,13 +129,265 @@ func (r *RestaurantRepository) GetAllRestaurants(ctx context.Context, filter bso
 	defer cursor.Close(ctx)
 
 	var restaurants []models.Restaurant
-	for cursor.Next(ctx) {
-		var restaurant models.Restaurant
-		if err := cursor.Decode(&restaurant); err != nil {
-			return nil, err
+	if err = cursor.All(ctx, &restaurants); err != nil {
+		return nil, err
+	}
+
+	timezones := make(map[primitive.ObjectID]string, len(restaurants))
+	for _, restaurant := range restaurants {
+		timezones[restaurant.ID] = restaurant.Timezone
+	}
+	return timezones, nil
+}
+
+func (r *MongoRestaurantRepository) UpdateRestaurant(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *MongoRestaurantRepository) DeleteRestaurant(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// GetAllRestaurants returns one page of the restaurants matching filter
+func (r *MongoRestaurantRepository) GetAllRestaurants(ctx context.Context, filter bson.M, opts models.QueryOptions) (*models.Page[models.Restaurant], error) {
+	matchStage := bson.D{{Key: "$match", Value: filter}}
+	return findPage[models.Restaurant](ctx, r.collection, mongo.Pipeline{matchStage}, opts)
+}
+
+// FindNearby returns restaurants within query.RadiusMeters of the query point with their distance
+// in meters, in the order of opts.Sort. It runs $geoNear against the location 2dsphere index;
+// filter narrows the candidates in the same stage so it combines with the other listing filters.
+func (r *MongoRestaurantRepository) FindNearby(ctx context.Context, query models.NearbyQuery, filter bson.M, opts models.QueryOptions) (*models.Page[models.Restaurant], error) {
+	geoNearStage := bson.D{{Key: "$geoNear", Value: bson.D{
+		{Key: "near", Value: bson.D{
+			{Key: "type", Value: "Point"},
+			{Key: "coordinates", Value: bson.A{query.Longitude, query.Latitude}},
+		}},
+		{Key: "key", Value: "location"},
+		{Key: "distanceField", Value: "distance"},
+		{Key: "maxDistance", Value: query.RadiusMeters},
+		{Key: "spherical", Value: true},
+		{Key: "query", Value: filter},
+	}}}
+	return findPage[models.Restaurant](ctx, r.collection, mongo.Pipeline{geoNearStage}, opts)
+}
+
+// SearchRestaurants runs a text search over restaurant names and descriptions, best matches first.
+// With near, only restaurants within its radius are searched.
+func (r *MongoRestaurantRepository) SearchRestaurants(ctx context.Context, text string, near *models.NearbyQuery, limit int64) ([]models.ScoredRestaurant, error) {
+	filter := bson.M{"$text": bson.M{"$search": text}}
+	if near != nil {
+		filter["location"] = near.RadiusFilter()
+	}
+	score := bson.M{"$meta": "textScore"}
+	findOptions := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}}).
+		SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var restaurants []models.ScoredRestaurant
+	if err = cursor.All(ctx, &restaurants); err != nil {
+		return nil, err
+	}
+	return restaurants, nil
+}
+
+// GetRestaurantIDs returns the IDs of the restaurants matching filter
+func (r *MongoRestaurantRepository) GetRestaurantIDs(ctx context.Context, filter bson.M) ([]primitive.ObjectID, error) {
+	values, err := r.collection.Distinct(ctx, "_id", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(primitive.ObjectID); ok {
+			ids = append(ids, id)
 		}
-		restaurants = append(restaurants, restaurant)
 	}
+	return ids, nil
+}
 
+// GetRestaurantNames returns the ID and name of every restaurant
+func (r *MongoRestaurantRepository) GetRestaurantNames(ctx context.Context) ([]models.Restaurant, error) {
+	findOptions := options.Find().SetProjection(bson.M{"name": 1})
+	cursor, err := r.collection.Find(ctx, bson.M{}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var restaurants []models.Restaurant
+	if err = cursor.All(ctx, &restaurants); err != nil {
+		return nil, err
+	}
 	return restaurants, nil
 }
+
+// GetRestaurantsByIDs returns the restaurants with the given IDs, without their items
+func (r *MongoRestaurantRepository) GetRestaurantsByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Restaurant, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var restaurants []models.Restaurant
+	if err = cursor.All(ctx, &restaurants); err != nil {
+		return nil, err
+	}
+	return restaurants, nil
+}
+
+// ReplaceRatingStats overwrites the stored rating aggregates with stats. Restaurants that are not in
+// stats have no reviews and are reset to zero.
+func (r *MongoRestaurantRepository) ReplaceRatingStats(ctx context.Context, stats []models.RatingStats) error {
+	writes := make([]mongo.WriteModel, 0, len(stats)+1)
+	reviewed := make([]primitive.ObjectID, len(stats))
+	for i, stat := range stats {
+		reviewed[i] = stat.RestaurantID
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": stat.RestaurantID}).
+			SetUpdate(bson.M{"$set": bson.M{
+				"rating":          stat.Average,
+				"ratingCount":     stat.Count,
+				"ratingTotal":     stat.Total,
+				"ratingHistogram": stat.Histogram,
+			}}))
+	}
+	writes = append(writes, mongo.NewUpdateManyModel().
+		SetFilter(bson.M{"_id": bson.M{"$nin": reviewed}}).
+		SetUpdate(bson.M{"$set": bson.M{
+			"rating":          0,
+			"ratingCount":     0,
+			"ratingTotal":     0,
+			"ratingHistogram": models.EmptyRatingHistogram(),
+		}}))
+
+	_, err := r.collection.BulkWrite(ctx, writes)
+	return err
+}
+
+// OpenAtFilter builds a filter matching restaurants that are open at t
+func (r *MongoRestaurantRepository) OpenAtFilter(ctx context.Context, t time.Time) (bson.M, error) {
+	timezones, err := r.collection.Distinct(ctx, "timezone", bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	return openAtFilter(t, timezones), nil
+}
+
+// openAtFilter builds a filter matching restaurants that are open at t. Each restaurant's open
+// intervals are in its own local time, so the weekly hours get one clause per stored timezone.
+// Hours exceptions covering t take precedence: closures and pauses exclude the restaurant, and
+// special hours replace the weekly hours.
+func openAtFilter(t time.Time, timezones []interface{}) bson.M {
+	openAt := func(loc *time.Location) bson.M {
+		minute := models.MinuteOfWeek(t.In(loc))
+		return bson.M{"$elemMatch": bson.M{"start": bson.M{"$lte": minute}, "end": bson.M{"$gt": minute}}}
+	}
+
+	// Restaurants without a timezone use UTC
+	weekly := bson.A{bson.M{
+		"timezone":      bson.M{"$in": bson.A{nil, "", models.DefaultTimezone}},
+		"openIntervals": openAt(time.UTC),
+	}}
+	for _, value := range timezones {
+		timezone, ok := value.(string)
+		if !ok || timezone == "" || timezone == models.DefaultTimezone {
+			continue
+		}
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			continue
+		}
+		weekly = append(weekly, bson.M{"timezone": timezone, "openIntervals": openAt(loc)})
+	}
+
+	activeException := func(types ...string) bson.M {
+		return bson.M{
+			"type":     bson.M{"$in": types},
+			"startsAt": bson.M{"$lte": t},
+			"endsAt":   bson.M{"$gt": t},
+		}
+	}
+	special := activeException(models.HoursExceptionSpecialHours)
+	specialOpen := activeException(models.HoursExceptionSpecialHours)
+	specialOpen["opens"] = bson.M{"$elemMatch": bson.M{"start": bson.M{"$lte": t}, "end": bson.M{"$gt": t}}}
+
+	return bson.M{
+		"hoursExceptions": bson.M{"$not": bson.M{"$elemMatch": activeException(models.HoursExceptionClosed, models.HoursExceptionPause)}},
+		"$or": bson.A{
+			bson.M{"hoursExceptions": bson.M{"$elemMatch": specialOpen}},
+			bson.M{
+				"hoursExceptions": bson.M{"$not": bson.M{"$elemMatch": special}},
+				"$or":             weekly,
+			},
+		},
+	}
+}
+
+// AddHoursException appends an exception to a restaurant's hours, dropping exceptions that are over
+func (r *MongoRestaurantRepository) AddHoursException(ctx context.Context, restaurantID primitive.ObjectID, exception *models.HoursException) error {
+	exception.ID = primitive.NewObjectID()
+	exception.CreatedAt = time.Now()
+
+	prune := bson.M{"$pull": bson.M{"hoursExceptions": bson.M{"endsAt": bson.M{"$lte": exception.CreatedAt}}}}
+	if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": restaurantID}, prune); err != nil {
+		return err
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": restaurantID}, bson.M{"$push": bson.M{"hoursExceptions": exception}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// UpdateHoursException replaces one of a restaurant's exceptions
+func (r *MongoRestaurantRepository) UpdateHoursException(ctx context.Context, restaurantID primitive.ObjectID, exception *models.HoursException) error {
+	filter := bson.M{"_id": restaurantID, "hoursExceptions._id": exception.ID}
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"hoursExceptions.$": exception}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *MongoRestaurantRepository) DeleteHoursException(ctx context.Context, restaurantID, exceptionID primitive.ObjectID) error {
+	filter := bson.M{"_id": restaurantID, "hoursExceptions._id": exceptionID}
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$pull": bson.M{"hoursExceptions": bson.M{"_id": exceptionID}}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package repos
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MemoryReviewRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryReviewRepository(store *MemoryStore) *MemoryReviewRepository {
+	return &MemoryReviewRepository{store: store}
+}
+
+func (r *MemoryReviewRepository) CreateReview(ctx context.Context, review *models.Review) error {
+	review.ID = primitive.NewObjectID()
+	review.CreatedAt = time.Now()
+	review.UpdatedAt = time.Now()
+
+	if err := r.store.Insert("reviews", review); err != nil {
+		return err
+	}
+
+	if err := r.recomputeRating(review.RestaurantID); err != nil {
+		logRecomputeError(review.RestaurantID, err)
+	}
+	return nil
+}
+
+func (r *MemoryReviewRepository) GetReviewByID(ctx context.Context, id primitive.ObjectID) (*models.Review, error) {
+	var review models.Review
+	if err := r.store.findOne("reviews", bson.M{"_id": id}, nil, &review); err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// UpdateReview changes a review's rating and comment and recomputes the restaurant's aggregates.
+// It returns the updated review.
+func (r *MemoryReviewRepository) UpdateReview(ctx context.Context, id primitive.ObjectID, rating int, comment string) (*models.Review, error) {
+	var after models.Review
+	matched, err := modifyAs(r.store, "reviews", bson.M{"_id": id}, false, func(review *models.Review) error {
+		review.Rating = rating
+		review.Comment = comment
+		review.UpdatedAt = time.Now()
+		after = *review
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if matched == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	if err := r.recomputeRating(after.RestaurantID); err != nil {
+		logRecomputeError(after.RestaurantID, err)
+	}
+	return &after, nil
+}
+
+func (r *MemoryReviewRepository) DeleteReview(ctx context.Context, id primitive.ObjectID) error {
+	removed, err := r.store.remove("reviews", bson.M{"_id": id}, false)
+	if err != nil {
+		return err
+	}
+	if len(removed) == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	var deleted models.Review
+	if err := fromDocument(removed[0], &deleted); err != nil {
+		return err
+	}
+	if err := r.recomputeRating(deleted.RestaurantID); err != nil {
+		logRecomputeError(deleted.RestaurantID, err)
+	}
+	return nil
+}
+
+// GetReviewsByRestaurantID returns one page of a restaurant's reviews
+func (r *MemoryReviewRepository) GetReviewsByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID, opts models.QueryOptions) (*models.Page[models.Review], error) {
+	reviews, err := r.store.find("reviews", bson.M{"restaurantId": restaurantID}, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	return pageDocuments[models.Review](reviews, opts)
+}
+
+func (r *MemoryReviewRepository) GetAverageRatingByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID) (float64, error) {
+	stats, err := r.ratingStats(bson.M{"restaurantId": restaurantID})
+	if err != nil || len(stats) == 0 {
+		return 0, err
+	}
+	return stats[0].Average, nil
+}
+
+// GetRatingStats computes the rating aggregates of every reviewed restaurant from its reviews
+func (r *MemoryReviewRepository) GetRatingStats(ctx context.Context) ([]models.RatingStats, error) {
+	return r.ratingStats(bson.M{})
+}
+
+// GetRatingStatsByRestaurantIDs computes the rating aggregates of a set of restaurants.
+// Restaurants without reviews are absent from the result.
+func (r *MemoryReviewRepository) GetRatingStatsByRestaurantIDs(ctx context.Context, restaurantIDs []primitive.ObjectID) (map[primitive.ObjectID]models.RatingStats, error) {
+	stats, err := r.ratingStats(bson.M{"restaurantId": bson.M{"$in": restaurantIDs}})
+	if err != nil {
+		return nil, err
+	}
+
+	byRestaurant := make(map[primitive.ObjectID]models.RatingStats, len(stats))
+	for _, stat := range stats {
+		byRestaurant[stat.RestaurantID] = stat
+	}
+	return byRestaurant, nil
+}
+
+// ratingStats groups the reviews matching filter by restaurant into the same aggregates as the
+// $group of the MongoDB repository
+func (r *MemoryReviewRepository) ratingStats(filter bson.M) ([]models.RatingStats, error) {
+	documents, err := r.store.find("reviews", filter, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	reviews, err := decodeAll[models.Review](documents)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []models.RatingStats
+	index := make(map[primitive.ObjectID]int)
+	for _, review := range reviews {
+		i, ok := index[review.RestaurantID]
+		if !ok {
+			i = len(stats)
+			index[review.RestaurantID] = i
+			stats = append(stats, models.RatingStats{RestaurantID: review.RestaurantID, Histogram: models.EmptyRatingHistogram()})
+		}
+		stat := &stats[i]
+		stat.Count++
+		stat.Total += int64(review.Rating)
+		stat.Histogram[strconv.Itoa(review.Rating)]++
+		stat.Average = float64(stat.Total) / float64(stat.Count)
+	}
+	return stats, nil
+}
+
+// recomputeRating rebuilds a restaurant's stored rating aggregates from its reviews, like the
+// $merge of the MongoDB repository
+func (r *MemoryReviewRepository) recomputeRating(restaurantID primitive.ObjectID) error {
+	stats, err := r.ratingStats(bson.M{"restaurantId": restaurantID})
+	if err != nil {
+		return err
+	}
+	stat := models.RatingStats{Histogram: models.EmptyRatingHistogram()}
+	if len(stats) > 0 {
+		stat = stats[0]
+	}
+
+	_, err = r.store.Update("restaurants", bson.M{"_id": restaurantID}, bson.M{"$set": bson.M{
+		"rating":          stat.Average,
+		"ratingCount":     stat.Count,
+		"ratingTotal":     stat.Total,
+		"ratingHistogram": stat.Histogram,
+	}})
+	return err
+}
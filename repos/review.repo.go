@@ -2,53 +2,102 @@ package repos
 
 import (
 	"context"
+	"log"
+	"strconv"
 	"time"
 
 	"github.com/aldiandyaIrsyad/uber-eats/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-type ReviewRepository struct {
-	collection *mongo.Collection
+// ReviewRepository stores reviews and keeps the rating aggregates of their restaurants up to date
+type ReviewRepository interface {
+	CreateReview(ctx context.Context, review *models.Review) error
+	GetReviewByID(ctx context.Context, id primitive.ObjectID) (*models.Review, error)
+	UpdateReview(ctx context.Context, id primitive.ObjectID, rating int, comment string) (*models.Review, error)
+	DeleteReview(ctx context.Context, id primitive.ObjectID) error
+	GetReviewsByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID, opts models.QueryOptions) (*models.Page[models.Review], error)
+	GetAverageRatingByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID) (float64, error)
+	GetRatingStats(ctx context.Context) ([]models.RatingStats, error)
+	GetRatingStatsByRestaurantIDs(ctx context.Context, restaurantIDs []primitive.ObjectID) (map[primitive.ObjectID]models.RatingStats, error)
 }
 
-func NewReviewRepository(client *mongo.Client) *ReviewRepository {
-	collection := client.Database("testing").Collection("reviews")
-	return &ReviewRepository{collection: collection}
+// ratingRecomputeTimeout bounds the recompute of a restaurant's rating after a review write
+const ratingRecomputeTimeout = 5 * time.Second
+
+type MongoReviewRepository struct {
+	collection           *mongo.Collection
+	restaurantCollection *mongo.Collection
+}
+
+func NewMongoReviewRepository(db *mongo.Database) *MongoReviewRepository {
+	collection := db.Collection("reviews")
+	restaurantCollection := db.Collection("restaurants")
+	return &MongoReviewRepository{collection: collection, restaurantCollection: restaurantCollection}
 }
 
-func (r *ReviewRepository) CreateReview(ctx context.Context, review *models.Review) error {
+func (r *MongoReviewRepository) CreateReview(ctx context.Context, review *models.Review) error {
 	review.ID = primitive.NewObjectID()
 	review.CreatedAt = time.Now()
 	review.UpdatedAt = time.Now()
 
-	_, err := r.collection.InsertOne(ctx, review)
-	return err
+	if _, err := r.collection.InsertOne(ctx, review); err != nil {
+		return err
+	}
+
+	if err := r.recomputeRating(ctx, review.RestaurantID); err != nil {
+		logRecomputeError(review.RestaurantID, err)
+	}
+	return nil
 }
 
-func (r *ReviewRepository) GetReviewsByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID) ([]models.Review, error) {
-	filter := bson.M{"restaurantId": restaurantID}
-	cursor, err := r.collection.Find(ctx, filter)
+func (r *MongoReviewRepository) GetReviewByID(ctx context.Context, id primitive.ObjectID) (*models.Review, error) {
+	var review models.Review
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&review); err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// UpdateReview changes a review's rating and comment and recomputes the restaurant's aggregates.
+// It returns the updated review.
+func (r *MongoReviewRepository) UpdateReview(ctx context.Context, id primitive.ObjectID, rating int, comment string) (*models.Review, error) {
+	update := bson.M{"$set": bson.M{"rating": rating, "comment": comment, "updatedAt": time.Now()}}
+
+	var review models.Review
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&review)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
 
-	var reviews []models.Review
-	for cursor.Next(ctx) {
-		var review models.Review
-		if err := cursor.Decode(&review); err != nil {
-			return nil, err
-		}
-		reviews = append(reviews, review)
+	if err := r.recomputeRating(ctx, review.RestaurantID); err != nil {
+		logRecomputeError(review.RestaurantID, err)
 	}
+	return &review, nil
+}
 
-	return reviews, nil
+func (r *MongoReviewRepository) DeleteReview(ctx context.Context, id primitive.ObjectID) error {
+	var deleted models.Review
+	if err := r.collection.FindOneAndDelete(ctx, bson.M{"_id": id}).Decode(&deleted); err != nil {
+		return err
+	}
+
+	if err := r.recomputeRating(ctx, deleted.RestaurantID); err != nil {
+		logRecomputeError(deleted.RestaurantID, err)
+	}
+	return nil
 }
 
-func (r *ReviewRepository) GetAverageRatingByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID) (float64, error) {
+// GetReviewsByRestaurantID returns one page of a restaurant's reviews
+func (r *MongoReviewRepository) GetReviewsByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID, opts models.QueryOptions) (*models.Page[models.Review], error) {
+	matchStage := bson.D{{Key: "$match", Value: bson.D{{Key: "restaurantId", Value: restaurantID}}}}
+	return findPage[models.Review](ctx, r.collection, mongo.Pipeline{matchStage}, opts)
+}
+
+func (r *MongoReviewRepository) GetAverageRatingByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID) (float64, error) {
 	matchStage := bson.D{{Key: "$match", Value: bson.D{{Key: "restaurantId", Value: restaurantID}}}}
 	groupStage := bson.D{{Key: "$group", Value: bson.D{
 		{Key: "_id", Value: "$restaurantId"},
@@ -72,3 +121,124 @@ func (r *ReviewRepository) GetAverageRatingByRestaurantID(ctx context.Context, r
 
 	return result.AverageRating, nil
 }
+
+// GetRatingStats computes the rating aggregates of every reviewed restaurant from the reviews collection
+func (r *MongoReviewRepository) GetRatingStats(ctx context.Context) ([]models.RatingStats, error) {
+	return r.aggregateRatingStats(ctx, mongo.Pipeline{})
+}
+
+// GetRatingStatsByRestaurantIDs computes the rating aggregates of a set of restaurants in one
+// round trip. Restaurants without reviews are absent from the result.
+func (r *MongoReviewRepository) GetRatingStatsByRestaurantIDs(ctx context.Context, restaurantIDs []primitive.ObjectID) (map[primitive.ObjectID]models.RatingStats, error) {
+	matchStage := bson.D{{Key: "$match", Value: bson.D{{Key: "restaurantId", Value: bson.D{{Key: "$in", Value: restaurantIDs}}}}}}
+	stats, err := r.aggregateRatingStats(ctx, mongo.Pipeline{matchStage})
+	if err != nil {
+		return nil, err
+	}
+
+	byRestaurant := make(map[primitive.ObjectID]models.RatingStats, len(stats))
+	for _, stat := range stats {
+		byRestaurant[stat.RestaurantID] = stat
+	}
+	return byRestaurant, nil
+}
+
+// aggregateRatingStats runs the average rating $group, extended with a count, a total and one
+// counter per star, after the given leading stages
+func (r *MongoReviewRepository) aggregateRatingStats(ctx context.Context, pipeline mongo.Pipeline) ([]models.RatingStats, error) {
+	group := bson.D{
+		{Key: "_id", Value: "$restaurantId"},
+		{Key: "averageRating", Value: bson.D{{Key: "$avg", Value: "$rating"}}},
+		{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		{Key: "total", Value: bson.D{{Key: "$sum", Value: "$rating"}}},
+	}
+	histogram := bson.D{}
+	for star := 1; star <= 5; star++ {
+		key := strconv.Itoa(star)
+		group = append(group, bson.E{Key: "star" + key, Value: bson.D{{Key: "$sum", Value: bson.D{
+			{Key: "$cond", Value: bson.A{bson.D{{Key: "$eq", Value: bson.A{"$rating", star}}}, 1, 0}},
+		}}}})
+		histogram = append(histogram, bson.E{Key: key, Value: "$star" + key})
+	}
+
+	groupStage := bson.D{{Key: "$group", Value: group}}
+	projectStage := bson.D{{Key: "$project", Value: bson.D{
+		{Key: "averageRating", Value: 1},
+		{Key: "count", Value: 1},
+		{Key: "total", Value: 1},
+		{Key: "histogram", Value: histogram},
+	}}}
+	pipeline = append(pipeline, groupStage, projectStage)
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var stats []models.RatingStats
+	if err = cursor.All(ctx, &stats); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// logRecomputeError logs a failed recompute of a restaurant's rating. The review write has been
+// committed by then, so the request still succeeds; RecomputeRatings repairs the aggregates.
+func logRecomputeError(restaurantID primitive.ObjectID, err error) {
+	log.Printf("Error recomputing the rating of restaurant %s: %v", restaurantID.Hex(), err)
+}
+
+// recomputeRating rebuilds a restaurant's stored rating aggregates from its reviews in a single
+// aggregation that $merges the result into the restaurant. Nothing is added to or subtracted from
+// the stored values, so concurrent review writes can't corrupt them, and a recompute that fails
+// leaves them behind until the next review write instead of wrong for good. It runs after the
+// review write even if the request has been cancelled by then.
+func (r *MongoReviewRepository) recomputeRating(ctx context.Context, restaurantID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ratingRecomputeTimeout)
+	defer cancel()
+
+	histogram := bson.D{}
+	for star := 1; star <= 5; star++ {
+		histogram = append(histogram, bson.E{Key: strconv.Itoa(star), Value: bson.D{{Key: "$size", Value: bson.D{{Key: "$filter", Value: bson.D{
+			{Key: "input", Value: "$reviews"},
+			{Key: "cond", Value: bson.D{{Key: "$eq", Value: bson.A{"$$this.rating", star}}}},
+		}}}}}})
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "_id", Value: restaurantID}}}},
+		{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: r.collection.Name()},
+			{Key: "let", Value: bson.D{{Key: "restaurantId", Value: "$_id"}}},
+			{Key: "pipeline", Value: mongo.Pipeline{
+				{{Key: "$match", Value: bson.D{{Key: "$expr", Value: bson.D{{Key: "$eq", Value: bson.A{"$restaurantId", "$$restaurantId"}}}}}}},
+				{{Key: "$project", Value: bson.D{{Key: "_id", Value: 0}, {Key: "rating", Value: 1}}}},
+			}},
+			{Key: "as", Value: "reviews"},
+		}}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "ratingCount", Value: bson.D{{Key: "$size", Value: "$reviews"}}},
+			{Key: "ratingTotal", Value: bson.D{{Key: "$sum", Value: "$reviews.rating"}}},
+			{Key: "ratingHistogram", Value: histogram},
+		}}},
+		{{Key: "$set", Value: bson.D{{Key: "rating", Value: bson.D{{Key: "$cond", Value: bson.A{
+			bson.D{{Key: "$gt", Value: bson.A{"$ratingCount", 0}}},
+			bson.D{{Key: "$divide", Value: bson.A{"$ratingTotal", "$ratingCount"}}},
+			0,
+		}}}}}}},
+		{{Key: "$merge", Value: bson.D{
+			{Key: "into", Value: r.restaurantCollection.Name()},
+			{Key: "on", Value: "_id"},
+			{Key: "whenMatched", Value: "merge"},
+			{Key: "whenNotMatched", Value: "discard"},
+		}}},
+	}
+
+	cursor, err := r.restaurantCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	return cursor.Close(ctx)
+}
@@ -0,0 +1,42 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MemoryUserRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryUserRepository(store *MemoryStore) *MemoryUserRepository {
+	return &MemoryUserRepository{store: store}
+}
+
+func (r *MemoryUserRepository) CreateUser(ctx context.Context, user *models.User) error {
+	user.ID = primitive.NewObjectID()
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+
+	return r.store.Insert("users", user)
+}
+
+func (r *MemoryUserRepository) GetUserByID(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	var user models.User
+	if err := r.store.findOne("users", bson.M{"_id": id}, nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *MemoryUserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	if err := r.store.findOne("users", bson.M{"email": email}, nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
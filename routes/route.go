@@ -1,72 +1,238 @@
 package routes
 
 import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/config"
 	"github.com/aldiandyaIrsyad/uber-eats/controllers"
+	"github.com/aldiandyaIrsyad/uber-eats/middleware"
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/aldiandyaIrsyad/uber-eats/policies"
 	"github.com/aldiandyaIrsyad/uber-eats/repos"
 	"github.com/aldiandyaIrsyad/uber-eats/services"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type RouteHandler struct {
+	cfg *config.Config
+
+	authService *services.AuthService
+	authorizer  *policies.Authorizer
+
+	authController       *controllers.AuthController
 	restaurantController *controllers.RestaurantController
 	itemController       *controllers.ItemController
 	reviewController     *controllers.ReviewController
+	orderController      *controllers.OrderController
+	cartController       *controllers.CartController
+	zoneController       *controllers.DeliveryZoneController
+	menuController       *controllers.MenuController
+	inventoryController  *controllers.InventoryController
+	searchController     *controllers.SearchController
+
+	restaurantService *services.RestaurantService
+	inventoryService  *services.InventoryService
+	suggestService    *services.SuggestService
 }
 
-func NewRouteHandler(client *mongo.Client) *RouteHandler {
-	// Initialize repositories
-	restaurantRepo := repos.NewRestaurantRepository(client)
-	itemRepo := repos.NewItemRepository(client)
-	reviewRepo := repos.NewReviewRepository(client)
+func NewRouteHandler(cfg *config.Config, repositories repos.Repositories) *RouteHandler {
+	// Repositories are injected, backed by MongoDB or kept in memory
+	userRepo := repositories.Users
+	restaurantRepo := repositories.Restaurants
+	itemRepo := repositories.Items
+	reviewRepo := repositories.Reviews
+	orderRepo := repositories.Orders
+	cartRepo := repositories.Carts
+	auditRepo := repositories.Audit
+	zoneRepo := repositories.Zones
+	categoryRepo := repositories.Categories
+	menuRepo := repositories.Menus
 
 	// Initialize services
-	restaurantService := services.NewRestaurantService(restaurantRepo, reviewRepo)
-	itemService := services.NewItemService(itemRepo)
-	reviewService := services.NewReviewService(reviewRepo)
+	authService := services.NewAuthService(userRepo, []byte(cfg.Auth.JWTSecret))
+	suggestService := services.NewSuggestService(restaurantRepo, itemRepo)
+	restaurantService := services.NewRestaurantService(restaurantRepo, reviewRepo, menuRepo, suggestService)
+	inventoryService := services.NewInventoryService(itemRepo, restaurantRepo)
+	itemService := services.NewItemService(itemRepo, categoryRepo, menuRepo, restaurantRepo, inventoryService, suggestService)
+	reviewService := services.NewReviewService(reviewRepo, orderRepo)
+	searchService := services.NewSearchService(restaurantRepo, itemRepo)
+	orderService := services.NewOrderService(orderRepo, itemRepo, restaurantRepo, menuRepo, inventoryService)
+	cartService := services.NewCartService(cartRepo, itemRepo, orderService)
+	zoneService := services.NewDeliveryZoneService(zoneRepo, restaurantRepo)
+	menuService := services.NewMenuService(menuRepo, categoryRepo, itemRepo, restaurantRepo)
+
+	// Initialize authorization policies
+	authorizer := policies.NewAuthorizer(restaurantRepo, itemRepo, orderRepo, reviewRepo, auditRepo)
 
 	// Initialize controllers
+	authController := controllers.NewAuthController(authService)
 	restaurantController := controllers.NewRestaurantController(restaurantService)
 	itemController := controllers.NewItemController(itemService)
 	reviewController := controllers.NewReviewController(reviewService)
+	orderController := controllers.NewOrderController(orderService)
+	cartController := controllers.NewCartController(cartService)
+	zoneController := controllers.NewDeliveryZoneController(zoneService)
+	menuController := controllers.NewMenuController(menuService)
+	inventoryController := controllers.NewInventoryController(inventoryService)
+	searchController := controllers.NewSearchController(searchService, suggestService)
 
 	return &RouteHandler{
+		cfg:                  cfg,
+		authService:          authService,
+		authorizer:           authorizer,
+		authController:       authController,
 		restaurantController: restaurantController,
 		itemController:       itemController,
 		reviewController:     reviewController,
+		orderController:      orderController,
+		cartController:       cartController,
+		zoneController:       zoneController,
+		menuController:       menuController,
+		inventoryController:  inventoryController,
+		searchController:     searchController,
+		restaurantService:    restaurantService,
+		inventoryService:     inventoryService,
+		suggestService:       suggestService,
 	}
 }
 
+// LoadSearchIndex builds the in-memory search suggestions from the database
+func (rh *RouteHandler) LoadSearchIndex(ctx context.Context) error {
+	return rh.suggestService.Load(ctx)
+}
+
+// BackfillOpenIntervals stores the open intervals of restaurants saved before they were computed
+func (rh *RouteHandler) BackfillOpenIntervals(ctx context.Context) (int, error) {
+	return rh.restaurantService.BackfillOpenIntervals(ctx)
+}
+
+// stockResetInterval is how often items are checked for a due daily stock reset
+const stockResetInterval = time.Minute
+
+// StartBackgroundJobs starts the jobs that run alongside the API. They stop when ctx is cancelled;
+// the returned function waits until they have.
+func (rh *RouteHandler) StartBackgroundJobs(ctx context.Context) (wait func()) {
+	var jobs sync.WaitGroup
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		rh.inventoryService.RunDailyResets(ctx, stockResetInterval)
+	}()
+	return jobs.Wait
+}
+
 func (rh *RouteHandler) SetupRoutes(r *gin.Engine) {
+	authz := rh.authorizer
+
+	// Every response, including those for unknown routes and methods, reports errors as problems
+	r.Use(middleware.Errors())
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(middleware.NoRoute())
+	r.NoMethod(middleware.NoMethod())
+
 	api := r.Group("/api")
+	api.Use(middleware.Timeout(rh.cfg.Server.RequestTimeout, rh.cfg.Server.RouteTimeouts))
+	api.Use(middleware.Authenticate(rh.authService))
 	{
+		// Auth routes
+		auth := api.Group("/auth")
+		{
+			auth.POST("/signup", rh.authController.Signup)
+			auth.POST("/login", rh.authController.Login)
+			auth.GET("/me", authz.Require("auth.me"), rh.authController.Me)
+		}
+
 		// Restaurant routes
 		restaurants := api.Group("/restaurants")
 		{
-			restaurants.POST("", rh.restaurantController.CreateRestaurant)
+			restaurants.POST("", authz.Require("restaurant.create", policies.Roles(models.RoleRestaurantOwner)), rh.restaurantController.CreateRestaurant)
+			restaurants.GET("/nearby", rh.restaurantController.GetNearbyRestaurants)
 			restaurants.GET("/:id", rh.restaurantController.GetRestaurantByID)
-			restaurants.PUT("/:id", rh.restaurantController.UpdateRestaurant)
-			restaurants.DELETE("/:id", rh.restaurantController.DeleteRestaurant)
+			restaurants.PUT("/:id", authz.Require("restaurant.update", authz.RestaurantOwner("id")), rh.restaurantController.UpdateRestaurant)
+			restaurants.DELETE("/:id", authz.Require("restaurant.delete", authz.RestaurantOwner("id")), rh.restaurantController.DeleteRestaurant)
 			restaurants.GET("/:id/rating", rh.restaurantController.GetAverageRating)
 			restaurants.GET("", rh.restaurantController.GetRestaurants)
+
+			// Delivery zones
+			restaurants.GET("/:id/delivery-zones", rh.zoneController.GetZones)
+			restaurants.POST("/:id/delivery-zones", authz.Require("deliveryZone.create", authz.RestaurantOwner("id")), rh.zoneController.CreateZone)
+			restaurants.PUT("/:id/delivery-zones/:zoneID", authz.Require("deliveryZone.update", authz.RestaurantOwner("id")), rh.zoneController.UpdateZone)
+			restaurants.DELETE("/:id/delivery-zones/:zoneID", authz.Require("deliveryZone.delete", authz.RestaurantOwner("id")), rh.zoneController.DeleteZone)
+			restaurants.GET("/:id/delivery-check", rh.zoneController.CheckDelivery)
+
+			// Menu, scheduled menus and categories
+			restaurants.GET("/:id/menu", rh.menuController.GetRestaurantMenu)
+			restaurants.GET("/:id/menus", rh.menuController.GetMenus)
+			restaurants.POST("/:id/menus", authz.Require("menu.create", authz.RestaurantOwner("id")), rh.menuController.CreateMenu)
+			restaurants.PUT("/:id/menus/:menuID", authz.Require("menu.update", authz.RestaurantOwner("id")), rh.menuController.UpdateMenu)
+			restaurants.DELETE("/:id/menus/:menuID", authz.Require("menu.delete", authz.RestaurantOwner("id")), rh.menuController.DeleteMenu)
+			restaurants.GET("/:id/categories", rh.menuController.GetCategories)
+			restaurants.POST("/:id/categories", authz.Require("menuCategory.create", authz.RestaurantOwner("id")), rh.menuController.CreateCategory)
+			restaurants.PUT("/:id/categories/:categoryID", authz.Require("menuCategory.update", authz.RestaurantOwner("id")), rh.menuController.UpdateCategory)
+			restaurants.DELETE("/:id/categories/:categoryID", authz.Require("menuCategory.delete", authz.RestaurantOwner("id")), rh.menuController.DeleteCategory)
+
+			// Stock of tracked items
+			restaurants.PATCH("/:id/stock", authz.Require("item.stock", authz.RestaurantOwner("id")), rh.inventoryController.AdjustStock)
+
+			// Hours exceptions
+			restaurants.GET("/:id/hours/exceptions", rh.restaurantController.GetHoursExceptions)
+			restaurants.POST("/:id/hours/exceptions", authz.Require("restaurant.hours.create", authz.RestaurantOwner("id")), rh.restaurantController.AddHoursException)
+			restaurants.PUT("/:id/hours/exceptions/:exceptionID", authz.Require("restaurant.hours.update", authz.RestaurantOwner("id")), rh.restaurantController.UpdateHoursException)
+			restaurants.DELETE("/:id/hours/exceptions/:exceptionID", authz.Require("restaurant.hours.delete", authz.RestaurantOwner("id")), rh.restaurantController.DeleteHoursException)
 		}
 
+		// Search across restaurants and items
+		api.GET("/search", rh.searchController.Search)
+		api.GET("/search/suggest", rh.searchController.Suggest)
+
 		// Item routes
 		items := api.Group("/items")
 		{
-			items.POST("", rh.itemController.CreateItem)
+			items.POST("", authz.Require("item.create", authz.RestaurantOwnerOfBody("restaurantId")), rh.itemController.CreateItem)
 			items.GET("", rh.itemController.GetItems)
 			items.GET("/:id", rh.itemController.GetItemByID)
-			items.PUT("/:id", rh.itemController.UpdateItem)
-			items.DELETE("/:id", rh.itemController.DeleteItem)
+			items.PUT("/:id", authz.Require("item.update", authz.ItemOwner("id")), rh.itemController.UpdateItem)
+			items.DELETE("/:id", authz.Require("item.delete", authz.ItemOwner("id")), rh.itemController.DeleteItem)
 		}
 
 		// Review routes
 		reviews := api.Group("/reviews")
 		{
-			reviews.POST("", rh.reviewController.CreateReview)
+			reviews.POST("", authz.Require("review.create", policies.Roles(models.RoleCustomer)), rh.reviewController.CreateReview)
+			reviews.PUT("/:id", authz.Require("review.update", authz.ReviewAuthor("id")), rh.reviewController.UpdateReview)
+			reviews.DELETE("/:id", authz.Require("review.delete", authz.ReviewAuthor("id")), rh.reviewController.DeleteReview)
 			reviews.GET("/restaurant/:restaurantID", rh.reviewController.GetReviewsByRestaurantID)
 			reviews.GET("/restaurant/:restaurantID/rating", rh.reviewController.GetAverageRatingByRestaurantID)
 		}
+
+		// Order routes
+		orders := api.Group("/orders")
+		{
+			orderParticipant := policies.AnyOf(authz.OrderCustomer("id"), authz.OrderRestaurantOwner("id"), authz.OrderCourier("id"))
+
+			orders.POST("", authz.Require("order.create", policies.Roles(models.RoleCustomer)), rh.orderController.CreateOrder)
+			orders.GET("/:id", authz.Require("order.read", orderParticipant), rh.orderController.GetOrderByID)
+			orders.PATCH("/:id/status", authz.Require("order.updateStatus", authz.OrderStatusChange("id")), rh.orderController.UpdateOrderStatus)
+			orders.GET("/restaurant/:restaurantID", authz.Require("order.listByRestaurant", authz.RestaurantOwner("restaurantID")), rh.orderController.GetOrdersByRestaurantID)
+		}
+
+		// Admin routes
+		admin := api.Group("/admin", authz.Require("admin", policies.Roles(models.RoleAdmin)))
+		{
+			admin.POST("/ratings/recompute", rh.restaurantController.RecomputeRatings)
+		}
+
+		// Cart routes, always for the calling customer
+		cart := api.Group("/cart", authz.Require("cart", policies.Roles(models.RoleCustomer)))
+		{
+			cart.GET("", rh.cartController.GetCart)
+			cart.DELETE("", rh.cartController.ClearCart)
+			cart.POST("/items", rh.cartController.AddItem)
+			cart.PUT("/items/:lineID", rh.cartController.UpdateItemQuantity)
+			cart.DELETE("/items/:lineID", rh.cartController.RemoveItem)
+			cart.POST("/checkout", rh.cartController.Checkout)
+		}
 	}
 }
@@ -0,0 +1,61 @@
+package seeders
+
+import (
+	"context"
+
+	"github.com/aldiandyaIrsyad/uber-eats/repos"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Database is where the seed data is written: MongoDB or the in-memory store
+type Database interface {
+	Count(ctx context.Context, collection string) (int64, error)
+	Insert(ctx context.Context, collection string, document interface{}) error
+	Update(ctx context.Context, collection string, filter, update bson.M) error
+}
+
+type mongoDatabase struct {
+	db *mongo.Database
+}
+
+// MongoDatabase seeds a MongoDB database
+func MongoDatabase(db *mongo.Database) Database {
+	return mongoDatabase{db: db}
+}
+
+func (d mongoDatabase) Count(ctx context.Context, collection string) (int64, error) {
+	return d.db.Collection(collection).CountDocuments(ctx, bson.M{})
+}
+
+func (d mongoDatabase) Insert(ctx context.Context, collection string, document interface{}) error {
+	_, err := d.db.Collection(collection).InsertOne(ctx, document)
+	return err
+}
+
+func (d mongoDatabase) Update(ctx context.Context, collection string, filter, update bson.M) error {
+	_, err := d.db.Collection(collection).UpdateOne(ctx, filter, update)
+	return err
+}
+
+type memoryDatabase struct {
+	store *repos.MemoryStore
+}
+
+// MemoryDatabase seeds an in-memory store
+func MemoryDatabase(store *repos.MemoryStore) Database {
+	return memoryDatabase{store: store}
+}
+
+func (d memoryDatabase) Count(ctx context.Context, collection string) (int64, error) {
+	return d.store.Count(collection, bson.M{})
+}
+
+func (d memoryDatabase) Insert(ctx context.Context, collection string, document interface{}) error {
+	return d.store.Insert(collection, document)
+}
+
+func (d memoryDatabase) Update(ctx context.Context, collection string, filter, update bson.M) error {
+	_, err := d.store.Update(collection, filter, update)
+	return err
+}
@@ -4,25 +4,51 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aldiandyaIrsyad/uber-eats/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
 )
 
+const seedPassword = "password123"
+
 const (
 	numRestaurants = 2
 	numItems       = 15
 	numReviews     = 15
 )
 
-func createRestaurants() []models.Restaurant {
+// createUsers creates one user per role, all sharing seedPassword
+func createUsers() ([]models.User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(seedPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := []string{models.RoleAdmin, models.RoleRestaurantOwner, models.RoleCustomer, models.RoleCourier}
+	users := make([]models.User, len(roles))
+	for i, role := range roles {
+		name := strings.ReplaceAll(role, "_", "-")
+		users[i] = models.User{
+			Name:         fmt.Sprintf("Seed %s", name),
+			Email:        fmt.Sprintf("%s@example.com", name),
+			PasswordHash: string(hash),
+			Role:         role,
+		}
+	}
+	return users, nil
+}
+
+func createRestaurants(ownerID primitive.ObjectID) []models.Restaurant {
 	restaurants := make([]models.Restaurant, numRestaurants)
 
 	for i := 0; i < numRestaurants; i++ {
 		restaurants[i] = models.Restaurant{
+			OwnerID:     ownerID,
 			Name:        fmt.Sprintf("Restaurant %d", i+1),
 			Description: fmt.Sprintf("Description for Restaurant %d", i+1),
 			Address:     fmt.Sprintf("%d Main Street", (i+1)*100),
@@ -34,6 +60,7 @@ func createRestaurants() []models.Restaurant {
 				Type:        "Point",
 				Coordinates: []float64{-73.935242 + float64(i)*0.01, 40.730610}, // Slight variation in coordinates
 			},
+			Timezone: "America/New_York",
 			OperatingHours: []models.OperatingHours{
 				{Day: "Monday", OpenTime: "09:00", CloseTime: "22:00"},
 				{Day: "Tuesday", OpenTime: "09:00", CloseTime: "22:00"},
@@ -48,30 +75,106 @@ func createRestaurants() []models.Restaurant {
 	return restaurants
 }
 
-func createItems(restaurantID primitive.ObjectID, restaurantIndex int) []models.Item {
+// menuCategoryNames are the sections every seeded menu is split into
+var menuCategoryNames = []string{"Starters", "Mains", "Drinks"}
+
+func createCategories(restaurantID primitive.ObjectID) []models.MenuCategory {
+	categories := make([]models.MenuCategory, len(menuCategoryNames))
+
+	for i, name := range menuCategoryNames {
+		categories[i] = models.MenuCategory{
+			RestaurantID: restaurantID,
+			Name:         name,
+			Position:     i,
+		}
+	}
+	return categories
+}
+
+// createItems spreads the items over the categories in turn
+func createItems(restaurantID primitive.ObjectID, restaurantIndex int, categories []models.MenuCategory) []models.Item {
 	items := make([]models.Item, numItems)
 
 	for i := 0; i < numItems; i++ {
+		categoryID := categories[i%len(categories)].ID
 		items[i] = models.Item{
 			RestaurantID: restaurantID,
+			CategoryID:   &categoryID,
+			Position:     i / len(categories),
 			Name:         fmt.Sprintf("Item %d (Restaurant %d)", i+1, restaurantIndex+1),
 			Description:  fmt.Sprintf("Description for Item %d from Restaurant %d", i+1, restaurantIndex+1),
 			Price:        float64(5+(i*2)) + 0.99, // Prices from 5.99 to 23.99
 			ImageURL:     fmt.Sprintf("https://example.com/restaurant-%d/item-%d.jpg", restaurantIndex+1, i+1),
 			Status:       "available",
 		}
+		if i%len(categories) == 1 {
+			items[i].ModifierGroups = []models.ModifierGroup{createSizeGroup()}
+		}
 	}
 	return items
 }
 
-func createReviews(restaurantID, orderID primitive.ObjectID, restaurantIndex int) []models.Review {
+// createSizeGroup is a required single choice, as seeded on every main
+func createSizeGroup() models.ModifierGroup {
+	return models.ModifierGroup{
+		ID:            primitive.NewObjectID(),
+		Name:          "Size",
+		MinSelections: 1,
+		MaxSelections: 1,
+		Options: []models.ModifierOption{
+			{ID: primitive.NewObjectID(), Name: "Regular", PriceDelta: 0, Status: models.ItemStatusAvailable},
+			{ID: primitive.NewObjectID(), Name: "Large", PriceDelta: 2.5, Status: models.ItemStatusAvailable},
+		},
+	}
+}
+
+// createDeliveredOrders creates one delivered order per review so every seeded review points at a real order
+func createDeliveredOrders(restaurantID, customerID primitive.ObjectID, items []models.Item) []models.Order {
+	orders := make([]models.Order, numReviews)
+	statuses := []string{
+		models.OrderStatusPlaced,
+		models.OrderStatusAccepted,
+		models.OrderStatusPreparing,
+		models.OrderStatusReady,
+		models.OrderStatusPickedUp,
+		models.OrderStatusDelivered,
+	}
+
+	for i := 0; i < numReviews; i++ {
+		item := items[i%len(items)]
+		quantity := 1 + (i % 2)
+		placedAt := time.Now().Add(-time.Duration(numReviews-i) * time.Hour)
+
+		history := make([]models.OrderStatusChange, len(statuses))
+		for j, status := range statuses {
+			history[j] = models.OrderStatusChange{Status: status, ChangedAt: placedAt.Add(time.Duration(j*5) * time.Minute)}
+		}
+
+		orders[i] = models.Order{
+			CustomerID:   customerID,
+			RestaurantID: restaurantID,
+			Items: []models.OrderItem{
+				{ItemID: item.ID, Name: item.Name, Price: item.Price, UnitPrice: item.Price, Quantity: quantity, LineTotal: item.Price * float64(quantity)},
+			},
+			Pricing:       models.PriceBreakdown{Subtotal: item.Price * float64(quantity), Total: item.Price * float64(quantity)},
+			Status:        models.OrderStatusDelivered,
+			StatusHistory: history,
+			CreatedAt:     placedAt,
+			UpdatedAt:     history[len(history)-1].ChangedAt,
+		}
+	}
+	return orders
+}
+
+func createReviews(restaurantID primitive.ObjectID, orders []models.Order, restaurantIndex int) []models.Review {
 	reviews := make([]models.Review, numReviews)
 
 	for i := 0; i < numReviews; i++ {
 		rating := 3 + (i % 3) // Ratings from 3 to 5
 		reviews[i] = models.Review{
+			UserID:       orders[i].CustomerID,
 			RestaurantID: restaurantID,
-			OrderID:      orderID,
+			OrderID:      orders[i].ID,
 			Rating:       rating,
 			Comment:      fmt.Sprintf("Review %d for Restaurant %d - %d stars", i+1, restaurantIndex+1, rating),
 			RestaurantResponse: struct {
@@ -86,14 +189,32 @@ func createReviews(restaurantID, orderID primitive.ObjectID, restaurantIndex int
 	return reviews
 }
 
-func isDatabaseEmpty(client *mongo.Client) (bool, error) {
-	ctx := context.Background()
-	db := client.Database("testing")
+// ratingFields returns the restaurant rating aggregates for a set of reviews
+func ratingFields(reviews []models.Review) bson.M {
+	histogram := models.EmptyRatingHistogram()
+	var total int64
+	for _, review := range reviews {
+		histogram[strconv.Itoa(review.Rating)]++
+		total += int64(review.Rating)
+	}
+
+	var average float64
+	if len(reviews) > 0 {
+		average = float64(total) / float64(len(reviews))
+	}
+	return bson.M{
+		"rating":          average,
+		"ratingCount":     int64(len(reviews)),
+		"ratingTotal":     total,
+		"ratingHistogram": histogram,
+	}
+}
 
+func isDatabaseEmpty(ctx context.Context, db Database) (bool, error) {
 	// Check each collection
-	collections := []string{"restaurants", "items", "reviews"}
+	collections := []string{"users", "restaurants", "items", "reviews", "orders"}
 	for _, collName := range collections {
-		count, err := db.Collection(collName).CountDocuments(ctx, bson.M{})
+		count, err := db.Count(ctx, collName)
 		if err != nil {
 			return false, err
 		}
@@ -104,8 +225,9 @@ func isDatabaseEmpty(client *mongo.Client) (bool, error) {
 	return true, nil
 }
 
-func SeedDatabase(client *mongo.Client) error {
-	isEmpty, err := isDatabaseEmpty(client)
+func SeedDatabase(db Database) error {
+	ctx := context.Background()
+	isEmpty, err := isDatabaseEmpty(ctx, db)
 	if err != nil {
 		return fmt.Errorf("error checking database: %v", err)
 	}
@@ -114,50 +236,92 @@ func SeedDatabase(client *mongo.Client) error {
 		log.Println("Database is not empty, skipping seed")
 		return nil
 	}
-	ctx := context.Background()
+
+	// Create users
+	users, err := createUsers()
+	if err != nil {
+		return fmt.Errorf("error creating users: %v", err)
+	}
+	usersByRole := make(map[string]primitive.ObjectID)
+	for i := range users {
+		users[i].ID = primitive.NewObjectID()
+		users[i].CreatedAt = time.Now()
+		users[i].UpdatedAt = time.Now()
+		if err := db.Insert(ctx, "users", users[i]); err != nil {
+			return fmt.Errorf("error seeding user %s: %v", users[i].Email, err)
+		}
+		usersByRole[users[i].Role] = users[i].ID
+	}
 
 	// Create restaurants
-	restaurants := createRestaurants()
-	restaurantCollection := client.Database("testing").Collection("restaurants")
-	itemCollection := client.Database("testing").Collection("items")
-	reviewCollection := client.Database("testing").Collection("reviews")
+	restaurants := createRestaurants(usersByRole[models.RoleRestaurantOwner])
 
 	for i, restaurant := range restaurants {
 		// Seed restaurant
 		restaurant.ID = primitive.NewObjectID()
 		restaurant.CreatedAt = time.Now()
 		restaurant.UpdatedAt = time.Now()
-		_, err := restaurantCollection.InsertOne(ctx, restaurant)
+		if err := restaurant.NormalizeHours(); err != nil {
+			return fmt.Errorf("error seeding hours for restaurant %d: %v", i+1, err)
+		}
+		err := db.Insert(ctx, "restaurants", restaurant)
 		if err != nil {
 			return fmt.Errorf("error seeding restaurant %d: %v", i+1, err)
 		}
 
+		// Seed menu categories
+		categories := createCategories(restaurant.ID)
+		for j := range categories {
+			categories[j].ID = primitive.NewObjectID()
+			categories[j].CreatedAt = time.Now()
+			categories[j].UpdatedAt = time.Now()
+			if err := db.Insert(ctx, "menu_categories", categories[j]); err != nil {
+				return fmt.Errorf("error seeding category for restaurant %d: %v", i+1, err)
+			}
+		}
+
 		// Seed items
-		items := createItems(restaurant.ID, i)
-		for _, item := range items {
-			item.ID = primitive.NewObjectID()
-			item.CreatedAt = time.Now()
-			item.UpdatedAt = time.Now()
-			_, err := itemCollection.InsertOne(ctx, item)
+		items := createItems(restaurant.ID, i, categories)
+		for j := range items {
+			items[j].ID = primitive.NewObjectID()
+			items[j].CreatedAt = time.Now()
+			items[j].UpdatedAt = time.Now()
+			err := db.Insert(ctx, "items", items[j])
 			if err != nil {
 				return fmt.Errorf("error seeding item for restaurant %d: %v", i+1, err)
 			}
 		}
 
+		// Seed delivered orders for the reviews to refer to
+		orders := createDeliveredOrders(restaurant.ID, usersByRole[models.RoleCustomer], items)
+		for j := range orders {
+			orders[j].ID = primitive.NewObjectID()
+			err := db.Insert(ctx, "orders", orders[j])
+			if err != nil {
+				return fmt.Errorf("error seeding order for restaurant %d: %v", i+1, err)
+			}
+		}
+
 		// Seed reviews
-		orderID := primitive.NewObjectID()
-		reviews := createReviews(restaurant.ID, orderID, i)
+		reviews := createReviews(restaurant.ID, orders, i)
 		for _, review := range reviews {
 			review.ID = primitive.NewObjectID()
 			review.CreatedAt = time.Now()
 			review.UpdatedAt = time.Now()
-			_, err := reviewCollection.InsertOne(ctx, review)
+			err := db.Insert(ctx, "reviews", review)
 			if err != nil {
 				return fmt.Errorf("error seeding review for restaurant %d: %v", i+1, err)
 			}
 		}
+
+		// Store the rating aggregates the review repository would have maintained
+		err = db.Update(ctx, "restaurants", bson.M{"_id": restaurant.ID}, bson.M{"$set": ratingFields(reviews)})
+		if err != nil {
+			return fmt.Errorf("error seeding rating for restaurant %d: %v", i+1, err)
+		}
 	}
 
-	log.Printf("Seeded %d restaurants with %d items and %d reviews each", numRestaurants, numItems, numReviews)
+	log.Printf("Seeded users admin, restaurant-owner, customer and courier (<role>@example.com) with password %q", seedPassword)
+	log.Printf("Seeded %d restaurants with %d items, %d orders and %d reviews each", numRestaurants, numItems, numReviews, numReviews)
 	return nil
 }
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/aldiandyaIrsyad/uber-eats/repos"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const tokenTTL = 24 * time.Hour
+
+var (
+	ErrEmailTaken         = Conflict("email_taken", "email is already registered")
+	ErrInvalidCredentials = Unauthorized("invalid_credentials", "invalid email or password")
+	ErrInvalidRole        = InvalidField("role", "invalid_role", "role must be one of customer, restaurant_owner or courier")
+	ErrWeakPassword       = InvalidField("password", "weak_password", "password must be at least 8 characters")
+	ErrPasswordTooLong    = InvalidField("password", "password_too_long", "password must be at most 72 bytes")
+	ErrInvalidToken       = Unauthorized("invalid_token", "invalid or expired token")
+	ErrUserNotFound       = NotFound("user_not_found", "user not found")
+)
+
+// signupRoles are the roles a user may pick for themselves; admins are created out of band
+var signupRoles = map[string]bool{
+	models.RoleCustomer:        true,
+	models.RoleRestaurantOwner: true,
+	models.RoleCourier:         true,
+}
+
+type tokenClaims struct {
+	Role string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+type AuthService struct {
+	userRepo repos.UserRepository
+	secret   []byte
+}
+
+func NewAuthService(userRepo repos.UserRepository, secret []byte) *AuthService {
+	return &AuthService{
+		userRepo: userRepo,
+		secret:   secret,
+	}
+}
+
+// Signup registers a new user and returns a token for them
+func (s *AuthService) Signup(ctx context.Context, user *models.User, password string) (string, error) {
+	if user.Role == "" {
+		user.Role = models.RoleCustomer
+	}
+	if !signupRoles[user.Role] {
+		return "", ErrInvalidRole
+	}
+	if len(password) < 8 {
+		return "", ErrWeakPassword
+	}
+	// bcrypt only uses the first 72 bytes, and refuses longer passwords
+	if len(password) > 72 {
+		return "", ErrPasswordTooLong
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	user.Email = strings.ToLower(strings.TrimSpace(user.Email))
+	user.PasswordHash = string(hash)
+
+	if err := s.userRepo.CreateUser(ctx, user); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return "", ErrEmailTaken
+		}
+		return "", err
+	}
+
+	return s.issueToken(user)
+}
+
+// Login checks the credentials and returns a token together with the user
+func (s *AuthService) Login(ctx context.Context, email, password string) (string, *models.User, error) {
+	user, err := s.userRepo.GetUserByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return "", nil, ErrInvalidCredentials
+		}
+		return "", nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return "", nil, ErrInvalidCredentials
+	}
+
+	token, err := s.issueToken(user)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, user, nil
+}
+
+func (s *AuthService) GetUserByID(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+	return user, nil
+}
+
+// ParseToken verifies a signed token and returns the caller it was issued to
+func (s *AuthService) ParseToken(tokenString string) (*models.AuthUser, error) {
+	var claims tokenClaims
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	userID, err := primitive.ObjectIDFromHex(claims.Subject)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return &models.AuthUser{ID: userID, Role: claims.Role}, nil
+}
+
+func (s *AuthService) issueToken(user *models.User) (string, error) {
+	now := time.Now()
+	claims := tokenClaims{
+		Role: user.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.ID.Hex(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(tokenTTL)),
+		},
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+}
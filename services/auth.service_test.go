@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+)
+
+func TestAuthSignupAndLogin(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+
+	user := &models.User{Name: "Ann", Email: " Ann@Example.com "}
+	token, err := s.auth.Signup(ctx, user, "password123")
+	if err != nil {
+		t.Fatalf("Signup: %v", err)
+	}
+	caller, err := s.auth.ParseToken(token)
+	if err != nil {
+		t.Fatalf("ParseToken: %v", err)
+	}
+	if caller.ID != user.ID || caller.Role != models.RoleCustomer {
+		t.Errorf("token is for %v, want user %s as a customer", caller, user.ID.Hex())
+	}
+
+	if _, err := s.auth.Signup(ctx, &models.User{Name: "Ann", Email: "ann@example.com"}, "password123"); !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("second signup with the same email: err = %v, want ErrEmailTaken", err)
+	}
+
+	if _, loggedIn, err := s.auth.Login(ctx, "ANN@example.com", "password123"); err != nil || loggedIn.ID != user.ID {
+		t.Errorf("Login = %v, %v, want user %s", loggedIn, err, user.ID.Hex())
+	}
+	if _, _, err := s.auth.Login(ctx, "ann@example.com", "wrong password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Login with a wrong password: err = %v, want ErrInvalidCredentials", err)
+	}
+	if _, _, err := s.auth.Login(ctx, "bob@example.com", "password123"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Login of an unknown user: err = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestAuthSignupRefusesInvalidInput(t *testing.T) {
+	s := newTestServices()
+	tests := []struct {
+		name     string
+		role     string
+		password string
+		want     error
+	}{
+		{"admin role", models.RoleAdmin, "password123", ErrInvalidRole},
+		{"unknown role", "chef", "password123", ErrInvalidRole},
+		{"short password", models.RoleCourier, "short", ErrWeakPassword},
+		{"password over 72 bytes", models.RoleCourier, strings.Repeat("é", 37), ErrPasswordTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.auth.Signup(context.Background(), &models.User{Name: "Ann", Email: "ann@example.com", Role: tt.role}, tt.password)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
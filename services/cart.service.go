@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/aldiandyaIrsyad/uber-eats/repos"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrCartEmpty              = Validation("cart_empty", "cart is empty")
+	ErrCartItemNotFound       = NotFound("cart_item_not_found", "item is not in the cart")
+	ErrCartRestaurantMismatch = Conflict("cart_restaurant_mismatch", "cart already contains items from another restaurant")
+)
+
+type CartService struct {
+	cartRepo     repos.CartRepository
+	itemRepo     repos.ItemRepository
+	orderService *OrderService
+}
+
+func NewCartService(cartRepo repos.CartRepository, itemRepo repos.ItemRepository, orderService *OrderService) *CartService {
+	return &CartService{
+		cartRepo:     cartRepo,
+		itemRepo:     itemRepo,
+		orderService: orderService,
+	}
+}
+
+// GetCart returns the customer's cart with its price breakdown. Customers without a cart get an empty one.
+func (s *CartService) GetCart(ctx context.Context, customerID primitive.ObjectID) (*models.Cart, error) {
+	cart, err := s.cartRepo.GetCartByCustomerID(ctx, customerID)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, err
+		}
+		cart = &models.Cart{CustomerID: customerID, Items: []models.CartItem{}}
+	}
+
+	// Lines saved before carts had line IDs and modifiers get them once, and are saved so the IDs
+	// sent to the client keep working
+	backfilled := false
+	for i := range cart.Items {
+		if cart.Items[i].LineID.IsZero() {
+			cart.Items[i].LineID = primitive.NewObjectID()
+			cart.Items[i].UnitPrice = cart.Items[i].Price
+			backfilled = true
+		}
+	}
+	if backfilled {
+		if err := s.cartRepo.SaveCart(ctx, cart); err != nil {
+			return nil, err
+		}
+	}
+
+	s.price(cart)
+	return cart, nil
+}
+
+// AddItem puts quantity of an item with the given modifiers in the cart. The modifiers are checked
+// against the item's modifier groups and priced server-side. Adding an item with the same modifiers
+// as an existing line increases that line's quantity. Unavailable items and items from a different
+// restaurant than the one already in the cart are refused.
+func (s *CartService) AddItem(ctx context.Context, customerID, itemID primitive.ObjectID, quantity int, selected []models.SelectedModifier) (*models.Cart, error) {
+	if quantity < 1 {
+		return nil, ErrInvalidQuantity
+	}
+
+	item, err := s.itemRepo.GetItemByID(ctx, itemID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrItemNotFound
+		}
+		return nil, err
+	}
+	if item.Status != models.ItemStatusAvailable {
+		return nil, ErrItemUnavailable
+	}
+	modifiers, unitPrice, err := priceLine(item, selected)
+	if err != nil {
+		return nil, err
+	}
+
+	cart, err := s.GetCart(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(cart.Items) > 0 && cart.RestaurantID != item.RestaurantID {
+		return nil, ErrCartRestaurantMismatch
+	}
+	cart.RestaurantID = item.RestaurantID
+
+	found := false
+	for i := range cart.Items {
+		if cart.Items[i].ItemID == itemID && sameModifiers(cart.Items[i].Modifiers, modifiers) {
+			cart.Items[i].Quantity += quantity
+			cart.Items[i].Name = item.Name
+			cart.Items[i].Price = item.Price
+			cart.Items[i].Modifiers = modifiers
+			cart.Items[i].UnitPrice = unitPrice
+			found = true
+			break
+		}
+	}
+	if !found {
+		cart.Items = append(cart.Items, models.CartItem{
+			LineID:    primitive.NewObjectID(),
+			ItemID:    item.ID,
+			Name:      item.Name,
+			Price:     item.Price,
+			Modifiers: modifiers,
+			UnitPrice: unitPrice,
+			Quantity:  quantity,
+		})
+	}
+
+	return s.save(ctx, cart)
+}
+
+// UpdateItemQuantity sets the quantity of a cart line. A quantity of 0 removes it.
+func (s *CartService) UpdateItemQuantity(ctx context.Context, customerID, lineID primitive.ObjectID, quantity int) (*models.Cart, error) {
+	if quantity < 0 {
+		return nil, ErrInvalidQuantity
+	}
+	if quantity == 0 {
+		return s.RemoveItem(ctx, customerID, lineID)
+	}
+
+	cart, err := s.GetCart(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range cart.Items {
+		if cart.Items[i].LineID == lineID {
+			cart.Items[i].Quantity = quantity
+			return s.save(ctx, cart)
+		}
+	}
+	return nil, ErrCartItemNotFound
+}
+
+func (s *CartService) RemoveItem(ctx context.Context, customerID, lineID primitive.ObjectID) (*models.Cart, error) {
+	cart, err := s.GetCart(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range cart.Items {
+		if cart.Items[i].LineID == lineID {
+			cart.Items = append(cart.Items[:i], cart.Items[i+1:]...)
+			return s.save(ctx, cart)
+		}
+	}
+	return nil, ErrCartItemNotFound
+}
+
+func (s *CartService) ClearCart(ctx context.Context, customerID primitive.ObjectID) error {
+	return s.cartRepo.DeleteCart(ctx, customerID)
+}
+
+// Checkout turns the cart into an order and empties the cart. Items and modifiers are re-validated
+// and re-priced by the order service, so a price change since the item was added is charged at the
+// current price. Once the order is placed checkout succeeds, even if the cart can't be emptied.
+func (s *CartService) Checkout(ctx context.Context, customerID primitive.ObjectID) (*models.Order, error) {
+	cart, err := s.GetCart(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(cart.Items) == 0 {
+		return nil, ErrCartEmpty
+	}
+
+	order := &models.Order{
+		CustomerID:   customerID,
+		RestaurantID: cart.RestaurantID,
+		Items:        make([]models.OrderItem, len(cart.Items)),
+	}
+	for i, line := range cart.Items {
+		order.Items[i] = models.OrderItem{ItemID: line.ItemID, Modifiers: line.Modifiers, Quantity: line.Quantity}
+	}
+
+	if err := s.orderService.CreateOrder(ctx, order); err != nil {
+		return nil, err
+	}
+	// Failing here would make the customer retry and place the order twice. The cart is emptied
+	// even if the request has been cancelled meanwhile, since the order is placed.
+	deleteCtx, cancel := detach(ctx)
+	defer cancel()
+	if err := s.cartRepo.DeleteCart(deleteCtx, customerID); err != nil {
+		log.Printf("Error emptying the cart of customer %s after placing order %s: %v", customerID.Hex(), order.ID.Hex(), err)
+	}
+
+	return order, nil
+}
+
+func (s *CartService) save(ctx context.Context, cart *models.Cart) (*models.Cart, error) {
+	if len(cart.Items) == 0 {
+		cart.RestaurantID = primitive.NilObjectID
+	}
+	if err := s.cartRepo.SaveCart(ctx, cart); err != nil {
+		return nil, err
+	}
+
+	s.price(cart)
+	return cart, nil
+}
+
+func (s *CartService) price(cart *models.Cart) {
+	var subtotal float64
+	for i := range cart.Items {
+		cart.Items[i].LineTotal = roundPrice(cart.Items[i].UnitPrice * float64(cart.Items[i].Quantity))
+		subtotal += cart.Items[i].LineTotal
+	}
+	cart.Pricing = calculatePricing(subtotal)
+}
+
+// sameModifiers reports whether two lines have the same options picked. Both are in menu order.
+func sameModifiers(a, b []models.SelectedModifier) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].OptionID != b[i].OptionID {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCartCheckout(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+	customerID := primitive.NewObjectID()
+	restaurant := s.createRestaurant(t, "Burger Barn")
+	burger := s.createItem(t, restaurant.ID, "Cheeseburger", 8.5, nil)
+	brulee := s.createItem(t, s.createRestaurant(t, "Crème Café").ID, "Crème Brûlée", 6, nil)
+
+	if _, err := s.carts.AddItem(ctx, customerID, burger.ID, 1, nil); err != nil {
+		t.Fatalf("AddItem: %v", err)
+	}
+	cart, err := s.carts.AddItem(ctx, customerID, burger.ID, 2, nil)
+	if err != nil {
+		t.Fatalf("AddItem: %v", err)
+	}
+	if len(cart.Items) != 1 || cart.Items[0].Quantity != 3 || cart.Pricing.Subtotal != 25.5 {
+		t.Errorf("cart = %+v, want one line of 3 cheeseburgers at 25.5", cart)
+	}
+	if _, err := s.carts.AddItem(ctx, customerID, brulee.ID, 1, nil); !errors.Is(err, ErrCartRestaurantMismatch) {
+		t.Errorf("item from another restaurant: err = %v, want ErrCartRestaurantMismatch", err)
+	}
+
+	order, err := s.carts.Checkout(ctx, customerID)
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if order.RestaurantID != restaurant.ID || order.Items[0].Quantity != 3 || order.Pricing.Subtotal != 25.5 {
+		t.Errorf("order = %+v, want the cart's 3 cheeseburgers", order)
+	}
+
+	cart, err = s.carts.GetCart(ctx, customerID)
+	if err != nil {
+		t.Fatalf("GetCart: %v", err)
+	}
+	if len(cart.Items) != 0 {
+		t.Errorf("cart after checkout has %d lines, want none", len(cart.Items))
+	}
+	if _, err := s.carts.Checkout(ctx, customerID); !errors.Is(err, ErrCartEmpty) {
+		t.Errorf("checkout of an empty cart: err = %v, want ErrCartEmpty", err)
+	}
+}
+
+func TestCartKeepsBackfilledLineIDs(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+	customerID := primitive.NewObjectID()
+	restaurant := s.createRestaurant(t, "Burger Barn")
+	burger := s.createItem(t, restaurant.ID, "Cheeseburger", 8.5, nil)
+
+	// A cart saved before lines had IDs
+	legacy := &models.Cart{
+		CustomerID:   customerID,
+		RestaurantID: restaurant.ID,
+		Items:        []models.CartItem{{ItemID: burger.ID, Name: burger.Name, Price: burger.Price, Quantity: 1}},
+	}
+	if err := s.repos.Carts.SaveCart(ctx, legacy); err != nil {
+		t.Fatalf("SaveCart: %v", err)
+	}
+
+	cart, err := s.carts.GetCart(ctx, customerID)
+	if err != nil {
+		t.Fatalf("GetCart: %v", err)
+	}
+	lineID := cart.Items[0].LineID
+	if lineID.IsZero() || cart.Items[0].UnitPrice != 8.5 {
+		t.Fatalf("line = %+v, want a line ID and the unit price", cart.Items[0])
+	}
+
+	cart, err = s.carts.UpdateItemQuantity(ctx, customerID, lineID, 4)
+	if err != nil {
+		t.Fatalf("UpdateItemQuantity with the backfilled line ID: %v", err)
+	}
+	if cart.Items[0].LineID != lineID || cart.Items[0].Quantity != 4 {
+		t.Errorf("line = %+v, want line %s with 4", cart.Items[0], lineID.Hex())
+	}
+}
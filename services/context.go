@@ -0,0 +1,17 @@
+package services
+
+import (
+	"context"
+	"time"
+)
+
+// compensationTimeout bounds writes that undo or finish up after a request, such as putting back
+// reserved stock
+const compensationTimeout = 5 * time.Second
+
+// detach returns a context for a write that must run even though the request's context may be
+// cancelled or past its deadline already, so that state isn't left half changed. It keeps the
+// request's values and has its own short deadline.
+func detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), compensationTimeout)
+}
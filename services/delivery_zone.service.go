@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/aldiandyaIrsyad/uber-eats/repos"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrInvalidDeliveryArea  = InvalidField("area", "invalid_delivery_area", "area must be a GeoJSON Polygon of closed rings with at least 4 [longitude, latitude] positions")
+	ErrDeliveryZoneNotFound = NotFound("delivery_zone_not_found", "delivery zone not found")
+	ErrInvalidZoneName      = InvalidField("name", "invalid_zone_name", "name must be 2 to 100 characters")
+	ErrInvalidDeliveryFee   = InvalidField("deliveryFee", "invalid_delivery_fee", "deliveryFee can't be negative")
+	ErrInvalidMinimumOrder  = InvalidField("minimumOrder", "invalid_minimum_order", "minimumOrder can't be negative")
+)
+
+type DeliveryZoneService struct {
+	zoneRepo       repos.DeliveryZoneRepository
+	restaurantRepo repos.RestaurantRepository
+}
+
+func NewDeliveryZoneService(zoneRepo repos.DeliveryZoneRepository, restaurantRepo repos.RestaurantRepository) *DeliveryZoneService {
+	return &DeliveryZoneService{
+		zoneRepo:       zoneRepo,
+		restaurantRepo: restaurantRepo,
+	}
+}
+
+func (s *DeliveryZoneService) CreateZone(ctx context.Context, zone *models.DeliveryZone) error {
+	if err := checkZone(zone); err != nil {
+		return err
+	}
+	if _, err := s.restaurantRepo.GetRestaurantOwnerID(ctx, zone.RestaurantID); err != nil {
+		return notFound(err, ErrRestaurantNotFound)
+	}
+
+	return rejectedArea(s.zoneRepo.CreateZone(ctx, zone))
+}
+
+func (s *DeliveryZoneService) GetZonesByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID) ([]models.DeliveryZone, error) {
+	return s.zoneRepo.GetZonesByRestaurantID(ctx, restaurantID)
+}
+
+func (s *DeliveryZoneService) UpdateZone(ctx context.Context, zone *models.DeliveryZone) error {
+	if err := checkZone(zone); err != nil {
+		return err
+	}
+	return notFound(rejectedArea(s.zoneRepo.UpdateZone(ctx, zone)), ErrDeliveryZoneNotFound)
+}
+
+func (s *DeliveryZoneService) DeleteZone(ctx context.Context, restaurantID, zoneID primitive.ObjectID) error {
+	return notFound(s.zoneRepo.DeleteZone(ctx, restaurantID, zoneID), ErrDeliveryZoneNotFound)
+}
+
+// CheckDelivery reports whether the restaurant delivers to the coordinate. When several zones
+// overlap there, the cheapest one applies.
+func (s *DeliveryZoneService) CheckDelivery(ctx context.Context, restaurantID primitive.ObjectID, lat, lng float64) (*models.DeliveryQuote, error) {
+	if _, err := s.restaurantRepo.GetRestaurantOwnerID(ctx, restaurantID); err != nil {
+		return nil, notFound(err, ErrRestaurantNotFound)
+	}
+
+	quote := &models.DeliveryQuote{RestaurantID: restaurantID}
+	zone, err := s.zoneRepo.FindZoneContaining(ctx, restaurantID, lng, lat)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return quote, nil
+		}
+		return nil, err
+	}
+
+	quote.Deliverable = true
+	quote.Zone = zone
+	quote.DeliveryFee = zone.DeliveryFee
+	quote.MinimumOrder = zone.MinimumOrder
+	return quote, nil
+}
+
+// checkZone validates the fields of a zone its owner sets
+func checkZone(zone *models.DeliveryZone) error {
+	if length := utf8.RuneCountInString(strings.TrimSpace(zone.Name)); length < 2 || length > 100 {
+		return ErrInvalidZoneName
+	}
+	if zone.DeliveryFee < 0 {
+		return ErrInvalidDeliveryFee
+	}
+	if zone.MinimumOrder < 0 {
+		return ErrInvalidMinimumOrder
+	}
+	if !validDeliveryArea(zone.Area) {
+		return ErrInvalidDeliveryArea
+	}
+	return nil
+}
+
+// rejectedArea replaces the error of a 2dsphere index refusing a polygon that passed
+// validDeliveryArea, such as one whose edges cross, with ErrInvalidDeliveryArea
+func rejectedArea(err error) error {
+	var serverErr mongo.ServerError
+	// 16755 is MongoDB's "Can't extract geo keys"
+	if errors.As(err, &serverErr) && serverErr.HasErrorCode(16755) {
+		return fmt.Errorf("%w: the polygon is not valid, for instance its edges cross", ErrInvalidDeliveryArea)
+	}
+	return err
+}
+
+// validDeliveryArea checks the polygon shape before it reaches the 2dsphere index, which would
+// otherwise reject it with a less helpful driver error
+func validDeliveryArea(area models.GeoPolygon) bool {
+	if area.Type != "Polygon" || len(area.Coordinates) == 0 {
+		return false
+	}
+
+	for _, ring := range area.Coordinates {
+		if len(ring) < 4 {
+			return false
+		}
+		for _, position := range ring {
+			if len(position) != 2 || position[0] < -180 || position[0] > 180 || position[1] < -90 || position[1] > 90 {
+				return false
+			}
+		}
+		first, last := ring[0], ring[len(ring)-1]
+		if first[0] != last[0] || first[1] != last[1] {
+			return false
+		}
+	}
+	return true
+}
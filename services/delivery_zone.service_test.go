@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// square returns a polygon covering size degrees on each side of a point
+func square(lng, lat, size float64) models.GeoPolygon {
+	return models.GeoPolygon{Type: "Polygon", Coordinates: [][][]float64{{
+		{lng - size, lat - size}, {lng + size, lat - size}, {lng + size, lat + size}, {lng - size, lat + size}, {lng - size, lat - size},
+	}}}
+}
+
+func TestDeliveryZoneServiceQuotesCheapestZone(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+	restaurant := s.createRestaurant(t, "Burger Barn")
+
+	zones := []*models.DeliveryZone{
+		{RestaurantID: restaurant.ID, Name: "Far", Area: square(0, 0, 1), DeliveryFee: 5, MinimumOrder: 20},
+		{RestaurantID: restaurant.ID, Name: "Near", Area: square(0, 0, 0.1), DeliveryFee: 2, MinimumOrder: 10},
+	}
+	for _, zone := range zones {
+		if err := s.zones.CreateZone(ctx, zone); err != nil {
+			t.Fatalf("CreateZone: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		lat, lng float64
+		zone     string
+	}{
+		{"inside both zones", 0.05, 0.05, "Near"},
+		{"inside the outer zone", 0.5, 0.5, "Far"},
+		{"outside", 2, 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := s.zones.CheckDelivery(ctx, restaurant.ID, tt.lat, tt.lng)
+			if err != nil {
+				t.Fatalf("CheckDelivery: %v", err)
+			}
+			if tt.zone == "" {
+				if quote.Deliverable {
+					t.Errorf("delivers through %s, want no delivery", quote.Zone.Name)
+				}
+				return
+			}
+			if !quote.Deliverable || quote.Zone.Name != tt.zone || quote.DeliveryFee != quote.Zone.DeliveryFee {
+				t.Errorf("quote = %+v, want zone %s", quote, tt.zone)
+			}
+		})
+	}
+}
+
+func TestDeliveryZoneServiceRefusesInvalidZones(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+	restaurant := s.createRestaurant(t, "Burger Barn")
+
+	open := square(0, 0, 1)
+	open.Coordinates[0] = open.Coordinates[0][:4]
+	if err := s.zones.CreateZone(ctx, &models.DeliveryZone{RestaurantID: restaurant.ID, Name: "Open", Area: open}); !errors.Is(err, ErrInvalidDeliveryArea) {
+		t.Errorf("ring that isn't closed: err = %v, want ErrInvalidDeliveryArea", err)
+	}
+	if err := s.zones.CreateZone(ctx, &models.DeliveryZone{RestaurantID: primitive.NewObjectID(), Name: "Lost", Area: square(0, 0, 1)}); !errors.Is(err, ErrRestaurantNotFound) {
+		t.Errorf("unknown restaurant: err = %v, want ErrRestaurantNotFound", err)
+	}
+	if err := s.zones.DeleteZone(ctx, restaurant.ID, primitive.NewObjectID()); !errors.Is(err, ErrDeliveryZoneNotFound) {
+		t.Errorf("delete of an unknown zone: err = %v, want ErrDeliveryZoneNotFound", err)
+	}
+
+	tests := []struct {
+		name string
+		zone models.DeliveryZone
+		want error
+	}{
+		{"no name", models.DeliveryZone{Name: "  ", Area: square(0, 0, 1)}, ErrInvalidZoneName},
+		{"negative fee", models.DeliveryZone{Name: "Near", Area: square(0, 0, 1), DeliveryFee: -2}, ErrInvalidDeliveryFee},
+		{"negative minimum", models.DeliveryZone{Name: "Near", Area: square(0, 0, 1), MinimumOrder: -10}, ErrInvalidMinimumOrder},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.zone.RestaurantID = restaurant.ID
+			if err := s.zones.CreateZone(ctx, &tt.zone); !errors.Is(err, tt.want) {
+				t.Errorf("CreateZone: err = %v, want %v", err, tt.want)
+			}
+			if err := s.zones.UpdateZone(ctx, &tt.zone); !errors.Is(err, tt.want) {
+				t.Errorf("UpdateZone: err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRejectedArea(t *testing.T) {
+	geoKeys := mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 16755, Message: "Can't extract geo keys: Edges 0 and 2 cross"}}}
+	if err := rejectedArea(geoKeys); !errors.Is(err, ErrInvalidDeliveryArea) {
+		t.Errorf("geo keys error: err = %v, want ErrInvalidDeliveryArea", err)
+	}
+	duplicate := mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000, Message: "duplicate key"}}}
+	if err := rejectedArea(duplicate); errors.Is(err, ErrInvalidDeliveryArea) {
+		t.Errorf("duplicate key error turned into ErrInvalidDeliveryArea")
+	}
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+)
+
+// Kind is what went wrong with a request, in terms of what its caller can do about it
+type Kind int
+
+const (
+	// KindNotFound means the resource doesn't exist
+	KindNotFound Kind = iota + 1
+	// KindConflict means the request clashes with the current state of the resource
+	KindConflict
+	// KindValidation means the input is invalid and has to be fixed before it is sent again
+	KindValidation
+	// KindUnauthorized means the caller isn't authenticated
+	KindUnauthorized
+	// KindForbidden means the caller is authenticated but may not do this
+	KindForbidden
+	// KindUnavailable means the database failed or answered too late; the same request may work later
+	KindUnavailable
+)
+
+// FieldError is what is wrong with one field of the input
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error is an error the services report to their callers. Code identifies it to API clients and
+// Message is safe to show them; Err is the underlying cause, which isn't.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	// Field names the input field a validation error is about, if it is about one
+	Field string
+	// Fields lists every invalid field when several are found at once
+	Fields []FieldError
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func NotFound(code, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+func Conflict(code, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+// Validation reports invalid input, with the problems of each field if they are known
+func Validation(code, message string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message, Fields: fields}
+}
+
+// InvalidField reports invalid input in a single field
+func InvalidField(field, code, message string) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message, Field: field}
+}
+
+func Unauthorized(code, message string) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Message: message}
+}
+
+func Forbidden(code, message string) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: message}
+}
+
+// ErrNotFound is returned for a missing resource no service has a more specific error for
+var ErrNotFound = NotFound("not_found", "resource not found")
+
+// AsError returns the service error err is or wraps. Errors from the repositories are classified
+// here: a missing document is not found and a duplicate key is a conflict. A database that can't
+// be reached or runs out of time is unavailable, and so is a request its caller cancelled. It
+// returns nil for anything else, which is a bug and must not be shown to the caller.
+func AsError(err error) *Error {
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return serviceErr
+	}
+
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return ErrNotFound
+	case mongo.IsDuplicateKeyError(err):
+		return &Error{Kind: KindConflict, Code: "duplicate", Message: "resource already exists", Err: err}
+	case errors.Is(err, context.Canceled):
+		return &Error{Kind: KindUnavailable, Code: "request_cancelled", Message: "the request was cancelled", Err: err}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{Kind: KindUnavailable, Code: "timeout", Message: "the request took too long", Err: err}
+	case mongo.IsTimeout(err), mongo.IsNetworkError(err), errors.Is(err, mongo.ErrClientDisconnected),
+		errors.As(err, new(topology.ServerSelectionError)):
+		return &Error{Kind: KindUnavailable, Code: "database_unavailable", Message: "the database is unavailable", Err: err}
+	}
+	return nil
+}
+
+// notFound replaces a repository's missing document with the service's own not found error
+func notFound(err error, notFoundErr *Error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return notFoundErr
+	}
+	return err
+}
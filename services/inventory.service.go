@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/aldiandyaIrsyad/uber-eats/repos"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrInvalidInventory       = InvalidField("inventory", "invalid_inventory", "invalid inventory")
+	ErrInvalidStockAdjustment = InvalidField("adjustments", "invalid_stock_adjustment", "invalid stock adjustment")
+	ErrOutOfStock             = Conflict("out_of_stock", "not enough stock")
+)
+
+type InventoryService struct {
+	itemRepo       repos.ItemRepository
+	restaurantRepo repos.RestaurantRepository
+}
+
+func NewInventoryService(itemRepo repos.ItemRepository, restaurantRepo repos.RestaurantRepository) *InventoryService {
+	return &InventoryService{
+		itemRepo:       itemRepo,
+		restaurantRepo: restaurantRepo,
+	}
+}
+
+// AdjustStock sets or changes the stock of several of the restaurant's tracked items at once
+func (s *InventoryService) AdjustStock(ctx context.Context, restaurantID primitive.ObjectID, adjustments []models.StockAdjustment) error {
+	if len(adjustments) == 0 {
+		return fmt.Errorf("%w: no adjustments", ErrInvalidStockAdjustment)
+	}
+
+	itemIDs := make([]primitive.ObjectID, 0, len(adjustments))
+	seen := make(map[primitive.ObjectID]bool, len(adjustments))
+	for _, adjustment := range adjustments {
+		if (adjustment.Stock == nil) == (adjustment.Delta == nil) {
+			return fmt.Errorf("%w: set exactly one of stock or delta for item %s", ErrInvalidStockAdjustment, adjustment.ItemID.Hex())
+		}
+		if adjustment.Stock != nil && *adjustment.Stock < 0 {
+			return fmt.Errorf("%w: stock cannot be negative", ErrInvalidStockAdjustment)
+		}
+		if seen[adjustment.ItemID] {
+			return fmt.Errorf("%w: item %s is adjusted more than once", ErrInvalidStockAdjustment, adjustment.ItemID.Hex())
+		}
+		seen[adjustment.ItemID] = true
+		itemIDs = append(itemIDs, adjustment.ItemID)
+	}
+
+	// Checked up front so a bad item doesn't leave the batch half applied
+	count, err := s.itemRepo.CountItems(ctx, bson.M{
+		"_id":          bson.M{"$in": itemIDs},
+		"restaurantId": restaurantID,
+		"inventory":    bson.M{"$exists": true},
+	})
+	if err != nil {
+		return err
+	}
+	if count != int64(len(itemIDs)) {
+		return fmt.Errorf("%w: items must be stock-tracked items of this restaurant", ErrInvalidStockAdjustment)
+	}
+
+	_, err = s.itemRepo.AdjustStock(ctx, restaurantID, adjustments)
+	return err
+}
+
+// SetStatus sets the status of a tracked item. Its status follows its stock, so making an item
+// with no stock available leaves it unavailable, marked sold out until it is restocked.
+func (s *InventoryService) SetStatus(ctx context.Context, id primitive.ObjectID, status string) error {
+	return s.itemRepo.SetStockedStatus(ctx, id, status)
+}
+
+// ReserveStock takes the ordered quantities from the stock of tracked items. It succeeds for all
+// items or none: if one runs short, what was already taken is put back.
+func (s *InventoryService) ReserveStock(ctx context.Context, lines []models.OrderItem) error {
+	quantities, itemIDs := orderQuantities(lines)
+
+	var reserved []primitive.ObjectID
+	for _, itemID := range itemIDs {
+		item, err := s.itemRepo.GetItemByID(ctx, itemID)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		}
+		if err != nil {
+			s.release(ctx, reserved, quantities)
+			return err
+		}
+		if item.Inventory == nil {
+			continue
+		}
+
+		ok, err := s.itemRepo.DecrementStock(ctx, itemID, quantities[itemID])
+		if err != nil {
+			s.release(ctx, reserved, quantities)
+			return err
+		}
+		if !ok {
+			s.release(ctx, reserved, quantities)
+			return fmt.Errorf("%w: %s", ErrOutOfStock, item.Name)
+		}
+		reserved = append(reserved, itemID)
+	}
+	return nil
+}
+
+// ReleaseStock puts the ordered quantities back into the stock of tracked items. It runs even if ctx
+// is done, since it undoes a reservation.
+func (s *InventoryService) ReleaseStock(ctx context.Context, lines []models.OrderItem) {
+	quantities, itemIDs := orderQuantities(lines)
+	s.release(ctx, itemIDs, quantities)
+}
+
+func (s *InventoryService) release(ctx context.Context, itemIDs []primitive.ObjectID, quantities map[primitive.ObjectID]int) {
+	ctx, cancel := detach(ctx)
+	defer cancel()
+	for _, itemID := range itemIDs {
+		if err := s.itemRepo.IncrementStock(ctx, itemID, quantities[itemID]); err != nil {
+			log.Printf("Error releasing stock of item %s: %v", itemID.Hex(), err)
+		}
+	}
+}
+
+// orderQuantities adds up the quantity ordered of each item, since an item can be on several lines
+// with different modifiers. The IDs are returned in order of first appearance.
+func orderQuantities(lines []models.OrderItem) (map[primitive.ObjectID]int, []primitive.ObjectID) {
+	quantities := make(map[primitive.ObjectID]int, len(lines))
+	var itemIDs []primitive.ObjectID
+	for _, line := range lines {
+		if _, ok := quantities[line.ItemID]; !ok {
+			itemIDs = append(itemIDs, line.ItemID)
+		}
+		quantities[line.ItemID] += line.Quantity
+	}
+	return quantities, itemIDs
+}
+
+// ResetDueStock resets the stock of every item whose daily reset is due and returns how many
+func (s *InventoryService) ResetDueStock(ctx context.Context, now time.Time) (int, error) {
+	items, err := s.itemRepo.GetItemsDueForReset(ctx, now)
+	if err != nil || len(items) == 0 {
+		return 0, err
+	}
+
+	restaurantIDs := make([]primitive.ObjectID, 0, len(items))
+	for _, item := range items {
+		restaurantIDs = append(restaurantIDs, item.RestaurantID)
+	}
+	timezones, err := s.restaurantRepo.GetTimezones(ctx, bson.M{"_id": bson.M{"$in": restaurantIDs}})
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range items {
+		item := &items[i]
+		if item.Inventory.DailyStock == nil {
+			continue
+		}
+		loc, err := time.LoadLocation(timezones[item.RestaurantID])
+		if err != nil {
+			loc = time.UTC
+		}
+		if err := s.itemRepo.ResetStock(ctx, item, item.Inventory.NextReset(now, loc)); err != nil {
+			return i, err
+		}
+	}
+	return len(items), nil
+}
+
+// RunDailyResets resets due stock every interval until ctx is cancelled
+func (s *InventoryService) RunDailyResets(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if count, err := s.ResetDueStock(ctx, time.Now()); err != nil {
+			log.Printf("Error resetting daily stock: %v", err)
+		} else if count > 0 {
+			log.Printf("Reset daily stock of %d items", count)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// normalizeInventory validates an item's inventory in the timezone of its restaurant
+func normalizeInventory(ctx context.Context, restaurantRepo repos.RestaurantRepository, restaurantID primitive.ObjectID, inventory *models.Inventory) error {
+	restaurant, err := restaurantRepo.GetRestaurantHours(ctx, restaurantID)
+	if err != nil {
+		return notFound(err, ErrRestaurantNotFound)
+	}
+	loc, err := restaurant.TimeLocation()
+	if err != nil {
+		loc = time.UTC
+	}
+	if err := inventory.Normalize(time.Now(), loc); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidInventory, err)
+	}
+	return nil
+}
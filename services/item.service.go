@@ -2,6 +2,9 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/aldiandyaIrsyad/uber-eats/models"
 	"github.com/aldiandyaIrsyad/uber-eats/repos"
@@ -9,34 +12,235 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+var (
+	ErrInvalidItemPosition   = InvalidField("position", "invalid_position", "position must be a whole number")
+	ErrInvalidModifierGroups = InvalidField("modifierGroups", "invalid_modifier_groups", "invalid modifier groups")
+	ErrAtNeedsRestaurant     = InvalidField("at", "at_needs_restaurant", "at needs restaurantID or a restaurantId:eq filter")
+	ErrInvalidItemStatus     = InvalidField("status", "invalid_status", "status must be available or unavailable")
+	ErrEmptyItemUpdate       = Validation("empty_update", "the update has no fields that can be changed")
+)
+
+// itemUpdatableFields are the fields an owner may change. The restaurant is left out, since access
+// is granted per restaurant, and so is the stock, which moves through the inventory service.
+var itemUpdatableFields = []string{"name", "description", "price", "imageUrl", "status", "position", "categoryId", "modifierGroups", "inventory"}
+
 type ItemService struct {
-	itemRepo *repos.ItemRepository
+	itemRepo       repos.ItemRepository
+	categoryRepo   repos.MenuCategoryRepository
+	menuRepo       repos.MenuRepository
+	restaurantRepo repos.RestaurantRepository
+
+	inventoryService *InventoryService
+	suggestService   *SuggestService
 }
 
-func NewItemService(itemRepo *repos.ItemRepository) *ItemService {
+func NewItemService(itemRepo repos.ItemRepository, categoryRepo repos.MenuCategoryRepository, menuRepo repos.MenuRepository, restaurantRepo repos.RestaurantRepository, inventoryService *InventoryService, suggestService *SuggestService) *ItemService {
 	return &ItemService{
-		itemRepo: itemRepo,
+		itemRepo:         itemRepo,
+		categoryRepo:     categoryRepo,
+		menuRepo:         menuRepo,
+		restaurantRepo:   restaurantRepo,
+		inventoryService: inventoryService,
+		suggestService:   suggestService,
 	}
 }
 
 func (s *ItemService) CreateItem(ctx context.Context, item *models.Item) error {
-	return s.itemRepo.CreateItem(ctx, item)
+	if err := normalizeModifierGroups(item.ModifierGroups); err != nil {
+		return err
+	}
+	if item.CategoryID != nil {
+		if err := checkCategory(ctx, s.categoryRepo, item.RestaurantID, *item.CategoryID); err != nil {
+			return err
+		}
+	}
+	if item.Inventory != nil {
+		item.Inventory.SoldOut = false
+		if err := normalizeInventory(ctx, s.restaurantRepo, item.RestaurantID, item.Inventory); err != nil {
+			return err
+		}
+		item.ApplyStockStatus()
+	}
+	if err := s.itemRepo.CreateItem(ctx, item); err != nil {
+		return err
+	}
+	s.suggestService.IndexItem(item)
+	return nil
 }
 
 func (s *ItemService) GetItemByID(ctx context.Context, id primitive.ObjectID) (*models.Item, error) {
-	return s.itemRepo.GetItemByID(ctx, id)
+	item, err := s.itemRepo.GetItemByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrItemNotFound)
+	}
+	return item, nil
 }
 
 func (s *ItemService) UpdateItem(ctx context.Context, id primitive.ObjectID, update map[string]interface{}) error {
-	updateBson := bson.M{"$set": update}
-	return s.itemRepo.UpdateItem(ctx, id, updateBson)
+	if err := checkUpdateFields(update, itemUpdatableFields); err != nil {
+		return err
+	}
+	if len(update) == 0 {
+		return ErrEmptyItemUpdate
+	}
+	item, err := s.GetItemByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	unset := bson.M{}
+
+	if value, ok := update["status"]; ok {
+		if status, _ := value.(string); status != models.ItemStatusAvailable && status != models.ItemStatusUnavailable {
+			return ErrInvalidItemStatus
+		}
+	}
+
+	if value, ok := update["position"]; ok {
+		position, ok := value.(float64)
+		if !ok || position != float64(int(position)) {
+			return ErrInvalidItemPosition
+		}
+		update["position"] = int(position)
+	}
+
+	if value, ok := update["modifierGroups"]; ok {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidModifierGroups, err)
+		}
+		var groups []models.ModifierGroup
+		if err := json.Unmarshal(raw, &groups); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidModifierGroups, err)
+		}
+		if err := normalizeModifierGroups(groups); err != nil {
+			return err
+		}
+		update["modifierGroups"] = groups
+	}
+
+	// A null category moves the item out of its category
+	if value, ok := update["categoryId"]; ok {
+		delete(update, "categoryId")
+		if value == nil {
+			unset["categoryId"] = ""
+		} else {
+			hex, _ := value.(string)
+			categoryID, err := primitive.ObjectIDFromHex(hex)
+			if err != nil {
+				return ErrInvalidCategory
+			}
+			if err := checkCategory(ctx, s.categoryRepo, item.RestaurantID, categoryID); err != nil {
+				return err
+			}
+			update["categoryId"] = categoryID
+		}
+	}
+
+	if value, ok := update["inventory"]; ok {
+		delete(update, "inventory")
+		if err := s.updateInventory(ctx, item, value, update, unset); err != nil {
+			return err
+		}
+	}
+
+	// The status of an item that stays tracked follows its stock, so it is set through the
+	// inventory service
+	status, _ := update["status"].(string)
+	if _, untracked := unset["inventory"]; item.Inventory == nil || untracked {
+		status = ""
+	} else {
+		delete(update, "status")
+	}
+
+	updateBson := bson.M{}
+	if len(update) > 0 {
+		updateBson["$set"] = update
+	}
+	if len(unset) > 0 {
+		updateBson["$unset"] = unset
+	}
+	if len(updateBson) > 0 {
+		if err := s.itemRepo.UpdateItem(ctx, id, updateBson); err != nil {
+			return notFound(err, ErrItemNotFound)
+		}
+	}
+	if status != "" {
+		if err := s.inventoryService.SetStatus(ctx, id, status); err != nil {
+			return err
+		}
+	}
+	// The restaurant is indexed with the name, so the item leaves the index with its restaurant
+	if name, ok := update["name"].(string); ok {
+		s.suggestService.IndexItem(&models.Item{ID: id, RestaurantID: item.RestaurantID, Name: name})
+	}
+	return nil
+}
+
+// updateInventory adds an inventory change to an item update. A null inventory stops tracking
+// stock. For an item already tracked only the daily reset settings change, since stock itself
+// moves with orders and through InventoryService.AdjustStock; an untracked item starts with the
+// given stock.
+func (s *ItemService) updateInventory(ctx context.Context, item *models.Item, value interface{}, set, unset bson.M) error {
+	if value == nil {
+		unset["inventory"] = ""
+		if _, ok := set["status"]; !ok && item.Inventory != nil && item.Inventory.SoldOut {
+			set["status"] = models.ItemStatusAvailable
+		}
+		return nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidInventory, err)
+	}
+	var inventory models.Inventory
+	if err := json.Unmarshal(raw, &inventory); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidInventory, err)
+	}
+	if item.Inventory != nil {
+		inventory.Stock = item.Inventory.Stock
+	}
+	if err := normalizeInventory(ctx, s.restaurantRepo, item.RestaurantID, &inventory); err != nil {
+		return err
+	}
+
+	if item.Inventory == nil {
+		tracked := *item
+		if status, ok := set["status"].(string); ok {
+			tracked.Status = status
+		}
+		tracked.Inventory = &inventory
+		tracked.ApplyStockStatus()
+		set["inventory"] = tracked.Inventory
+		set["status"] = tracked.Status
+		return nil
+	}
+
+	if inventory.DailyStock == nil {
+		unset["inventory.dailyStock"] = ""
+		unset["inventory.resetTime"] = ""
+		unset["inventory.nextResetAt"] = ""
+		return nil
+	}
+	set["inventory.dailyStock"] = inventory.DailyStock
+	set["inventory.resetTime"] = inventory.ResetTime
+	set["inventory.nextResetAt"] = inventory.NextResetAt
+	return nil
 }
 
 func (s *ItemService) DeleteItem(ctx context.Context, id primitive.ObjectID) error {
-	return s.itemRepo.DeleteItem(ctx, id)
+	if err := s.itemRepo.DeleteItem(ctx, id); err != nil {
+		return err
+	}
+	s.suggestService.Remove(id)
+	return nil
 }
 
-func (s *ItemService) GetItems(ctx context.Context, queryOpts models.QueryOptions) (*models.Pagination, error) {
+// GetItems lists items. With at, only items that can be ordered at that moment are listed: the item
+// is available, its restaurant is open and one of its menus, if it has any, is on. at needs the
+// listing to be narrowed to one restaurant, so only that restaurant's menus are loaded.
+func (s *ItemService) GetItems(ctx context.Context, queryOpts models.QueryOptions, at *time.Time) (*models.Page[models.Item], error) {
 	// Set default values if not provided
 	if queryOpts.Pagination == nil {
 		queryOpts.Pagination = &models.PaginationOptions{
@@ -45,5 +249,110 @@ func (s *ItemService) GetItems(ctx context.Context, queryOpts models.QueryOption
 		}
 	}
 
+	if at != nil {
+		filter, err := s.orderableFilter(ctx, queryOpts.Filter, *at)
+		if err != nil {
+			return nil, err
+		}
+		queryOpts.Filter = filter
+	}
+
 	return s.itemRepo.FindWithOptions(ctx, queryOpts)
 }
+
+// orderableFilter narrows an item filter for a single restaurant to the items that can be ordered
+// at t
+func (s *ItemService) orderableFilter(ctx context.Context, filter map[string]interface{}, t time.Time) (map[string]interface{}, error) {
+	restaurantID, ok := filteredRestaurant(filter)
+	if !ok {
+		return nil, ErrAtNeedsRestaurant
+	}
+	restaurantFilter, err := s.restaurantRepo.OpenAtFilter(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	restaurantFilter = bson.M{"$and": bson.A{restaurantFilter, bson.M{"_id": restaurantID}}}
+
+	timezones, err := s.restaurantRepo.GetTimezones(ctx, restaurantFilter)
+	if err != nil {
+		return nil, err
+	}
+	availability, err := menuAvailability(ctx, s.menuRepo, timezones, t)
+	if err != nil {
+		return nil, err
+	}
+
+	openRestaurants := make([]primitive.ObjectID, 0, len(timezones))
+	for restaurantID := range timezones {
+		openRestaurants = append(openRestaurants, restaurantID)
+	}
+
+	conditions := bson.A{
+		bson.M{"status": models.ItemStatusAvailable},
+		bson.M{"restaurantId": bson.M{"$in": openRestaurants}},
+		repos.MenuAvailabilityFilter(availability),
+	}
+	if len(filter) > 0 {
+		conditions = append(conditions, filter)
+	}
+	return bson.M{"$and": conditions}, nil
+}
+
+// filteredRestaurant returns the one restaurant an item filter is narrowed to, given as restaurantID
+// or as a restaurantId:eq filter
+func filteredRestaurant(filter map[string]interface{}) (primitive.ObjectID, bool) {
+	switch value := filter["restaurantId"].(type) {
+	case primitive.ObjectID:
+		return value, true
+	case map[string]interface{}:
+		restaurantID, ok := value["$eq"].(primitive.ObjectID)
+		return restaurantID, ok
+	}
+	return primitive.NilObjectID, false
+}
+
+// normalizeModifierGroups checks the selection rules of an item's modifier groups, gives new groups
+// and options an ID and makes options available unless they say otherwise
+func normalizeModifierGroups(groups []models.ModifierGroup) error {
+	optionIDs := make(map[primitive.ObjectID]bool)
+	for i := range groups {
+		group := &groups[i]
+		if group.ID.IsZero() {
+			group.ID = primitive.NewObjectID()
+		}
+		if group.Name == "" {
+			return fmt.Errorf("%w: every group needs a name", ErrInvalidModifierGroups)
+		}
+		if len(group.Options) == 0 {
+			return fmt.Errorf("%w: %s has no options", ErrInvalidModifierGroups, group.Name)
+		}
+		if group.MinSelections < 0 || group.MaxSelections < 1 || group.MaxSelections < group.MinSelections {
+			return fmt.Errorf("%w: %s needs 0 <= minSelections <= maxSelections and maxSelections >= 1", ErrInvalidModifierGroups, group.Name)
+		}
+		if group.MinSelections > len(group.Options) {
+			return fmt.Errorf("%w: %s requires more selections than it has options", ErrInvalidModifierGroups, group.Name)
+		}
+
+		for j := range group.Options {
+			option := &group.Options[j]
+			if option.ID.IsZero() {
+				option.ID = primitive.NewObjectID()
+			}
+			if optionIDs[option.ID] {
+				return fmt.Errorf("%w: option IDs must be unique", ErrInvalidModifierGroups)
+			}
+			optionIDs[option.ID] = true
+
+			if option.Name == "" {
+				return fmt.Errorf("%w: every option in %s needs a name", ErrInvalidModifierGroups, group.Name)
+			}
+			if option.Status == "" {
+				option.Status = models.ItemStatusAvailable
+			}
+			if option.Status != models.ItemStatusAvailable && option.Status != models.ItemStatusUnavailable {
+				return fmt.Errorf("%w: option status must be available or unavailable", ErrInvalidModifierGroups)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestItemServiceOrderableAt(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+	restaurant := s.createRestaurant(t, "Burger Barn")
+	burger := s.createItem(t, restaurant.ID, "Cheeseburger", 8.5, nil)
+	fries := s.createItem(t, restaurant.ID, "Fries", 3, nil)
+	s.createItem(t, s.createRestaurant(t, "Crème Café").ID, "Crème Brûlée", 6, nil)
+
+	if err := s.items.UpdateItem(ctx, fries.ID, map[string]interface{}{"status": models.ItemStatusUnavailable}); err != nil {
+		t.Fatalf("UpdateItem: %v", err)
+	}
+
+	now := time.Now()
+	if _, err := s.items.GetItems(ctx, models.QueryOptions{}, &now); !errors.Is(err, ErrAtNeedsRestaurant) {
+		t.Errorf("at without a restaurant: err = %v, want ErrAtNeedsRestaurant", err)
+	}
+
+	page, err := s.items.GetItems(ctx, models.QueryOptions{Filter: map[string]interface{}{"restaurantId": restaurant.ID}}, &now)
+	if err != nil {
+		t.Fatalf("GetItems: %v", err)
+	}
+	if len(page.Data) != 1 || page.Data[0].ID != burger.ID {
+		t.Errorf("orderable items = %+v, want only the cheeseburger", page.Data)
+	}
+
+	page, err = s.items.GetItems(ctx, models.QueryOptions{Filter: map[string]interface{}{"restaurantId": restaurant.ID}}, nil)
+	if err != nil {
+		t.Fatalf("GetItems: %v", err)
+	}
+	if len(page.Data) != 2 {
+		t.Errorf("listed %d items without at, want both of the restaurant's", len(page.Data))
+	}
+}
+
+func TestItemServiceRefusesAnotherRestaurantsCategory(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+	restaurant := s.createRestaurant(t, "Burger Barn")
+	other := s.createRestaurant(t, "Crème Café")
+
+	category := &models.MenuCategory{RestaurantID: other.ID, Name: "Desserts"}
+	if err := s.menus.CreateCategory(ctx, category); err != nil {
+		t.Fatalf("CreateCategory: %v", err)
+	}
+
+	item := &models.Item{RestaurantID: restaurant.ID, CategoryID: &category.ID, Name: "Brownie", Price: 4, Status: models.ItemStatusAvailable}
+	if err := s.items.CreateItem(ctx, item); !errors.Is(err, ErrInvalidCategory) {
+		t.Errorf("err = %v, want ErrInvalidCategory", err)
+	}
+}
+
+func TestItemServiceRefusesComputedFields(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+	restaurant := s.createRestaurant(t, "Burger Barn")
+	burger := s.createItem(t, restaurant.ID, "Cheeseburger", 8.5, &models.Inventory{Stock: 5})
+
+	for _, update := range []map[string]interface{}{
+		{"restaurantId": s.createRestaurant(t, "Crème Café").ID.Hex()},
+		{"inventory.stock": 100},
+		{"inventory.soldOut": true},
+		{"name": "Burger", "$inc": map[string]interface{}{"inventory.stock": 1}},
+	} {
+		err := s.items.UpdateItem(ctx, burger.ID, update)
+		if serviceErr := AsError(err); serviceErr == nil || serviceErr.Code != "invalid_update" {
+			t.Errorf("update %v: err = %v, want invalid_update", update, err)
+		}
+	}
+	if stock := s.stockOf(t, burger.ID); stock != 5 {
+		t.Errorf("stock = %d after refused updates, want 5", stock)
+	}
+	if err := s.items.UpdateItem(ctx, burger.ID, map[string]interface{}{"status": "gone"}); !errors.Is(err, ErrInvalidItemStatus) {
+		t.Errorf("unknown status: err = %v, want ErrInvalidItemStatus", err)
+	}
+}
+
+func TestItemServiceStatusFollowsStock(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+	restaurant := s.createRestaurant(t, "Burger Barn")
+	burger := s.createItem(t, restaurant.ID, "Cheeseburger", 8.5, &models.Inventory{Stock: 1})
+	setStock := func(stock int) {
+		t.Helper()
+		if err := s.inventory.AdjustStock(ctx, restaurant.ID, []models.StockAdjustment{{ItemID: burger.ID, Stock: &stock}}); err != nil {
+			t.Fatalf("AdjustStock: %v", err)
+		}
+	}
+	setStock(0)
+
+	statusOf := func() *models.Item {
+		t.Helper()
+		item, err := s.items.GetItemByID(ctx, burger.ID)
+		if err != nil {
+			t.Fatalf("GetItemByID: %v", err)
+		}
+		return item
+	}
+
+	if err := s.items.UpdateItem(ctx, burger.ID, map[string]interface{}{"status": models.ItemStatusAvailable, "name": "Burger"}); err != nil {
+		t.Fatalf("UpdateItem: %v", err)
+	}
+	if item := statusOf(); item.Status != models.ItemStatusUnavailable || !item.Inventory.SoldOut || item.Name != "Burger" {
+		t.Errorf("made available without stock: status %s, sold out %v, name %s, want a renamed, sold out item", item.Status, item.Inventory.SoldOut, item.Name)
+	}
+
+	if err := s.items.UpdateItem(ctx, burger.ID, map[string]interface{}{"status": models.ItemStatusUnavailable}); err != nil {
+		t.Fatalf("UpdateItem: %v", err)
+	}
+	setStock(3)
+	if item := statusOf(); item.Status != models.ItemStatusUnavailable {
+		t.Errorf("status = %s after restocking an item taken off, want it to stay unavailable", item.Status)
+	}
+
+	if err := s.items.UpdateItem(ctx, burger.ID, map[string]interface{}{"status": models.ItemStatusAvailable}); err != nil {
+		t.Fatalf("UpdateItem: %v", err)
+	}
+	if item := statusOf(); item.Status != models.ItemStatusAvailable || item.Inventory.SoldOut {
+		t.Errorf("status = %s, sold out %v with stock, want available", item.Status, item.Inventory.SoldOut)
+	}
+}
+
+func TestItemServiceChecksModifierGroupUpdates(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+	restaurant := s.createRestaurant(t, "Burger Barn")
+	burger := s.createItem(t, restaurant.ID, "Cheeseburger", 8.5, nil)
+
+	size := map[string]interface{}{
+		"name": "Size", "minSelections": 1, "maxSelections": 1,
+		"options": []interface{}{map[string]interface{}{"name": "Regular"}, map[string]interface{}{"name": "Large", "priceDelta": 2.5}},
+	}
+	if err := s.items.UpdateItem(ctx, burger.ID, map[string]interface{}{"modifierGroups": []interface{}{size}}); err != nil {
+		t.Fatalf("UpdateItem: %v", err)
+	}
+
+	for _, update := range []map[string]interface{}{
+		{"modifierGroups.0.minSelections": 5},
+		{"modifierGroups.0.options.0.priceDelta": -100},
+	} {
+		err := s.items.UpdateItem(ctx, burger.ID, update)
+		if serviceErr := AsError(err); serviceErr == nil || serviceErr.Code != "invalid_update" {
+			t.Errorf("update %v: err = %v, want invalid_update", update, err)
+		}
+	}
+	size["minSelections"] = 2
+	if err := s.items.UpdateItem(ctx, burger.ID, map[string]interface{}{"modifierGroups": []interface{}{size}}); !errors.Is(err, ErrInvalidModifierGroups) {
+		t.Errorf("minSelections above maxSelections: err = %v, want ErrInvalidModifierGroups", err)
+	}
+
+	item, err := s.items.GetItemByID(ctx, burger.ID)
+	if err != nil {
+		t.Fatalf("GetItemByID: %v", err)
+	}
+	group := item.ModifierGroups[0]
+	if group.MinSelections != 1 || group.Options[0].PriceDelta != 0 || group.Options[0].ID.IsZero() {
+		t.Errorf("modifier group = %+v after refused updates, want the checked one", group)
+	}
+}
+
+func TestItemServiceReindexesRenamedItems(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+	restaurant := s.createRestaurant(t, "Burger Barn")
+	burger := s.createItem(t, restaurant.ID, "Cheeseburger", 8.5, nil)
+
+	if err := s.items.UpdateItem(ctx, burger.ID, map[string]interface{}{"name": "Halloumi Burger"}); err != nil {
+		t.Fatalf("UpdateItem: %v", err)
+	}
+	if texts := suggestionTexts(s.suggest.Suggest("halloumi", 5)); len(texts) != 1 || texts[0] != "Halloumi Burger" {
+		t.Errorf("suggestions after the rename = %v, want [Halloumi Burger]", texts)
+	}
+
+	if err := s.items.UpdateItem(ctx, primitive.NewObjectID(), map[string]interface{}{"name": "Phantom Pie"}); !errors.Is(err, ErrItemNotFound) {
+		t.Errorf("update of an unknown item: err = %v, want ErrItemNotFound", err)
+	}
+	if texts := suggestionTexts(s.suggest.Suggest("phantom", 5)); len(texts) != 0 {
+		t.Errorf("suggestions after a missed update = %v, want none", texts)
+	}
+
+	if err := s.restaurants.DeleteRestaurant(ctx, restaurant.ID); err != nil {
+		t.Fatalf("DeleteRestaurant: %v", err)
+	}
+	if texts := suggestionTexts(s.suggest.Suggest("halloumi", 5)); len(texts) != 0 {
+		t.Errorf("suggestions after deleting the restaurant = %v, want none", texts)
+	}
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/aldiandyaIrsyad/uber-eats/repos"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrInvalidCategory     = Validation("invalid_category", "category does not exist in this restaurant")
+	ErrInvalidMenuSchedule = InvalidField("schedule", "invalid_menu_schedule", "invalid menu schedule")
+	ErrInvalidMenuItems    = InvalidField("itemIds", "invalid_menu_items", "menu items must belong to this restaurant")
+	ErrMenuNotFound        = NotFound("menu_not_found", "menu not found")
+	ErrCategoryNotFound    = NotFound("category_not_found", "category not found")
+)
+
+type MenuService struct {
+	menuRepo       repos.MenuRepository
+	categoryRepo   repos.MenuCategoryRepository
+	itemRepo       repos.ItemRepository
+	restaurantRepo repos.RestaurantRepository
+}
+
+func NewMenuService(menuRepo repos.MenuRepository, categoryRepo repos.MenuCategoryRepository, itemRepo repos.ItemRepository, restaurantRepo repos.RestaurantRepository) *MenuService {
+	return &MenuService{
+		menuRepo:       menuRepo,
+		categoryRepo:   categoryRepo,
+		itemRepo:       itemRepo,
+		restaurantRepo: restaurantRepo,
+	}
+}
+
+func (s *MenuService) CreateMenu(ctx context.Context, menu *models.Menu) error {
+	if _, err := s.restaurantRepo.GetRestaurantOwnerID(ctx, menu.RestaurantID); err != nil {
+		return notFound(err, ErrRestaurantNotFound)
+	}
+	if err := s.validateMenu(ctx, menu); err != nil {
+		return err
+	}
+	return s.menuRepo.CreateMenu(ctx, menu)
+}
+
+func (s *MenuService) GetMenusByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID) ([]models.Menu, error) {
+	return s.menuRepo.GetMenusByRestaurantID(ctx, restaurantID)
+}
+
+func (s *MenuService) UpdateMenu(ctx context.Context, menu *models.Menu) error {
+	if err := s.validateMenu(ctx, menu); err != nil {
+		return err
+	}
+	return notFound(s.menuRepo.UpdateMenu(ctx, menu), ErrMenuNotFound)
+}
+
+func (s *MenuService) DeleteMenu(ctx context.Context, restaurantID, menuID primitive.ObjectID) error {
+	return notFound(s.menuRepo.DeleteMenu(ctx, restaurantID, menuID), ErrMenuNotFound)
+}
+
+// validateMenu checks the schedule and that every category and item is the restaurant's own
+func (s *MenuService) validateMenu(ctx context.Context, menu *models.Menu) error {
+	if len(menu.Schedule) == 0 {
+		return fmt.Errorf("%w: a menu needs at least one entry in schedule", ErrInvalidMenuSchedule)
+	}
+	if err := menu.NormalizeSchedule(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMenuSchedule, err)
+	}
+	if menu.CategoryIDs == nil {
+		menu.CategoryIDs = []primitive.ObjectID{}
+	}
+	if menu.ItemIDs == nil {
+		menu.ItemIDs = []primitive.ObjectID{}
+	}
+
+	for _, categoryID := range menu.CategoryIDs {
+		if err := checkCategory(ctx, s.categoryRepo, menu.RestaurantID, categoryID); err != nil {
+			return err
+		}
+	}
+
+	if len(menu.ItemIDs) > 0 {
+		count, err := s.itemRepo.CountItems(ctx, bson.M{"_id": bson.M{"$in": menu.ItemIDs}, "restaurantId": menu.RestaurantID})
+		if err != nil {
+			return err
+		}
+		if count != int64(len(menu.ItemIDs)) {
+			return ErrInvalidMenuItems
+		}
+	}
+	return nil
+}
+
+func (s *MenuService) CreateCategory(ctx context.Context, category *models.MenuCategory) error {
+	if _, err := s.restaurantRepo.GetRestaurantOwnerID(ctx, category.RestaurantID); err != nil {
+		return notFound(err, ErrRestaurantNotFound)
+	}
+	return s.categoryRepo.CreateCategory(ctx, category)
+}
+
+func (s *MenuService) GetCategoriesByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID) ([]models.MenuCategory, error) {
+	return s.categoryRepo.GetCategoriesByRestaurantID(ctx, restaurantID)
+}
+
+func (s *MenuService) UpdateCategory(ctx context.Context, category *models.MenuCategory) error {
+	return notFound(s.categoryRepo.UpdateCategory(ctx, category), ErrCategoryNotFound)
+}
+
+func (s *MenuService) DeleteCategory(ctx context.Context, restaurantID, categoryID primitive.ObjectID) error {
+	return notFound(s.categoryRepo.DeleteCategory(ctx, restaurantID, categoryID), ErrCategoryNotFound)
+}
+
+// GetRestaurantMenu returns a restaurant's full menu grouped by category
+func (s *MenuService) GetRestaurantMenu(ctx context.Context, restaurantID primitive.ObjectID) (*models.RestaurantMenu, error) {
+	if _, err := s.restaurantRepo.GetRestaurantOwnerID(ctx, restaurantID); err != nil {
+		return nil, notFound(err, ErrRestaurantNotFound)
+	}
+	return s.categoryRepo.GetRestaurantMenu(ctx, restaurantID)
+}
+
+// checkCategory verifies that a category exists and belongs to the restaurant
+func checkCategory(ctx context.Context, categoryRepo repos.MenuCategoryRepository, restaurantID, categoryID primitive.ObjectID) error {
+	category, err := categoryRepo.GetCategoryByID(ctx, categoryID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrInvalidCategory
+		}
+		return err
+	}
+	if category.RestaurantID != restaurantID {
+		return ErrInvalidCategory
+	}
+	return nil
+}
+
+// menuAvailability works out which items the menus of the given restaurants allow at t.
+// timezones holds each restaurant's timezone, as returned by RestaurantRepository.GetTimezones.
+func menuAvailability(ctx context.Context, menuRepo repos.MenuRepository, timezones map[primitive.ObjectID]string, t time.Time) (*models.MenuAvailability, error) {
+	restaurantIDs := make([]primitive.ObjectID, 0, len(timezones))
+	for restaurantID := range timezones {
+		restaurantIDs = append(restaurantIDs, restaurantID)
+	}
+
+	menus, err := menuRepo.GetMenusByRestaurantIDs(ctx, restaurantIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	location := func(restaurantID primitive.ObjectID) *time.Location {
+		loc, err := time.LoadLocation(timezones[restaurantID])
+		if err != nil {
+			return time.UTC
+		}
+		return loc
+	}
+	return models.NewMenuAvailability(menus, t, location), nil
+}
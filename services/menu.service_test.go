@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMenuServiceGroupsItemsByCategory(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+	restaurant := s.createRestaurant(t, "Burger Barn")
+
+	categories := []*models.MenuCategory{
+		{RestaurantID: restaurant.ID, Name: "Sides", Position: 1},
+		{RestaurantID: restaurant.ID, Name: "Mains", Position: 0},
+	}
+	for _, category := range categories {
+		if err := s.menus.CreateCategory(ctx, category); err != nil {
+			t.Fatalf("CreateCategory: %v", err)
+		}
+	}
+	items := []*models.Item{
+		{Name: "Fries", CategoryID: &categories[0].ID},
+		{Name: "Cheeseburger", CategoryID: &categories[1].ID, Position: 1},
+		{Name: "Veggie Burger", CategoryID: &categories[1].ID, Position: 0},
+		{Name: "Secret Sauce"},
+	}
+	for _, item := range items {
+		item.RestaurantID, item.Price, item.Status = restaurant.ID, 5, models.ItemStatusAvailable
+		if err := s.items.CreateItem(ctx, item); err != nil {
+			t.Fatalf("CreateItem: %v", err)
+		}
+	}
+
+	menu, err := s.menus.GetRestaurantMenu(ctx, restaurant.ID)
+	if err != nil {
+		t.Fatalf("GetRestaurantMenu: %v", err)
+	}
+	var got []string
+	for _, section := range menu.Categories {
+		got = append(got, section.Name+":")
+		for _, item := range section.Items {
+			got = append(got, item.Name)
+		}
+	}
+	want := []string{"Mains:", "Veggie Burger", "Cheeseburger", "Sides:", "Fries"}
+	if len(got) != len(want) {
+		t.Fatalf("menu = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("menu = %v, want %v", got, want)
+		}
+	}
+	if len(menu.Uncategorized) != 1 || menu.Uncategorized[0].Name != "Secret Sauce" {
+		t.Errorf("uncategorized = %+v, want the secret sauce", menu.Uncategorized)
+	}
+
+	if err := s.menus.DeleteCategory(ctx, restaurant.ID, categories[0].ID); err != nil {
+		t.Fatalf("DeleteCategory: %v", err)
+	}
+	if menu, err = s.menus.GetRestaurantMenu(ctx, restaurant.ID); err != nil || len(menu.Uncategorized) != 2 {
+		t.Errorf("after deleting Sides, uncategorized = %+v, %v, want the fries and the sauce", menu.Uncategorized, err)
+	}
+}
+
+func TestMenuServiceDeletedCategoryLeavesMenus(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+	restaurant := s.createRestaurant(t, "Burger Barn")
+
+	categories := []*models.MenuCategory{
+		{RestaurantID: restaurant.ID, Name: "Sides"},
+		{RestaurantID: restaurant.ID, Name: "Mains"},
+	}
+	for _, category := range categories {
+		if err := s.menus.CreateCategory(ctx, category); err != nil {
+			t.Fatalf("CreateCategory: %v", err)
+		}
+	}
+	menu := &models.Menu{
+		RestaurantID: restaurant.ID,
+		Name:         "Lunch",
+		Schedule:     []models.OperatingHours{{Day: "Monday", OpenTime: "11:00", CloseTime: "15:00"}},
+		CategoryIDs:  []primitive.ObjectID{categories[0].ID, categories[1].ID},
+	}
+	if err := s.menus.CreateMenu(ctx, menu); err != nil {
+		t.Fatalf("CreateMenu: %v", err)
+	}
+
+	if err := s.menus.DeleteCategory(ctx, restaurant.ID, categories[0].ID); err != nil {
+		t.Fatalf("DeleteCategory: %v", err)
+	}
+	menus, err := s.repos.Menus.GetMenusByRestaurantID(ctx, restaurant.ID)
+	if err != nil || len(menus) != 1 {
+		t.Fatalf("GetMenusByRestaurantID = %+v, %v, want the lunch menu", menus, err)
+	}
+	if ids := menus[0].CategoryIDs; len(ids) != 1 || ids[0] != categories[1].ID {
+		t.Errorf("menu categories = %v, want only Mains", ids)
+	}
+}
+
+func TestMenuServiceLimitsOrdersToScheduledMenus(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+	restaurant := s.createRestaurant(t, "Burger Barn")
+	breakfast := s.createItem(t, restaurant.ID, "Pancakes", 6, nil)
+	burger := s.createItem(t, restaurant.ID, "Cheeseburger", 8.5, nil)
+
+	// On only the day after tomorrow, so never now
+	day := time.Now().UTC().AddDate(0, 0, 2).Weekday().String()
+	menu := &models.Menu{
+		RestaurantID: restaurant.ID,
+		Name:         "Breakfast",
+		Schedule:     []models.OperatingHours{{Day: day, OpenTime: "00:00", CloseTime: "24:00"}},
+		ItemIDs:      []primitive.ObjectID{breakfast.ID},
+	}
+	if err := s.menus.CreateMenu(ctx, menu); err != nil {
+		t.Fatalf("CreateMenu: %v", err)
+	}
+
+	order := &models.Order{CustomerID: primitive.NewObjectID(), RestaurantID: restaurant.ID, Items: []models.OrderItem{{ItemID: breakfast.ID, Quantity: 1}}}
+	if err := s.orders.CreateOrder(ctx, order); !errors.Is(err, ErrItemUnavailable) {
+		t.Errorf("ordering off a menu that is off: err = %v, want ErrItemUnavailable", err)
+	}
+	s.placeOrder(t, restaurant.ID, burger.ID, 1)
+
+	if err := s.menus.DeleteMenu(ctx, restaurant.ID, menu.ID); err != nil {
+		t.Fatalf("DeleteMenu: %v", err)
+	}
+	s.placeOrder(t, restaurant.ID, breakfast.ID, 1)
+}
+
+func TestMenuServiceRefusesAnotherRestaurantsItems(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+	restaurant := s.createRestaurant(t, "Burger Barn")
+	brulee := s.createItem(t, s.createRestaurant(t, "Crème Café").ID, "Crème Brûlée", 6, nil)
+
+	menu := &models.Menu{
+		RestaurantID: restaurant.ID,
+		Name:         "Desserts",
+		Schedule:     []models.OperatingHours{{Day: "Friday", OpenTime: "18:00", CloseTime: "23:00"}},
+		ItemIDs:      []primitive.ObjectID{brulee.ID},
+	}
+	if err := s.menus.CreateMenu(ctx, menu); !errors.Is(err, ErrInvalidMenuItems) {
+		t.Errorf("err = %v, want ErrInvalidMenuItems", err)
+	}
+}
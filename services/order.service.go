@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/aldiandyaIrsyad/uber-eats/repos"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrEmptyOrder             = InvalidField("items", "empty_order", "order must contain at least one item")
+	ErrInvalidQuantity        = InvalidField("quantity", "invalid_quantity", "item quantity must be at least 1")
+	ErrItemNotFound           = NotFound("item_not_found", "item not found")
+	ErrItemNotInRestaurant    = InvalidField("itemId", "item_not_in_restaurant", "item does not belong to this restaurant")
+	ErrItemUnavailable        = Conflict("item_unavailable", "item is unavailable")
+	ErrInvalidOrderStatus     = InvalidField("status", "invalid_order_status", "invalid order status")
+	ErrInvalidOrderTransition = Conflict("invalid_order_transition", "order cannot move to this status")
+	ErrRestaurantNotFound     = NotFound("restaurant_not_found", "restaurant not found")
+	ErrRestaurantClosed       = Conflict("restaurant_closed", "restaurant is closed")
+	ErrOrderNotFound          = NotFound("order_not_found", "order not found")
+
+	ErrInvalidModifierSelection = InvalidField("modifiers", "invalid_modifier_selection", "invalid modifier selection")
+)
+
+type OrderService struct {
+	orderRepo      repos.OrderRepository
+	itemRepo       repos.ItemRepository
+	restaurantRepo repos.RestaurantRepository
+	menuRepo       repos.MenuRepository
+
+	inventoryService *InventoryService
+}
+
+func NewOrderService(orderRepo repos.OrderRepository, itemRepo repos.ItemRepository, restaurantRepo repos.RestaurantRepository, menuRepo repos.MenuRepository, inventoryService *InventoryService) *OrderService {
+	return &OrderService{
+		orderRepo:        orderRepo,
+		itemRepo:         itemRepo,
+		restaurantRepo:   restaurantRepo,
+		menuRepo:         menuRepo,
+		inventoryService: inventoryService,
+	}
+}
+
+// CreateOrder places a new order. Only the item IDs, modifier IDs and quantities are taken from the
+// request; names and prices are copied from the current items so the order keeps what was actually
+// charged.
+// Orders are only taken while the restaurant is open, including its hours exceptions, and for items
+// whose menus are on.
+func (s *OrderService) CreateOrder(ctx context.Context, order *models.Order) error {
+	if len(order.Items) == 0 {
+		return ErrEmptyOrder
+	}
+
+	restaurant, err := s.restaurantRepo.GetRestaurantHours(ctx, order.RestaurantID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrRestaurantNotFound
+		}
+		return err
+	}
+	now := time.Now()
+	if !restaurant.OpenAt(now) {
+		return ErrRestaurantClosed
+	}
+	availability, err := menuAvailability(ctx, s.menuRepo, map[primitive.ObjectID]string{order.RestaurantID: restaurant.Timezone}, now)
+	if err != nil {
+		return err
+	}
+
+	var subtotal float64
+	for i, line := range order.Items {
+		if line.Quantity < 1 {
+			return ErrInvalidQuantity
+		}
+
+		item, err := s.itemRepo.GetItemByID(ctx, line.ItemID)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return ErrItemNotFound
+			}
+			return err
+		}
+		if item.RestaurantID != order.RestaurantID {
+			return ErrItemNotInRestaurant
+		}
+		if item.Status != models.ItemStatusAvailable || !availability.Allows(item) {
+			return ErrItemUnavailable
+		}
+
+		modifiers, unitPrice, err := priceLine(item, line.Modifiers)
+		if err != nil {
+			return err
+		}
+
+		order.Items[i].Name = item.Name
+		order.Items[i].Price = item.Price
+		order.Items[i].Modifiers = modifiers
+		order.Items[i].UnitPrice = unitPrice
+		order.Items[i].LineTotal = roundPrice(unitPrice * float64(line.Quantity))
+		subtotal += order.Items[i].LineTotal
+	}
+
+	order.Pricing = calculatePricing(subtotal)
+	order.Status = models.OrderStatusPlaced
+	order.StatusHistory = []models.OrderStatusChange{
+		{Status: models.OrderStatusPlaced, ChangedAt: time.Now()},
+	}
+
+	return s.orderRepo.CreateOrder(ctx, order)
+}
+
+func (s *OrderService) GetOrderByID(ctx context.Context, id primitive.ObjectID) (*models.Order, error) {
+	order, err := s.orderRepo.GetOrderByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrOrderNotFound)
+	}
+	return order, nil
+}
+
+func (s *OrderService) GetOrdersByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID, opts models.QueryOptions) (*models.Page[models.Order], error) {
+	return s.orderRepo.GetOrdersByRestaurantID(ctx, restaurantID, opts)
+}
+
+// UpdateOrderStatus moves an order to status on behalf of user, refusing any move the order
+// lifecycle doesn't allow. Accepting an order takes its items from stock, and is refused if any of
+// them has run out; cancelling it after that puts them back. A courier picking an order up becomes
+// its courier.
+func (s *OrderService) UpdateOrderStatus(ctx context.Context, id primitive.ObjectID, status string, user *models.AuthUser) (*models.Order, error) {
+	if !models.IsValidOrderStatus(status) {
+		return nil, ErrInvalidOrderStatus
+	}
+
+	order, err := s.GetOrderByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !order.CanTransitionTo(status) {
+		return nil, ErrInvalidOrderTransition
+	}
+
+	accepting := status == models.OrderStatusAccepted
+	if accepting {
+		if err := s.inventoryService.ReserveStock(ctx, order.Items); err != nil {
+			return nil, err
+		}
+	}
+
+	var courierID primitive.ObjectID
+	if status == models.OrderStatusPickedUp && user.Role == models.RoleCourier {
+		courierID = user.ID
+	}
+
+	updated, err := s.orderRepo.UpdateOrderStatus(ctx, id, order.Status, status, courierID)
+	if err != nil || !updated {
+		if accepting {
+			s.inventoryService.ReleaseStock(ctx, order.Items)
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Someone else changed the status between our read and write
+		return nil, ErrInvalidOrderTransition
+	}
+	if status == models.OrderStatusCancelled && order.HasReservedStock() {
+		s.inventoryService.ReleaseStock(ctx, order.Items)
+	}
+
+	return s.orderRepo.GetOrderByID(ctx, id)
+}
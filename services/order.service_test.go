@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (s *testServices) placeOrder(t *testing.T, restaurantID, itemID primitive.ObjectID, quantity int) *models.Order {
+	t.Helper()
+	order := &models.Order{
+		CustomerID:   primitive.NewObjectID(),
+		RestaurantID: restaurantID,
+		Items:        []models.OrderItem{{ItemID: itemID, Quantity: quantity}},
+	}
+	if err := s.orders.CreateOrder(context.Background(), order); err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	return order
+}
+
+func TestOrderServiceReservesAndReleasesStock(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+	owner := &models.AuthUser{ID: primitive.NewObjectID(), Role: models.RoleRestaurantOwner}
+	restaurant := s.createRestaurant(t, "Burger Barn")
+	burger := s.createItem(t, restaurant.ID, "Cheeseburger", 8.5, &models.Inventory{Stock: 5})
+
+	order := s.placeOrder(t, restaurant.ID, burger.ID, 2)
+	if order.Status != models.OrderStatusPlaced || order.Items[0].LineTotal != 17 {
+		t.Errorf("placed order is %s with a line of %v, want placed at 17", order.Status, order.Items[0].LineTotal)
+	}
+	if stock := s.stockOf(t, burger.ID); stock != 5 {
+		t.Errorf("stock after placing = %d, want 5 until the order is accepted", stock)
+	}
+
+	if _, err := s.orders.UpdateOrderStatus(ctx, order.ID, models.OrderStatusAccepted, owner); err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	if stock := s.stockOf(t, burger.ID); stock != 3 {
+		t.Errorf("stock after accepting = %d, want 3", stock)
+	}
+
+	updated, err := s.orders.UpdateOrderStatus(ctx, order.ID, models.OrderStatusCancelled, owner)
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if updated.Status != models.OrderStatusCancelled {
+		t.Errorf("status = %s, want cancelled", updated.Status)
+	}
+	if stock := s.stockOf(t, burger.ID); stock != 5 {
+		t.Errorf("stock after cancelling = %d, want 5", stock)
+	}
+
+	if _, err := s.orders.UpdateOrderStatus(ctx, order.ID, models.OrderStatusAccepted, owner); !errors.Is(err, ErrInvalidOrderTransition) {
+		t.Errorf("accepting a cancelled order: err = %v, want ErrInvalidOrderTransition", err)
+	}
+}
+
+func TestOrderServiceRefusesAcceptingWithoutStock(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+	owner := &models.AuthUser{ID: primitive.NewObjectID(), Role: models.RoleRestaurantOwner}
+	restaurant := s.createRestaurant(t, "Burger Barn")
+	burger := s.createItem(t, restaurant.ID, "Cheeseburger", 8.5, &models.Inventory{Stock: 1})
+
+	order := s.placeOrder(t, restaurant.ID, burger.ID, 2)
+	if _, err := s.orders.UpdateOrderStatus(ctx, order.ID, models.OrderStatusAccepted, owner); !errors.Is(err, ErrOutOfStock) {
+		t.Errorf("err = %v, want ErrOutOfStock", err)
+	}
+	if stock := s.stockOf(t, burger.ID); stock != 1 {
+		t.Errorf("stock = %d, want 1", stock)
+	}
+	if _, err := s.orders.UpdateOrderStatus(ctx, order.ID, "eaten", owner); !errors.Is(err, ErrInvalidOrderStatus) {
+		t.Errorf("unknown status: err = %v, want ErrInvalidOrderStatus", err)
+	}
+}
+
+func TestOrderServiceCourierClaimsOrder(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+	owner := &models.AuthUser{ID: primitive.NewObjectID(), Role: models.RoleRestaurantOwner}
+	courier := &models.AuthUser{ID: primitive.NewObjectID(), Role: models.RoleCourier}
+	restaurant := s.createRestaurant(t, "Burger Barn")
+	burger := s.createItem(t, restaurant.ID, "Cheeseburger", 8.5, nil)
+
+	order := s.placeOrder(t, restaurant.ID, burger.ID, 1)
+	for _, status := range []string{models.OrderStatusAccepted, models.OrderStatusPreparing, models.OrderStatusReady} {
+		if _, err := s.orders.UpdateOrderStatus(ctx, order.ID, status, owner); err != nil {
+			t.Fatalf("%s: %v", status, err)
+		}
+	}
+	updated, err := s.orders.UpdateOrderStatus(ctx, order.ID, models.OrderStatusPickedUp, courier)
+	if err != nil {
+		t.Fatalf("pick up: %v", err)
+	}
+	if updated.CourierID == nil || *updated.CourierID != courier.ID {
+		t.Errorf("courier = %v, want %s", updated.CourierID, courier.ID.Hex())
+	}
+	if !updated.VisibleToCourier(courier.ID) || updated.VisibleToCourier(primitive.NewObjectID()) {
+		t.Error("a picked up order should only be visible to its courier")
+	}
+}
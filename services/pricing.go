@@ -0,0 +1,96 @@
+package services
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	taxRate        = 0.08
+	serviceFeeRate = 0.05
+	deliveryFee    = 2.99
+)
+
+// calculatePricing derives tax and fees from a subtotal. Carts and orders both go through here
+// so the price shown in the cart is the price charged at checkout.
+func calculatePricing(subtotal float64) models.PriceBreakdown {
+	if subtotal <= 0 {
+		return models.PriceBreakdown{}
+	}
+
+	pricing := models.PriceBreakdown{
+		Subtotal:    roundPrice(subtotal),
+		Tax:         roundPrice(subtotal * taxRate),
+		ServiceFee:  roundPrice(subtotal * serviceFeeRate),
+		DeliveryFee: deliveryFee,
+	}
+	pricing.Total = roundPrice(pricing.Subtotal + pricing.Tax + pricing.ServiceFee + pricing.DeliveryFee)
+	return pricing
+}
+
+// roundPrice rounds a monetary amount to cents
+func roundPrice(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// priceLine validates the modifiers picked for an item against its modifier groups and returns
+// them in menu order, with names and price deltas copied from the item, along with the unit
+// price including them.
+func priceLine(item *models.Item, selected []models.SelectedModifier) ([]models.SelectedModifier, float64, error) {
+	// Option IDs are unique across the item, so they map back to the group they were picked from
+	picked := make(map[primitive.ObjectID]primitive.ObjectID, len(selected))
+	for _, selection := range selected {
+		if _, ok := picked[selection.OptionID]; ok {
+			return nil, 0, fmt.Errorf("%w: an option was picked more than once", ErrInvalidModifierSelection)
+		}
+		picked[selection.OptionID] = selection.GroupID
+	}
+
+	var modifiers []models.SelectedModifier
+	unitPrice := item.Price
+	matched := 0
+	for _, group := range item.ModifierGroups {
+		count := 0
+		for _, option := range group.Options {
+			groupID, ok := picked[option.ID]
+			if !ok {
+				continue
+			}
+			if groupID != group.ID {
+				return nil, 0, fmt.Errorf("%w: option %s is not in group %s", ErrInvalidModifierSelection, option.Name, group.Name)
+			}
+			if option.Status != models.ItemStatusAvailable {
+				return nil, 0, fmt.Errorf("%w: %s is unavailable", ErrInvalidModifierSelection, option.Name)
+			}
+
+			count++
+			unitPrice += option.PriceDelta
+			modifiers = append(modifiers, models.SelectedModifier{
+				GroupID:    group.ID,
+				OptionID:   option.ID,
+				GroupName:  group.Name,
+				OptionName: option.Name,
+				PriceDelta: option.PriceDelta,
+			})
+		}
+
+		if count < group.MinSelections {
+			return nil, 0, fmt.Errorf("%w: choose at least %d for %s", ErrInvalidModifierSelection, group.MinSelections, group.Name)
+		}
+		if count > group.MaxSelections {
+			return nil, 0, fmt.Errorf("%w: choose at most %d for %s", ErrInvalidModifierSelection, group.MaxSelections, group.Name)
+		}
+		matched += count
+	}
+
+	if matched != len(selected) {
+		return nil, 0, fmt.Errorf("%w: unknown option for %s", ErrInvalidModifierSelection, item.Name)
+	}
+	if unitPrice < 0 {
+		return nil, 0, fmt.Errorf("%w: modifiers bring the price below zero", ErrInvalidModifierSelection)
+	}
+	return modifiers, roundPrice(unitPrice), nil
+}
@@ -2,75 +2,398 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
 
 	"github.com/aldiandyaIrsyad/uber-eats/models"
 	"github.com/aldiandyaIrsyad/uber-eats/repos"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+var (
+	ErrInvalidOperatingHours  = InvalidField("operatingHours", "invalid_operating_hours", "invalid operating hours")
+	ErrInvalidHoursException  = Validation("invalid_hours_exception", "invalid hours exception")
+	ErrSortNeedsLocation      = InvalidField("sort", "sort_needs_location", "sorting by distance needs lat and lng; use /api/restaurants/nearby")
+	ErrHoursExceptionNotFound = NotFound("hours_exception_not_found", "hours exception not found")
+	ErrEmptyRestaurantUpdate  = Validation("empty_update", "the update has no fields that can be changed")
+)
+
+// restaurantUpdatableFields are the fields an owner may change. Ownership, ratings, the computed
+// open intervals and the hours exceptions, which have their own routes, are left out.
+var restaurantUpdatableFields = []string{"name", "description", "address", "imageUrl", "location", "timezone", "operatingHours"}
+
 type RestaurantService struct {
-	restaurantRepo *repos.RestaurantRepository
-	reviewRepo     *repos.ReviewRepository
+	restaurantRepo repos.RestaurantRepository
+	reviewRepo     repos.ReviewRepository
+	menuRepo       repos.MenuRepository
+
+	suggestService *SuggestService
 }
 
-func NewRestaurantService(restaurantRepo *repos.RestaurantRepository, reviewRepo *repos.ReviewRepository) *RestaurantService {
+func NewRestaurantService(restaurantRepo repos.RestaurantRepository, reviewRepo repos.ReviewRepository, menuRepo repos.MenuRepository, suggestService *SuggestService) *RestaurantService {
 	return &RestaurantService{
 		restaurantRepo: restaurantRepo,
 		reviewRepo:     reviewRepo,
+		menuRepo:       menuRepo,
+		suggestService: suggestService,
 	}
 }
 
+// CreateRestaurant creates a restaurant from the fields its owner sets. The fields the server
+// computes are reset, and hours exceptions are added through their own routes, where they are
+// checked.
 func (s *RestaurantService) CreateRestaurant(ctx context.Context, restaurant *models.Restaurant) error {
-	return s.restaurantRepo.CreateRestaurant(ctx, restaurant)
+	restaurant.ID = primitive.NilObjectID
+	restaurant.HoursExceptions = nil
+	restaurant.OpenIntervals = nil
+	restaurant.IsOpen = nil
+	restaurant.NextOpenAt = nil
+	restaurant.Items = nil
+	restaurant.Distance = nil
+	restaurant.CreatedAt = time.Now()
+	restaurant.UpdatedAt = restaurant.CreatedAt
+	restaurant.Rating = 0
+	restaurant.RatingCount = 0
+	restaurant.RatingTotal = 0
+	restaurant.RatingHistogram = models.EmptyRatingHistogram()
+	if err := restaurant.NormalizeHours(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidOperatingHours, err)
+	}
+
+	if err := s.restaurantRepo.CreateRestaurant(ctx, restaurant); err != nil {
+		return err
+	}
+	s.suggestService.IndexRestaurant(restaurant)
+	return nil
 }
 
-func (s *RestaurantService) GetRestaurantByID(ctx context.Context, id primitive.ObjectID) (*models.Restaurant, error) {
-	restaurant, err := s.restaurantRepo.GetRestaurantByID(ctx, id)
+// GetRestaurantByID returns a restaurant with a preview of its items. With at, the open status is
+// for that moment and only items that can be ordered then are included.
+func (s *RestaurantService) GetRestaurantByID(ctx context.Context, id primitive.ObjectID, at *time.Time) (*models.Restaurant, error) {
+	now := time.Now()
+	var itemFilter bson.M
+	if at != nil {
+		now = *at
+		filter, err := s.orderableItemFilter(ctx, id, now)
+		if err != nil {
+			return nil, err
+		}
+		itemFilter = filter
+	}
+
+	restaurant, err := s.restaurantRepo.GetRestaurantByID(ctx, id, itemFilter)
 	if err != nil {
+		return nil, notFound(err, ErrRestaurantNotFound)
+	}
+
+	restaurants := []models.Restaurant{*restaurant}
+	if err := s.fillMissingRatings(ctx, restaurants); err != nil {
 		return nil, err
 	}
+	restaurants[0].SetOpenStatus(now)
+	return &restaurants[0], nil
+}
 
-	averageRating, err := s.reviewRepo.GetAverageRatingByRestaurantID(ctx, id)
+// orderableItemFilter matches the restaurant's items that can be ordered at t, none if it is closed
+func (s *RestaurantService) orderableItemFilter(ctx context.Context, id primitive.ObjectID, t time.Time) (bson.M, error) {
+	restaurant, err := s.restaurantRepo.GetRestaurantHours(ctx, id)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, ErrRestaurantNotFound)
+	}
+	if !restaurant.OpenAt(t) {
+		return bson.M{"_id": bson.M{"$in": bson.A{}}}, nil
 	}
-	restaurant.AverageRating = averageRating
 
-	return restaurant, nil
+	availability, err := menuAvailability(ctx, s.menuRepo, map[primitive.ObjectID]string{id: restaurant.Timezone}, t)
+	if err != nil {
+		return nil, err
+	}
+	return bson.M{"$and": bson.A{
+		bson.M{"status": models.ItemStatusAvailable},
+		repos.MenuAvailabilityFilter(availability),
+	}}, nil
 }
 
 func (s *RestaurantService) UpdateRestaurant(ctx context.Context, id primitive.ObjectID, update map[string]interface{}) error {
+	if err := checkUpdateFields(update, restaurantUpdatableFields); err != nil {
+		return err
+	}
+	if len(update) == 0 {
+		return ErrEmptyRestaurantUpdate
+	}
+	if err := s.normalizeHoursUpdate(ctx, id, update); err != nil {
+		return err
+	}
+
 	updateBson := map[string]interface{}{
 		"$set": update,
 	}
-	return s.restaurantRepo.UpdateRestaurant(ctx, id, updateBson)
+	if err := s.restaurantRepo.UpdateRestaurant(ctx, id, updateBson); err != nil {
+		return notFound(err, ErrRestaurantNotFound)
+	}
+	if name, ok := update["name"].(string); ok {
+		s.suggestService.IndexRestaurant(&models.Restaurant{ID: id, Name: name})
+	}
+	return nil
 }
 
 func (s *RestaurantService) DeleteRestaurant(ctx context.Context, id primitive.ObjectID) error {
-	return s.restaurantRepo.DeleteRestaurant(ctx, id)
+	if err := s.restaurantRepo.DeleteRestaurant(ctx, id); err != nil {
+		return notFound(err, ErrRestaurantNotFound)
+	}
+	s.suggestService.RemoveRestaurant(id)
+	return nil
 }
 
 func (s *RestaurantService) GetAverageRating(ctx context.Context, restaurantID primitive.ObjectID) (float64, error) {
 	return s.reviewRepo.GetAverageRatingByRestaurantID(ctx, restaurantID)
 }
 
-func (s *RestaurantService) GetRestaurants(ctx context.Context, filter map[string]interface{}, pagination *models.Pagination) ([]models.Restaurant, error) {
+// GetRestaurants lists restaurants matching the filter of opts. With openNow, only restaurants whose
+// hours cover the current time are returned.
+func (s *RestaurantService) GetRestaurants(ctx context.Context, opts models.QueryOptions, openNow bool) (*models.Page[models.Restaurant], error) {
+	if opts.Sort.Has("distance") {
+		return nil, ErrSortNeedsLocation
+	}
+
+	now := time.Now()
+	filter, err := s.listFilter(ctx, opts.Filter, openNow, now)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.restaurantRepo.GetAllRestaurants(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.completeListing(ctx, page.Data, now); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// GetNearbyRestaurants lists restaurants around a point in the order of opts.Sort
+func (s *RestaurantService) GetNearbyRestaurants(ctx context.Context, query models.NearbyQuery, opts models.QueryOptions, openNow bool) (*models.Page[models.Restaurant], error) {
+	now := time.Now()
+	filter, err := s.listFilter(ctx, opts.Filter, openNow, now)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.restaurantRepo.FindNearby(ctx, query, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.completeListing(ctx, page.Data, now); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// GetHoursExceptions lists a restaurant's date-specific overrides of its weekly hours
+func (s *RestaurantService) GetHoursExceptions(ctx context.Context, restaurantID primitive.ObjectID) ([]models.HoursException, error) {
+	restaurant, err := s.restaurantRepo.GetRestaurantHours(ctx, restaurantID)
+	if err != nil {
+		return nil, notFound(err, ErrRestaurantNotFound)
+	}
+	if restaurant.HoursExceptions == nil {
+		return []models.HoursException{}, nil
+	}
+	return restaurant.HoursExceptions, nil
+}
+
+func (s *RestaurantService) AddHoursException(ctx context.Context, restaurantID primitive.ObjectID, exception *models.HoursException) error {
+	restaurant, err := s.restaurantRepo.GetRestaurantHours(ctx, restaurantID)
+	if err != nil {
+		return notFound(err, ErrRestaurantNotFound)
+	}
+	if err := restaurant.NormalizeException(exception, time.Now()); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidHoursException, err)
+	}
+
+	return s.restaurantRepo.AddHoursException(ctx, restaurantID, exception)
+}
+
+func (s *RestaurantService) UpdateHoursException(ctx context.Context, restaurantID primitive.ObjectID, exception *models.HoursException) error {
+	restaurant, err := s.restaurantRepo.GetRestaurantHours(ctx, restaurantID)
+	if err != nil {
+		return notFound(err, ErrRestaurantNotFound)
+	}
+
+	var existing *models.HoursException
+	for i := range restaurant.HoursExceptions {
+		if restaurant.HoursExceptions[i].ID == exception.ID {
+			existing = &restaurant.HoursExceptions[i]
+		}
+	}
+	if existing == nil {
+		return ErrHoursExceptionNotFound
+	}
+
+	if err := restaurant.NormalizeException(exception, time.Now()); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidHoursException, err)
+	}
+	exception.CreatedAt = existing.CreatedAt
+
+	return notFound(s.restaurantRepo.UpdateHoursException(ctx, restaurantID, exception), ErrHoursExceptionNotFound)
+}
+
+func (s *RestaurantService) DeleteHoursException(ctx context.Context, restaurantID, exceptionID primitive.ObjectID) error {
+	return notFound(s.restaurantRepo.DeleteHoursException(ctx, restaurantID, exceptionID), ErrHoursExceptionNotFound)
+}
+
+// RecomputeRatings rebuilds every restaurant's stored rating aggregates from the reviews collection.
+// Use it after importing reviews, or to repair aggregates if a review write failed half way.
+func (s *RestaurantService) RecomputeRatings(ctx context.Context) (int, error) {
+	stats, err := s.reviewRepo.GetRatingStats(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.restaurantRepo.ReplaceRatingStats(ctx, stats); err != nil {
+		return 0, err
+	}
+	return len(stats), nil
+}
+
+// BackfillOpenIntervals computes and stores the open intervals of restaurants saved before they
+// were computed. Open-now listings only match the stored intervals, so until then such restaurants
+// are left out although they show as open on their own. Restaurants with invalid hours are logged
+// and skipped. It returns how many were updated.
+func (s *RestaurantService) BackfillOpenIntervals(ctx context.Context) (int, error) {
+	ids, err := s.restaurantRepo.GetRestaurantIDs(ctx, bson.M{"openIntervals": nil})
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, id := range ids {
+		restaurant, err := s.restaurantRepo.GetRestaurantHours(ctx, id)
+		if err != nil {
+			return count, err
+		}
+		if err := restaurant.NormalizeHours(); err != nil {
+			log.Printf("Error computing the open intervals of restaurant %s: %v", id.Hex(), err)
+			continue
+		}
+		update := bson.M{"$set": bson.M{
+			"timezone":       restaurant.Timezone,
+			"operatingHours": restaurant.OperatingHours,
+			"openIntervals":  restaurant.OpenIntervals,
+		}}
+		if err := s.restaurantRepo.UpdateRestaurant(ctx, id, update); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// listFilter combines the caller's filter with the open-now filter when requested
+func (s *RestaurantService) listFilter(ctx context.Context, filter map[string]interface{}, openNow bool, now time.Time) (map[string]interface{}, error) {
 	if filter == nil {
 		filter = make(map[string]interface{})
 	}
+	if !openNow {
+		return filter, nil
+	}
 
-	restaurants, err := s.restaurantRepo.GetAllRestaurants(ctx, filter, pagination)
+	openFilter, err := s.restaurantRepo.OpenAtFilter(ctx, now)
 	if err != nil {
 		return nil, err
 	}
+	return map[string]interface{}{"$and": []interface{}{filter, openFilter}}, nil
+}
 
+// completeListing fills in the computed fields of listed restaurants
+func (s *RestaurantService) completeListing(ctx context.Context, restaurants []models.Restaurant, now time.Time) error {
+	if err := s.fillMissingRatings(ctx, restaurants); err != nil {
+		return err
+	}
 	for i := range restaurants {
-		averageRating, err := s.reviewRepo.GetAverageRatingByRestaurantID(ctx, restaurants[i].ID)
+		restaurants[i].SetOpenStatus(now)
+	}
+	return nil
+}
+
+// normalizeHoursUpdate validates a partial update of the timezone or operating hours against the
+// stored values and adds the recomputed open intervals to it
+func (s *RestaurantService) normalizeHoursUpdate(ctx context.Context, id primitive.ObjectID, update map[string]interface{}) error {
+	hoursValue, hasHours := update["operatingHours"]
+	timezoneValue, hasTimezone := update["timezone"]
+	if !hasHours && !hasTimezone {
+		return nil
+	}
+
+	restaurant, err := s.restaurantRepo.GetRestaurantHours(ctx, id)
+	if err != nil {
+		return notFound(err, ErrRestaurantNotFound)
+	}
+
+	if hasHours {
+		raw, err := json.Marshal(hoursValue)
 		if err != nil {
-			return nil, err
+			return fmt.Errorf("%w: %v", ErrInvalidOperatingHours, err)
+		}
+		restaurant.OperatingHours = nil
+		if err := json.Unmarshal(raw, &restaurant.OperatingHours); err != nil {
+			return fmt.Errorf("%w: operatingHours must be a list of {day, openTime, closeTime}", ErrInvalidOperatingHours)
+		}
+	}
+	if hasTimezone {
+		timezone, ok := timezoneValue.(string)
+		if !ok {
+			return fmt.Errorf("%w: timezone must be a string", ErrInvalidOperatingHours)
+		}
+		restaurant.Timezone = timezone
+	}
+
+	if err := restaurant.NormalizeHours(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidOperatingHours, err)
+	}
+	update["timezone"] = restaurant.Timezone
+	update["operatingHours"] = restaurant.OperatingHours
+	update["openIntervals"] = restaurant.OpenIntervals
+	return nil
+}
+
+// fillMissingRatings computes ratings for restaurants stored before rating aggregates existed, all
+// in a single aggregation instead of one per restaurant. It doesn't write them back; run
+// RecomputeRatings to backfill the stored aggregates.
+func (s *RestaurantService) fillMissingRatings(ctx context.Context, restaurants []models.Restaurant) error {
+	var missing []primitive.ObjectID
+	for _, restaurant := range restaurants {
+		if restaurant.RatingHistogram == nil {
+			missing = append(missing, restaurant.ID)
 		}
-		restaurants[i].AverageRating = averageRating
+	}
+	if len(missing) == 0 {
+		return nil
 	}
 
-	return restaurants, nil
+	stats, err := s.reviewRepo.GetRatingStatsByRestaurantIDs(ctx, missing)
+	if err != nil {
+		return err
+	}
+
+	for i := range restaurants {
+		if restaurants[i].RatingHistogram != nil {
+			continue
+		}
+		stat, ok := stats[restaurants[i].ID]
+		if !ok {
+			restaurants[i].RatingHistogram = models.EmptyRatingHistogram()
+			continue
+		}
+		restaurants[i].Rating = stat.Average
+		restaurants[i].RatingCount = stat.Count
+		restaurants[i].RatingTotal = stat.Total
+		restaurants[i].RatingHistogram = stat.Histogram
+	}
+	return nil
 }
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRestaurantServiceLifecycle(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+	restaurant := s.createRestaurant(t, "Burger Barn")
+
+	got, err := s.restaurants.GetRestaurantByID(ctx, restaurant.ID, nil)
+	if err != nil {
+		t.Fatalf("GetRestaurantByID: %v", err)
+	}
+	if got.Name != "Burger Barn" || got.IsOpen == nil || !*got.IsOpen {
+		t.Errorf("got %q, open %v, want Burger Barn open", got.Name, got.IsOpen)
+	}
+
+	if err := s.restaurants.UpdateRestaurant(ctx, restaurant.ID, map[string]interface{}{"name": "Burger Hut"}); err != nil {
+		t.Fatalf("UpdateRestaurant: %v", err)
+	}
+	page, err := s.restaurants.GetRestaurants(ctx, models.QueryOptions{Pagination: &models.PaginationOptions{Page: 1, PageSize: 10}}, false)
+	if err != nil {
+		t.Fatalf("GetRestaurants: %v", err)
+	}
+	if len(page.Data) != 1 || page.Data[0].Name != "Burger Hut" {
+		t.Errorf("listed %+v, want only Burger Hut", page.Data)
+	}
+	if texts := suggestionTexts(s.suggest.Suggest("burger h", 5)); len(texts) != 1 || texts[0] != "Burger Hut" {
+		t.Errorf("suggestions after the rename = %v, want [Burger Hut]", texts)
+	}
+
+	if err := s.restaurants.UpdateRestaurant(ctx, restaurant.ID, map[string]interface{}{}); !errors.Is(err, ErrEmptyRestaurantUpdate) {
+		t.Errorf("empty update: err = %v, want ErrEmptyRestaurantUpdate", err)
+	}
+
+	if err := s.restaurants.DeleteRestaurant(ctx, restaurant.ID); err != nil {
+		t.Fatalf("DeleteRestaurant: %v", err)
+	}
+	if _, err := s.restaurants.GetRestaurantByID(ctx, restaurant.ID, nil); !errors.Is(err, ErrRestaurantNotFound) {
+		t.Errorf("get after delete: err = %v, want ErrRestaurantNotFound", err)
+	}
+	if err := s.restaurants.UpdateRestaurant(ctx, restaurant.ID, map[string]interface{}{"name": "Gone"}); !errors.Is(err, ErrRestaurantNotFound) {
+		t.Errorf("update after delete: err = %v, want ErrRestaurantNotFound", err)
+	}
+	if err := s.restaurants.DeleteRestaurant(ctx, restaurant.ID); !errors.Is(err, ErrRestaurantNotFound) {
+		t.Errorf("second delete: err = %v, want ErrRestaurantNotFound", err)
+	}
+}
+
+func TestRestaurantServiceRefusesComputedFields(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+	restaurant := s.createRestaurant(t, "Burger Barn")
+
+	for _, update := range []map[string]interface{}{
+		{"ownerId": primitive.NewObjectID()},
+		{"rating": 5},
+		{"ratingHistogram.5": 1000},
+		{"openIntervals.0.start": 0},
+		{"hoursExceptions": []interface{}{}},
+		{"name": "Burger Hut", "$inc": map[string]interface{}{"ratingCount": 1}},
+	} {
+		err := s.restaurants.UpdateRestaurant(ctx, restaurant.ID, update)
+		if serviceErr := AsError(err); serviceErr == nil || serviceErr.Code != "invalid_update" {
+			t.Errorf("update %v: err = %v, want invalid_update", update, err)
+		}
+	}
+
+	got, err := s.restaurants.GetRestaurantByID(ctx, restaurant.ID, nil)
+	if err != nil {
+		t.Fatalf("GetRestaurantByID: %v", err)
+	}
+	if got.Name != "Burger Barn" || got.RatingCount != 0 || got.RatingHistogram["5"] != 0 {
+		t.Errorf("restaurant changed by refused updates: %+v", got)
+	}
+}
+
+func TestRestaurantServiceClosure(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+	restaurant := s.createRestaurant(t, "Burger Barn")
+
+	if err := s.restaurants.AddHoursException(ctx, restaurant.ID, &models.HoursException{Type: models.HoursExceptionPause, DurationMinutes: 30}); err != nil {
+		t.Fatalf("AddHoursException: %v", err)
+	}
+	exceptions, err := s.restaurants.GetHoursExceptions(ctx, restaurant.ID)
+	if err != nil || len(exceptions) != 1 {
+		t.Fatalf("GetHoursExceptions = %v, %v, want the pause", exceptions, err)
+	}
+
+	got, err := s.restaurants.GetRestaurantByID(ctx, restaurant.ID, nil)
+	if err != nil {
+		t.Fatalf("GetRestaurantByID: %v", err)
+	}
+	if got.IsOpen == nil || *got.IsOpen {
+		t.Errorf("open = %v while paused, want closed", got.IsOpen)
+	}
+	page, err := s.restaurants.GetRestaurants(ctx, models.QueryOptions{Pagination: &models.PaginationOptions{Page: 1, PageSize: 10}}, true)
+	if err != nil {
+		t.Fatalf("GetRestaurants: %v", err)
+	}
+	if len(page.Data) != 0 {
+		t.Errorf("openNow listed %d restaurants while paused, want none", len(page.Data))
+	}
+
+	if err := s.restaurants.DeleteHoursException(ctx, restaurant.ID, exceptions[0].ID); err != nil {
+		t.Fatalf("DeleteHoursException: %v", err)
+	}
+	page, err = s.restaurants.GetRestaurants(ctx, models.QueryOptions{Pagination: &models.PaginationOptions{Page: 1, PageSize: 10}}, true)
+	if err != nil {
+		t.Fatalf("GetRestaurants: %v", err)
+	}
+	if len(page.Data) != 1 {
+		t.Errorf("openNow listed %d restaurants once reopened, want 1", len(page.Data))
+	}
+}
+
+func TestRestaurantServiceBackfillsOpenIntervals(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+
+	// Stored before open intervals were computed
+	legacy := &models.Restaurant{OwnerID: primitive.NewObjectID(), Name: "Old Diner", Timezone: "UTC"}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		legacy.OperatingHours = append(legacy.OperatingHours, models.OperatingHours{Day: day.String(), OpenTime: "00:00", CloseTime: "24:00"})
+	}
+	if err := s.repos.Restaurants.CreateRestaurant(ctx, legacy); err != nil {
+		t.Fatalf("CreateRestaurant: %v", err)
+	}
+	listOpen := func() int {
+		t.Helper()
+		page, err := s.restaurants.GetRestaurants(ctx, models.QueryOptions{Pagination: &models.PaginationOptions{Page: 1, PageSize: 10}}, true)
+		if err != nil {
+			t.Fatalf("GetRestaurants: %v", err)
+		}
+		return len(page.Data)
+	}
+	if n := listOpen(); n != 0 {
+		t.Fatalf("openNow listed %d restaurants before the backfill, want the legacy one missing", n)
+	}
+
+	count, err := s.restaurants.BackfillOpenIntervals(ctx)
+	if err != nil || count != 1 {
+		t.Fatalf("BackfillOpenIntervals = %d, %v, want 1", count, err)
+	}
+	if n := listOpen(); n != 1 {
+		t.Errorf("openNow listed %d restaurants after the backfill, want 1", n)
+	}
+	if count, err := s.restaurants.BackfillOpenIntervals(ctx); err != nil || count != 0 {
+		t.Errorf("second BackfillOpenIntervals = %d, %v, want nothing left to do", count, err)
+	}
+}
+
+func TestRestaurantServiceCreateResetsComputedFields(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+
+	open := true
+	restaurant := &models.Restaurant{
+		ID:              primitive.NewObjectID(),
+		OwnerID:         primitive.NewObjectID(),
+		Name:            "Burger Barn",
+		Address:         "1 Main Street",
+		OperatingHours:  []models.OperatingHours{{Day: "Monday", OpenTime: "09:00", CloseTime: "17:00"}},
+		OpenIntervals:   []models.OpenInterval{{Start: 0, End: 7 * 24 * 60}},
+		HoursExceptions: []models.HoursException{{Type: models.HoursExceptionPause, DurationMinutes: 60}},
+		IsOpen:          &open,
+		Rating:          5,
+		RatingCount:     1000,
+		Items:           []models.Item{{Name: "Cheeseburger"}},
+	}
+	id := restaurant.ID
+	if err := s.restaurants.CreateRestaurant(ctx, restaurant); err != nil {
+		t.Fatalf("CreateRestaurant: %v", err)
+	}
+	if restaurant.ID == id {
+		t.Error("kept the ID sent by the client")
+	}
+
+	got, err := s.repos.Restaurants.GetRestaurantHours(ctx, restaurant.ID)
+	if err != nil {
+		t.Fatalf("GetRestaurantHours: %v", err)
+	}
+	if len(got.HoursExceptions) != 0 {
+		t.Errorf("stored hours exceptions %+v sent with the restaurant, want none", got.HoursExceptions)
+	}
+	if len(got.OpenIntervals) != 1 || got.OpenIntervals[0] != (models.OpenInterval{Start: 1*24*60 + 9*60, End: 1*24*60 + 17*60}) {
+		t.Errorf("open intervals = %+v, want Monday 09:00-17:00", got.OpenIntervals)
+	}
+	if restaurant.Rating != 0 || restaurant.RatingCount != 0 || restaurant.Items != nil || restaurant.IsOpen != nil {
+		t.Errorf("created restaurant kept computed fields: %+v", restaurant)
+	}
+}
@@ -2,29 +2,83 @@ package services
 
 import (
 	"context"
+	"errors"
 
 	"github.com/aldiandyaIrsyad/uber-eats/models"
 	"github.com/aldiandyaIrsyad/uber-eats/repos"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrReviewOrderNotFound     = NotFound("order_not_found", "order not found")
+	ErrReviewOrderMismatch     = InvalidField("orderId", "review_order_mismatch", "order does not belong to this restaurant")
+	ErrReviewOrderNotDelivered = Conflict("review_order_not_delivered", "only delivered orders can be reviewed")
+	ErrReviewOrderNotOwned     = Forbidden("review_order_not_owned", "only the customer who placed the order can review it")
+	ErrReviewNotFound          = NotFound("review_not_found", "review not found")
+	ErrReviewExists            = Conflict("review_exists", "this order has already been reviewed")
 )
 
 type ReviewService struct {
-	reviewRepo *repos.ReviewRepository
+	reviewRepo repos.ReviewRepository
+	orderRepo  repos.OrderRepository
 }
 
-func NewReviewService(reviewRepo *repos.ReviewRepository) *ReviewService {
+func NewReviewService(reviewRepo repos.ReviewRepository, orderRepo repos.OrderRepository) *ReviewService {
 	return &ReviewService{
 		reviewRepo: reviewRepo,
+		orderRepo:  orderRepo,
 	}
 }
 
+// CreateReview stores a review by review.UserID for a delivered order they placed at the reviewed restaurant
 func (s *ReviewService) CreateReview(ctx context.Context, review *models.Review) error {
-	return s.reviewRepo.CreateReview(ctx, review)
+	order, err := s.orderRepo.GetOrderByID(ctx, review.OrderID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrReviewOrderNotFound
+		}
+		return err
+	}
+	if order.CustomerID != review.UserID {
+		return ErrReviewOrderNotOwned
+	}
+	if order.RestaurantID != review.RestaurantID {
+		return ErrReviewOrderMismatch
+	}
+	if order.Status != models.OrderStatusDelivered {
+		return ErrReviewOrderNotDelivered
+	}
+
+	if err := s.reviewRepo.CreateReview(ctx, review); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrReviewExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *ReviewService) GetReviewByID(ctx context.Context, id primitive.ObjectID) (*models.Review, error) {
+	return s.reviewRepo.GetReviewByID(ctx, id)
+}
+
+// UpdateReview edits the rating and comment of a review; the restaurant's rating follows the change
+func (s *ReviewService) UpdateReview(ctx context.Context, id primitive.ObjectID, rating int, comment string) (*models.Review, error) {
+	review, err := s.reviewRepo.UpdateReview(ctx, id, rating, comment)
+	if err != nil {
+		return nil, notFound(err, ErrReviewNotFound)
+	}
+	return review, nil
+}
+
+func (s *ReviewService) DeleteReview(ctx context.Context, id primitive.ObjectID) error {
+	return notFound(s.reviewRepo.DeleteReview(ctx, id), ErrReviewNotFound)
 }
 
-func (s *ReviewService) GetReviewsByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID) ([]models.Review, error) {
-	return s.reviewRepo.GetReviewsByRestaurantID(ctx, restaurantID)
+func (s *ReviewService) GetReviewsByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID, opts models.QueryOptions) (*models.Page[models.Review], error) {
+	return s.reviewRepo.GetReviewsByRestaurantID(ctx, restaurantID, opts)
 }
 
 func (s *ReviewService) GetAverageRatingByRestaurantID(ctx context.Context, restaurantID primitive.ObjectID) (float64, error) {
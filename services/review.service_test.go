@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// deliveredOrder stores an order of the customer's that has been delivered
+func (s *testServices) deliveredOrder(t *testing.T, customerID, restaurantID primitive.ObjectID) *models.Order {
+	t.Helper()
+	order := &models.Order{CustomerID: customerID, RestaurantID: restaurantID, Status: models.OrderStatusDelivered}
+	if err := s.repos.Orders.CreateOrder(context.Background(), order); err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	return order
+}
+
+func (s *testServices) ratingOf(t *testing.T, restaurantID primitive.ObjectID) (float64, int64) {
+	t.Helper()
+	restaurant, err := s.restaurants.GetRestaurantByID(context.Background(), restaurantID, nil)
+	if err != nil {
+		t.Fatalf("GetRestaurantByID: %v", err)
+	}
+	return restaurant.Rating, restaurant.RatingCount
+}
+
+func TestReviewServiceKeepsRatingInStep(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+	restaurant := s.createRestaurant(t, "Burger Barn")
+
+	var reviews []*models.Review
+	for _, rating := range []int{5, 2} {
+		customerID := primitive.NewObjectID()
+		order := s.deliveredOrder(t, customerID, restaurant.ID)
+		review := &models.Review{UserID: customerID, RestaurantID: restaurant.ID, OrderID: order.ID, Rating: rating, Comment: "Good enough food"}
+		if err := s.reviews.CreateReview(ctx, review); err != nil {
+			t.Fatalf("CreateReview: %v", err)
+		}
+		reviews = append(reviews, review)
+	}
+	if rating, count := s.ratingOf(t, restaurant.ID); rating != 3.5 || count != 2 {
+		t.Errorf("rating = %v of %d reviews, want 3.5 of 2", rating, count)
+	}
+
+	if _, err := s.reviews.UpdateReview(ctx, reviews[1].ID, 4, "Better the second time"); err != nil {
+		t.Fatalf("UpdateReview: %v", err)
+	}
+	if rating, count := s.ratingOf(t, restaurant.ID); rating != 4.5 || count != 2 {
+		t.Errorf("rating after the update = %v of %d reviews, want 4.5 of 2", rating, count)
+	}
+
+	if err := s.reviews.DeleteReview(ctx, reviews[0].ID); err != nil {
+		t.Fatalf("DeleteReview: %v", err)
+	}
+	if rating, count := s.ratingOf(t, restaurant.ID); rating != 4 || count != 1 {
+		t.Errorf("rating after the delete = %v of %d reviews, want 4 of 1", rating, count)
+	}
+	if err := s.reviews.DeleteReview(ctx, reviews[0].ID); !errors.Is(err, ErrReviewNotFound) {
+		t.Errorf("second delete: err = %v, want ErrReviewNotFound", err)
+	}
+}
+
+func TestReviewServiceRefusesInvalidReviews(t *testing.T) {
+	s := newTestServices()
+	ctx := context.Background()
+	restaurant := s.createRestaurant(t, "Burger Barn")
+	customerID := primitive.NewObjectID()
+	delivered := s.deliveredOrder(t, customerID, restaurant.ID)
+
+	placed := &models.Order{CustomerID: customerID, RestaurantID: restaurant.ID, Status: models.OrderStatusPlaced}
+	if err := s.repos.Orders.CreateOrder(ctx, placed); err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	first := &models.Review{UserID: customerID, RestaurantID: restaurant.ID, OrderID: delivered.ID, Rating: 5, Comment: "Lovely burgers"}
+	if err := s.reviews.CreateReview(ctx, first); err != nil {
+		t.Fatalf("CreateReview: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		review models.Review
+		want   error
+	}{
+		{"second review of an order", models.Review{UserID: customerID, RestaurantID: restaurant.ID, OrderID: delivered.ID}, ErrReviewExists},
+		{"someone else's order", models.Review{UserID: primitive.NewObjectID(), RestaurantID: restaurant.ID, OrderID: delivered.ID}, ErrReviewOrderNotOwned},
+		{"another restaurant", models.Review{UserID: customerID, RestaurantID: primitive.NewObjectID(), OrderID: delivered.ID}, ErrReviewOrderMismatch},
+		{"order not delivered", models.Review{UserID: customerID, RestaurantID: restaurant.ID, OrderID: placed.ID}, ErrReviewOrderNotDelivered},
+		{"unknown order", models.Review{UserID: customerID, RestaurantID: restaurant.ID, OrderID: primitive.NewObjectID()}, ErrReviewOrderNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			review := tt.review
+			review.Rating, review.Comment = 1, "Not what I expected"
+			if err := s.reviews.CreateReview(ctx, &review); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if rating, count := s.ratingOf(t, restaurant.ID); rating != 5 || count != 1 {
+		t.Errorf("rating = %v of %d reviews, want only the first review's 5", rating, count)
+	}
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/aldiandyaIrsyad/uber-eats/repos"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// searchCandidates bounds how many restaurants and items each text search considers
+	searchCandidates = 200
+	// searchItemsPerResult is how many matching items are shown under each restaurant
+	searchItemsPerResult = 5
+	// searchDistanceWeight is how much of a result's score is lost at the edge of the radius
+	searchDistanceWeight = 0.5
+)
+
+type SearchService struct {
+	restaurantRepo repos.RestaurantRepository
+	itemRepo       repos.ItemRepository
+}
+
+func NewSearchService(restaurantRepo repos.RestaurantRepository, itemRepo repos.ItemRepository) *SearchService {
+	return &SearchService{
+		restaurantRepo: restaurantRepo,
+		itemRepo:       itemRepo,
+	}
+}
+
+// Search finds restaurants matching the query by their own name and description or through their
+// items, and groups the matching items under their restaurant. Results are ranked by text
+// relevance; near a point, closer restaurants rank higher and those outside the radius are left out.
+func (s *SearchService) Search(ctx context.Context, query models.SearchQuery) ([]models.SearchResult, error) {
+	restaurants, err := s.restaurantRepo.SearchRestaurants(ctx, query.Text, query.Near, searchCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	// $text can't be combined with a lookup, so items are only searched in restaurants in range
+	var inRange []primitive.ObjectID
+	if query.Near != nil {
+		inRange, err = s.restaurantRepo.GetRestaurantIDs(ctx, bson.M{"location": query.Near.RadiusFilter()})
+		if err != nil {
+			return nil, err
+		}
+	}
+	items, err := s.itemRepo.SearchItems(ctx, query.Text, inRange, searchCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := models.SearchTerms(query.Text)
+	results := make(map[primitive.ObjectID]*models.SearchResult)
+	bestItem := make(map[primitive.ObjectID]float64)
+	for _, restaurant := range restaurants {
+		results[restaurant.ID] = &models.SearchResult{
+			Restaurant: restaurant.Restaurant,
+			Score:      restaurant.Score,
+			Highlights: highlights(terms, map[string]string{"name": restaurant.Name, "description": restaurant.Description}),
+			Items:      []models.ItemMatch{},
+		}
+	}
+
+	// Items come best first, so each restaurant keeps its best matches
+	var missing []primitive.ObjectID
+	for _, item := range items {
+		result, ok := results[item.RestaurantID]
+		if !ok {
+			result = &models.SearchResult{Items: []models.ItemMatch{}}
+			results[item.RestaurantID] = result
+			missing = append(missing, item.RestaurantID)
+		}
+		if len(result.Items) == searchItemsPerResult {
+			continue
+		}
+		if item.Score > bestItem[item.RestaurantID] {
+			bestItem[item.RestaurantID] = item.Score
+		}
+		result.Items = append(result.Items, models.ItemMatch{
+			Item:       item.Item,
+			Score:      item.Score,
+			Highlights: highlights(terms, map[string]string{"name": item.Name, "description": item.Description}),
+		})
+	}
+
+	if len(missing) > 0 {
+		found, err := s.restaurantRepo.GetRestaurantsByIDs(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, restaurant := range found {
+			results[restaurant.ID].Restaurant = restaurant
+		}
+	}
+
+	now := time.Now()
+	ranked := make([]models.SearchResult, 0, len(results))
+	for restaurantID, result := range results {
+		if result.Restaurant.ID.IsZero() {
+			// The restaurant was deleted but its items are still around
+			continue
+		}
+		result.Score += bestItem[restaurantID]
+		if query.Near != nil {
+			distance := query.Near.DistanceMeters(result.Restaurant.Location.Coordinates)
+			result.Restaurant.Distance = &distance
+			result.Score *= 1 - searchDistanceWeight*math.Min(distance/query.Near.RadiusMeters, 1)
+		}
+		result.Score = math.Round(result.Score*1000) / 1000
+		result.Restaurant.SetOpenStatus(now)
+		ranked = append(ranked, *result)
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Restaurant.Name < ranked[j].Restaurant.Name
+	})
+	if len(ranked) > query.Limit {
+		ranked = ranked[:query.Limit]
+	}
+	return ranked, nil
+}
+
+// highlights returns the fields in which a search term matched, with the matches marked
+func highlights(terms []string, fields map[string]string) map[string]string {
+	marked := make(map[string]string)
+	for field, text := range fields {
+		if highlighted, ok := models.Highlight(text, terms); ok {
+			marked[field] = highlighted
+		}
+	}
+	if len(marked) == 0 {
+		return nil
+	}
+	return marked
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/aldiandyaIrsyad/uber-eats/repos"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// testServices wires the services the way the routes do, on repositories kept in memory
+type testServices struct {
+	repos       repos.Repositories
+	auth        *AuthService
+	suggest     *SuggestService
+	restaurants *RestaurantService
+	items       *ItemService
+	reviews     *ReviewService
+	inventory   *InventoryService
+	orders      *OrderService
+	carts       *CartService
+	zones       *DeliveryZoneService
+	menus       *MenuService
+}
+
+func newTestServices() *testServices {
+	r := repos.NewMemoryRepositories(repos.NewMemoryStore())
+	suggest := NewSuggestService(r.Restaurants, r.Items)
+	inventory := NewInventoryService(r.Items, r.Restaurants)
+	orders := NewOrderService(r.Orders, r.Items, r.Restaurants, r.Menus, inventory)
+	return &testServices{
+		repos:       r,
+		auth:        NewAuthService(r.Users, []byte("test secret")),
+		suggest:     suggest,
+		restaurants: NewRestaurantService(r.Restaurants, r.Reviews, r.Menus, suggest),
+		items:       NewItemService(r.Items, r.Categories, r.Menus, r.Restaurants, inventory, suggest),
+		reviews:     NewReviewService(r.Reviews, r.Orders),
+		inventory:   inventory,
+		orders:      orders,
+		carts:       NewCartService(r.Carts, r.Items, orders),
+		zones:       NewDeliveryZoneService(r.Zones, r.Restaurants),
+		menus:       NewMenuService(r.Menus, r.Categories, r.Items, r.Restaurants),
+	}
+}
+
+// createRestaurant creates a restaurant that is open around the clock
+func (s *testServices) createRestaurant(t *testing.T, name string) *models.Restaurant {
+	t.Helper()
+	restaurant := &models.Restaurant{OwnerID: primitive.NewObjectID(), Name: name, Address: "1 Main Street"}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		restaurant.OperatingHours = append(restaurant.OperatingHours, models.OperatingHours{Day: day.String(), OpenTime: "00:00", CloseTime: "24:00"})
+	}
+	if err := s.restaurants.CreateRestaurant(context.Background(), restaurant); err != nil {
+		t.Fatalf("CreateRestaurant: %v", err)
+	}
+	return restaurant
+}
+
+// createItem creates an available item, tracking its stock when inventory is set
+func (s *testServices) createItem(t *testing.T, restaurantID primitive.ObjectID, name string, price float64, inventory *models.Inventory) *models.Item {
+	t.Helper()
+	item := &models.Item{
+		RestaurantID: restaurantID,
+		Name:         name,
+		Description:  "A test item",
+		Price:        price,
+		Status:       models.ItemStatusAvailable,
+		Inventory:    inventory,
+	}
+	if err := s.items.CreateItem(context.Background(), item); err != nil {
+		t.Fatalf("CreateItem: %v", err)
+	}
+	return item
+}
+
+// stockOf returns the stock left of a tracked item
+func (s *testServices) stockOf(t *testing.T, itemID primitive.ObjectID) int {
+	t.Helper()
+	item, err := s.items.GetItemByID(context.Background(), itemID)
+	if err != nil {
+		t.Fatalf("GetItemByID: %v", err)
+	}
+	return item.Inventory.Stock
+}
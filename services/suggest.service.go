@@ -0,0 +1,349 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"github.com/aldiandyaIrsyad/uber-eats/repos"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SuggestService answers search-as-you-type with restaurant and dish names. The names are held
+// in memory in a trie of their words, loaded at startup and kept up to date by the restaurant and
+// item services, so suggestions never wait on the database. Each instance only sees the writes it
+// handles itself; the index catches up with other instances' writes on restart.
+type SuggestService struct {
+	restaurantRepo repos.RestaurantRepository
+	itemRepo       repos.ItemRepository
+
+	mu      sync.RWMutex
+	root    *trieNode
+	entries map[primitive.ObjectID]suggestEntry
+}
+
+type suggestEntry struct {
+	kind string
+	name string
+	// restaurantID is the restaurant selling an item
+	restaurantID primitive.ObjectID
+	words        []string
+}
+
+// trieNode holds, for the word ending at it, the entries whose name contains that word
+type trieNode struct {
+	children map[rune]*trieNode
+	entries  map[primitive.ObjectID]struct{}
+}
+
+func newTrieNode() *trieNode {
+	return &trieNode{children: make(map[rune]*trieNode)}
+}
+
+func NewSuggestService(restaurantRepo repos.RestaurantRepository, itemRepo repos.ItemRepository) *SuggestService {
+	return &SuggestService{
+		restaurantRepo: restaurantRepo,
+		itemRepo:       itemRepo,
+		root:           newTrieNode(),
+		entries:        make(map[primitive.ObjectID]suggestEntry),
+	}
+}
+
+// Load fills the index with every restaurant and item name. Items left behind by deleted
+// restaurants are skipped.
+func (s *SuggestService) Load(ctx context.Context) error {
+	restaurants, err := s.restaurantRepo.GetRestaurantNames(ctx)
+	if err != nil {
+		return err
+	}
+	items, err := s.itemRepo.GetItemNames(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.root = newTrieNode()
+	s.entries = make(map[primitive.ObjectID]suggestEntry, len(restaurants)+len(items))
+	restaurantIDs := make(map[primitive.ObjectID]bool, len(restaurants))
+	for _, restaurant := range restaurants {
+		restaurantIDs[restaurant.ID] = true
+		s.add(restaurant.ID, suggestEntry{kind: models.SuggestionRestaurant, name: restaurant.Name})
+	}
+	for _, item := range items {
+		if restaurantIDs[item.RestaurantID] {
+			s.add(item.ID, suggestEntry{kind: models.SuggestionItem, name: item.Name, restaurantID: item.RestaurantID})
+		}
+	}
+	return nil
+}
+
+// IndexRestaurant adds a restaurant's name to the index, replacing its previous name
+func (s *SuggestService) IndexRestaurant(restaurant *models.Restaurant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(restaurant.ID)
+	s.add(restaurant.ID, suggestEntry{kind: models.SuggestionRestaurant, name: restaurant.Name})
+}
+
+// IndexItem adds an item's name to the index, replacing its previous name
+func (s *SuggestService) IndexItem(item *models.Item) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(item.ID)
+	s.add(item.ID, suggestEntry{kind: models.SuggestionItem, name: item.Name, restaurantID: item.RestaurantID})
+}
+
+// Remove takes an item out of the index
+func (s *SuggestService) Remove(id primitive.ObjectID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(id)
+}
+
+// RemoveRestaurant takes a restaurant and all of its items out of the index
+func (s *SuggestService) RemoveRestaurant(restaurantID primitive.ObjectID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(restaurantID)
+	for id, entry := range s.entries {
+		if entry.restaurantID == restaurantID {
+			s.remove(id)
+		}
+	}
+}
+
+func (s *SuggestService) add(id primitive.ObjectID, entry suggestEntry) {
+	entry.words = suggestWords(entry.name)
+	s.entries[id] = entry
+	for _, word := range entry.words {
+		node := s.root
+		for _, r := range word {
+			child, ok := node.children[r]
+			if !ok {
+				child = newTrieNode()
+				node.children[r] = child
+			}
+			node = child
+		}
+		if node.entries == nil {
+			node.entries = make(map[primitive.ObjectID]struct{})
+		}
+		node.entries[id] = struct{}{}
+	}
+}
+
+// remove drops the entry from the nodes of its words. Emptied nodes are left in place; they are
+// few and get reused by later names.
+func (s *SuggestService) remove(id primitive.ObjectID) {
+	entry, ok := s.entries[id]
+	if !ok {
+		return
+	}
+	delete(s.entries, id)
+	for _, word := range entry.words {
+		node := s.root
+		for _, r := range word {
+			if node = node.children[r]; node == nil {
+				break
+			}
+		}
+		if node != nil {
+			delete(node.entries, id)
+		}
+	}
+}
+
+// Suggest returns up to limit names whose words start with the words typed, allowing for typos:
+// none in words under 4 letters, one up to 7 letters and two from 8. Restaurants are suggested
+// individually; dishes sold by several restaurants are suggested once with their count.
+func (s *SuggestService) Suggest(text string, limit int) []models.Suggestion {
+	terms := suggestWords(text)
+	if len(terms) == 0 {
+		return []models.Suggestion{}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Every term has to match a word of the name; the distances of the best matches add up
+	var distances map[primitive.ObjectID]int
+	for _, term := range terms {
+		matches := make(map[primitive.ObjectID]int)
+		s.root.match([]rune(term), maxTypos(term), matches)
+
+		if distances == nil {
+			distances = matches
+			continue
+		}
+		for id, distance := range distances {
+			if d, ok := matches[id]; ok {
+				distances[id] = distance + d
+			} else {
+				delete(distances, id)
+			}
+		}
+	}
+
+	typed := strings.Join(terms, " ")
+	ranked := make([]rankedSuggestion, 0, len(distances))
+	dishes := make(map[string]int)
+	for id, distance := range distances {
+		entry := s.entries[id]
+		normalized := strings.Join(entry.words, " ")
+		if entry.kind == models.SuggestionItem {
+			if i, ok := dishes[normalized]; ok {
+				ranked[i].Count++
+				ranked[i].distance = min(ranked[i].distance, distance)
+				continue
+			}
+			dishes[normalized] = len(ranked)
+		}
+
+		suggestion := rankedSuggestion{
+			Suggestion: models.Suggestion{Type: entry.kind, Text: entry.name},
+			distance:   distance,
+			prefix:     strings.HasPrefix(normalized, typed),
+		}
+		if entry.kind == models.SuggestionRestaurant {
+			restaurantID := id
+			suggestion.ID = &restaurantID
+		} else {
+			suggestion.Count = 1
+		}
+		ranked = append(ranked, suggestion)
+	}
+
+	// Closest first, then names starting with what was typed, then the most common dishes and
+	// the shortest names
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.distance != b.distance {
+			return a.distance < b.distance
+		}
+		if a.prefix != b.prefix {
+			return a.prefix
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if len(a.Text) != len(b.Text) {
+			return len(a.Text) < len(b.Text)
+		}
+		return a.Text < b.Text
+	})
+
+	suggestions := make([]models.Suggestion, 0, min(limit, len(ranked)))
+	for i := 0; i < len(ranked) && i < limit; i++ {
+		suggestions = append(suggestions, ranked[i].Suggestion)
+	}
+	return suggestions
+}
+
+type rankedSuggestion struct {
+	models.Suggestion
+	distance int
+	prefix   bool
+}
+
+// match walks the trie computing the edit distance between term and every prefix of the words
+// below it, one row of the distance table per letter. Swapped neighbouring letters count as one
+// typo (optimal string alignment). Words with a prefix within maxDistance of term are added to
+// matches with their smallest distance. Branches are cut once no cell of the last two rows can lead
+// back within maxDistance.
+func (n *trieNode) match(term []rune, maxDistance int, matches map[primitive.ObjectID]int) {
+	row := make([]int, len(term)+1)
+	for i := range row {
+		row[i] = i
+	}
+	for r, child := range n.children {
+		child.matchFrom(r, 0, term, row, nil, len(term), maxDistance, matches)
+	}
+}
+
+func (n *trieNode) matchFrom(letter, previousLetter rune, term []rune, previous, beforePrevious []int, best, maxDistance int, matches map[primitive.ObjectID]int) {
+	row := make([]int, len(previous))
+	row[0] = previous[0] + 1
+	lowest := row[0]
+	for i := 1; i < len(row); i++ {
+		cost := 1
+		if term[i-1] == letter {
+			cost = 0
+		}
+		row[i] = min(previous[i]+1, row[i-1]+1, previous[i-1]+cost)
+		if beforePrevious != nil && i > 1 && term[i-1] == previousLetter && term[i-2] == letter {
+			row[i] = min(row[i], beforePrevious[i-2]+1)
+		}
+		lowest = min(lowest, row[i])
+	}
+
+	// best is the distance of the closest prefix of the words below
+	best = min(best, row[len(term)])
+	if best <= maxDistance {
+		for id := range n.entries {
+			if distance, ok := matches[id]; !ok || best < distance {
+				matches[id] = best
+			}
+		}
+	} else if lowest > maxDistance && minOf(previous) >= maxDistance {
+		return
+	}
+
+	for r, child := range n.children {
+		child.matchFrom(r, letter, term, row, previous, best, maxDistance, matches)
+	}
+}
+
+func minOf(values []int) int {
+	lowest := values[0]
+	for _, value := range values[1:] {
+		lowest = min(lowest, value)
+	}
+	return lowest
+}
+
+// maxTypos is how many typos are allowed in a typed word of that length
+func maxTypos(term string) int {
+	switch length := len([]rune(term)); {
+	case length < 4:
+		return 0
+	case length < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// suggestWords splits a name into lowercase words, folding common accents so "creme" finds "Crème"
+func suggestWords(text string) []string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words = append(words, strings.Map(foldAccent, word))
+	}
+	return words
+}
+
+func foldAccent(r rune) rune {
+	switch r {
+	case 'à', 'á', 'â', 'ã', 'ä', 'å':
+		return 'a'
+	case 'ç':
+		return 'c'
+	case 'è', 'é', 'ê', 'ë':
+		return 'e'
+	case 'ì', 'í', 'î', 'ï':
+		return 'i'
+	case 'ñ':
+		return 'n'
+	case 'ò', 'ó', 'ô', 'õ', 'ö':
+		return 'o'
+	case 'ù', 'ú', 'û', 'ü':
+		return 'u'
+	}
+	return r
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/aldiandyaIrsyad/uber-eats/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newTestSuggestService() (*SuggestService, map[string]primitive.ObjectID) {
+	s := NewSuggestService(nil, nil)
+	restaurants := map[string]primitive.ObjectID{"Burger Barn": primitive.NewObjectID(), "Crème Café": primitive.NewObjectID()}
+	for name, id := range restaurants {
+		s.IndexRestaurant(&models.Restaurant{ID: id, Name: name})
+	}
+	items := []struct {
+		restaurant string
+		name       string
+	}{
+		{"Burger Barn", "Chicken Burger"},
+		{"Burger Barn", "Cheeseburger"},
+		{"Burger Barn", "Fries"},
+		{"Crème Café", "Chicken Burger"},
+		{"Crème Café", "Crème Brûlée"},
+	}
+	for _, item := range items {
+		s.IndexItem(&models.Item{ID: primitive.NewObjectID(), RestaurantID: restaurants[item.restaurant], Name: item.name})
+	}
+	return s, restaurants
+}
+
+func suggestionTexts(suggestions []models.Suggestion) []string {
+	texts := make([]string, len(suggestions))
+	for i, suggestion := range suggestions {
+		texts[i] = suggestion.Text
+	}
+	return texts
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"prefix", "chee", []string{"Cheeseburger"}},
+		{"prefix of a later word", "bur", []string{"Burger Barn", "Chicken Burger"}},
+		{"every word must match", "chicken bur", []string{"Chicken Burger"}},
+		{"no typos under 4 letters", "fes", []string{}},
+		{"substitution", "frias", []string{"Fries"}},
+		{"missing letter", "chiken", []string{"Chicken Burger"}},
+		{"extra letter", "friees", []string{"Fries"}},
+		{"swapped letters", "chikcen bur", []string{"Chicken Burger"}},
+		{"two typos in long words", "chesseburgr", []string{"Cheeseburger"}},
+		{"too many typos", "frxxs", []string{}},
+		{"accents folded", "creme", []string{"Crème Brûlée", "Crème Café"}},
+		{"empty", "  ", []string{}},
+	}
+	s, _ := newTestSuggestService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := suggestionTexts(s.Suggest(tt.text, 10))
+			if len(got) != len(tt.want) {
+				t.Fatalf("Suggest(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Suggest(%q) = %q, want %q", tt.text, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSuggestCountsDishesAcrossRestaurants(t *testing.T) {
+	s, _ := newTestSuggestService()
+	got := s.Suggest("chicken", 10)
+	if len(got) != 1 || got[0].Count != 2 || got[0].ID != nil {
+		t.Fatalf("Suggest(chicken) = %+v, want one dish with count 2", got)
+	}
+}
+
+func TestSuggestRemoveRestaurantDropsItsItems(t *testing.T) {
+	s, restaurants := newTestSuggestService()
+	s.RemoveRestaurant(restaurants["Burger Barn"])
+
+	if got := suggestionTexts(s.Suggest("fries", 10)); len(got) != 0 {
+		t.Errorf("Suggest(fries) = %q after removing its restaurant, want nothing", got)
+	}
+	if got := suggestionTexts(s.Suggest("barn", 10)); len(got) != 0 {
+		t.Errorf("Suggest(barn) = %q after removing the restaurant, want nothing", got)
+	}
+	got := s.Suggest("chicken", 10)
+	if len(got) != 1 || got[0].Count != 1 {
+		t.Errorf("Suggest(chicken) = %+v, want the other restaurant's dish only", got)
+	}
+}
+
+func TestSuggestRenameReplacesName(t *testing.T) {
+	s, restaurants := newTestSuggestService()
+	s.IndexRestaurant(&models.Restaurant{ID: restaurants["Burger Barn"], Name: "Taco Shack"})
+
+	if got := suggestionTexts(s.Suggest("barn", 10)); len(got) != 0 {
+		t.Errorf("Suggest(barn) = %q after renaming, want nothing", got)
+	}
+	if got := suggestionTexts(s.Suggest("taco", 10)); len(got) != 1 || got[0] != "Taco Shack" {
+		t.Errorf("Suggest(taco) = %q, want [Taco Shack]", got)
+	}
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// checkUpdateFields refuses an update with a key that isn't one of the fields callers may set.
+// Updates are applied with $set, so dotted keys and operators are refused too: they would reach
+// into fields the services compute, such as rating aggregates or stock.
+func checkUpdateFields(update map[string]interface{}, updatable []string) error {
+	var fields []FieldError
+	for key := range update {
+		if strings.ContainsAny(key, ".$") || !slices.Contains(updatable, key) {
+			fields = append(fields, FieldError{Field: key, Code: "not_updatable", Message: key + " can't be updated; updatable fields are " + strings.Join(updatable, ", ")})
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+	return Validation("invalid_update", "the update has fields that can't be updated", fields...)
+}